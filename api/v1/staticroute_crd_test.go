@@ -0,0 +1,236 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const crdDir = "../../config/crd/"
+
+// crdKustomization is the part of config/crd/kustomization.yaml which patches the StaticRoute CRD
+type crdKustomization struct {
+	PatchesStrategicMerge []string   `json:"patchesStrategicMerge"`
+	PatchesJSON6902       []crdPatch `json:"patchesJson6902"`
+}
+
+type crdPatch struct {
+	Path string `json:"path"`
+}
+
+func readYAML(t *testing.T, path string, out interface{}) {
+	data, err := os.ReadFile(crdDir + path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %s", path, err.Error())
+	}
+	if err = yaml.Unmarshal(data, out); err != nil {
+		t.Fatalf("Failed to parse %s: %s", path, err.Error())
+	}
+}
+
+func readCRD(t *testing.T) *apiextensionsv1.CustomResourceDefinition {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	readYAML(t, "bases/static-route.ibm.com_staticroutes.yaml", crd)
+	return crd
+}
+
+// patchCRD applies the patches of the kustomization to the CRD like kustomize does. The strategic merge patches of
+// the StaticRoute CRD only set the conversion and the annotations.
+func patchCRD(t *testing.T, crd *apiextensionsv1.CustomResourceDefinition, kustomization crdKustomization) *apiextensionsv1.CustomResourceDefinition {
+	for _, path := range kustomization.PatchesStrategicMerge {
+		patch := &apiextensionsv1.CustomResourceDefinition{}
+		readYAML(t, path, patch)
+		if patch.Spec.Conversion != nil {
+			crd.Spec.Conversion = patch.Spec.Conversion
+		}
+		for key, value := range patch.Annotations {
+			if crd.Annotations == nil {
+				crd.Annotations = map[string]string{}
+			}
+			crd.Annotations[key] = value
+		}
+	}
+	for _, item := range kustomization.PatchesJSON6902 {
+		var operations []interface{}
+		readYAML(t, item.Path, &operations)
+		data, _ := json.Marshal(operations)
+		patch, err := jsonpatch.DecodePatch(data)
+		if err != nil {
+			t.Fatalf("Failed to decode %s: %s", item.Path, err.Error())
+		}
+		data, _ = json.Marshal(crd)
+		if data, err = patch.Apply(data); err != nil {
+			t.Fatalf("Failed to apply %s: %s", item.Path, err.Error())
+		}
+		crd = &apiextensionsv1.CustomResourceDefinition{}
+		if err = json.Unmarshal(data, crd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return crd
+}
+
+// shippedCRD returns the StaticRoute CRD as config/crd deploys it
+func shippedCRD(t *testing.T) *apiextensionsv1.CustomResourceDefinition {
+	kustomization := crdKustomization{}
+	readYAML(t, "kustomization.yaml", &kustomization)
+	return patchCRD(t, readCRD(t), kustomization)
+}
+
+func storageVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	return ""
+}
+
+func conversionStrategy(crd *apiextensionsv1.CustomResourceDefinition) apiextensionsv1.ConversionStrategyType {
+	if crd.Spec.Conversion == nil {
+		return apiextensionsv1.NoneConverter
+	}
+	return crd.Spec.Conversion.Strategy
+}
+
+// prune drops the fields which are not in the schema of the version, like the API server does
+func prune(t *testing.T, crd *apiextensionsv1.CustomResourceDefinition, version string, obj map[string]interface{}) {
+	for _, v := range crd.Spec.Versions {
+		if v.Name != version {
+			continue
+		}
+		props := &apiextensions.JSONSchemaProps{}
+		if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(v.Schema.OpenAPIV3Schema, props, nil); err != nil {
+			t.Fatal(err)
+		}
+		schema, err := structuralschema.NewStructural(props)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pruning.Prune(obj, schema, true)
+		return
+	}
+	t.Fatalf("Version %s not found", version)
+}
+
+// Without the conversion webhook the API server only rewrites the apiVersion, so a v1 object must survive being
+// stored in the storage version of the shipped CRD and read back
+func TestShippedCRDRoundTripsStoredV1(t *testing.T) {
+	crd := shippedCRD(t)
+	storage := storageVersion(crd)
+	if conversionStrategy(crd) == apiextensionsv1.WebhookConverter {
+		t.Skip("Objects are converted by the webhook")
+	}
+	if storage != "v1" {
+		t.Errorf("Storage version %s needs the conversion webhook", storage)
+	}
+	table, metric := 1000, int64(100)
+	route := &StaticRoute{
+		TypeMeta:   metav1.TypeMeta{APIVersion: GroupVersion.String(), Kind: "StaticRoute"},
+		ObjectMeta: metav1.ObjectMeta{Name: "CR"},
+		Spec: StaticRouteSpec{
+			Subnet:    "192.168.1.0/24",
+			Gateway:   "10.0.0.1",
+			Table:     &table,
+			Metric:    &metric,
+			Selectors: []metav1.LabelSelectorRequirement{{Key: "kubernetes.io/arch", Operator: "In", Values: []string{"amd64"}}},
+		},
+	}
+	route.Status.NodeStatus = []StaticRouteNodeStatus{{Hostname: "hostname", State: route.Spec}}
+	data, _ := json.Marshal(route)
+	original, stored := map[string]interface{}{}, map[string]interface{}{}
+	_ = json.Unmarshal(data, &original)
+	_ = json.Unmarshal(data, &stored)
+
+	prune(t, crd, "v1", stored)
+	stored["apiVersion"] = GroupVersion.Group + "/" + storage
+	prune(t, crd, storage, stored)
+	stored["apiVersion"] = GroupVersion.String()
+	prune(t, crd, "v1", stored)
+
+	if !reflect.DeepEqual(stored, original) {
+		t.Errorf("Stored object must not lose fields: expected %v, actual %v", original, stored)
+	}
+}
+
+// v2 may only become the storage version together with the conversion webhook
+func TestStorageV2PatchNeedsConversionWebhook(t *testing.T) {
+	kustomization := crdKustomization{}
+	readYAML(t, "kustomization.yaml", &kustomization)
+	webhook := slices.Contains(kustomization.PatchesStrategicMerge, "patches/webhook_in_staticroutes.yaml")
+	storageV2 := slices.Contains(kustomization.PatchesJSON6902, crdPatch{Path: "patches/storage_v2_in_staticroutes.yaml"})
+	if webhook != storageV2 {
+		t.Errorf("Conversion webhook (%t) and v2 storage (%t) patches must be enabled together", webhook, storageV2)
+	}
+
+	crd := patchCRD(t, readCRD(t), crdKustomization{
+		PatchesStrategicMerge: []string{"patches/webhook_in_staticroutes.yaml", "patches/cainjection_in_staticroutes.yaml"},
+		PatchesJSON6902:       []crdPatch{{Path: "patches/storage_v2_in_staticroutes.yaml"}},
+	})
+	if storageVersion(crd) != "v2" || conversionStrategy(crd) != apiextensionsv1.WebhookConverter {
+		t.Errorf("Patches must store v2 through the conversion webhook: %s %s", storageVersion(crd), conversionStrategy(crd))
+	}
+}
+
+// The state in the node status reports what is applied on the node, i.e. the active candidate gateway, so the
+// rules of the Spec must not reject the status updates
+func TestCRDStateHasNoSpecRules(t *testing.T) {
+	crd := readCRD(t)
+	for _, version := range crd.Spec.Versions {
+		t.Run(version.Name, func(t *testing.T) {
+			root := version.Schema.OpenAPIV3Schema.Properties
+			spec := root["spec"]
+			if len(spec.XValidations) == 0 || !strings.Contains(spec.XValidations[len(spec.XValidations)-1].Message, "candidate gateways") {
+				t.Errorf("Spec must have the rules: %+v", spec.XValidations)
+			}
+			state := root["status"].Properties["nodeStatus"].Items.Schema.Properties["state"]
+			if len(state.Properties) == 0 || len(state.XValidations) != 0 {
+				t.Errorf("State must have no rules of the Spec: %+v", state.XValidations)
+			}
+		})
+	}
+}
+
+// The controllers write the StaticRoutes through v1, so it must accept every table of v2
+func TestCRDTableRange(t *testing.T) {
+	crd := readCRD(t)
+	for _, version := range crd.Spec.Versions {
+		t.Run(version.Name, func(t *testing.T) {
+			root := version.Schema.OpenAPIV3Schema.Properties
+			spec := root["spec"].Properties["table"]
+			state := root["status"].Properties["nodeStatus"].Items.Schema.Properties["state"].Properties["table"]
+			for _, table := range []apiextensionsv1.JSONSchemaProps{spec, state} {
+				if table.Maximum == nil || *table.Maximum != 4294967295 {
+					t.Errorf("Table must be allowed up to 4294967295: %v", table.Maximum)
+				}
+			}
+		})
+	}
+}
@@ -23,25 +23,220 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// NextHop defines one of the gateways of a multipath route
+type NextHop struct {
+	// Gateway the IP address of the next hop. Must be the same IP family as the subnet.
+	// +kubebuilder:validation:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7})$`
+	Gateway string `json:"gateway"`
+
+	// Weight of the next hop compared to the others (optional, default is 1)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=256
+	Weight *int `json:"weight,omitempty"`
+}
+
+// Probe types of GatewayProbe
+const (
+	// ProbeTypeNeighbor checks the ARP (IPv4) or neighbor discovery (IPv6) state of the gateway
+	ProbeTypeNeighbor = "neighbor"
+	// ProbeTypeICMP sends an ICMP echo request to the gateway
+	ProbeTypeICMP = "icmp"
+	// ProbeTypeTCP opens a TCP connection to a port of the gateway
+	ProbeTypeTCP = "tcp"
+)
+
+// GatewayProbe defines how the health of the candidate gateways is checked on the nodes
+// +kubebuilder:validation:XValidation:rule="self.type != 'tcp' || has(self.port)",message="tcp probe needs a port"
+type GatewayProbe struct {
+	// Type of the probe: neighbor (ARP or neighbor discovery state), icmp (echo request) or tcp (connect)
+	// +kubebuilder:validation:Enum=neighbor;icmp;tcp
+	Type string `json:"type"`
+
+	// Port of the gateway the tcp probe connects to
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port,omitempty"`
+
+	// PeriodSeconds how often the gateways are probed (optional, default is 10)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=3600
+	PeriodSeconds int `json:"periodSeconds,omitempty"`
+
+	// TimeoutSeconds after the probe of a gateway fails (optional, default is 1)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+
+	// FailureThreshold the number of consecutive failed probes after a gateway is considered unhealthy (optional, default is 3)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+
+	// SuccessThreshold the number of consecutive successful probes after an unhealthy gateway is considered healthy
+	// again (optional, default is 1)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	SuccessThreshold int `json:"successThreshold,omitempty"`
+}
+
+// Route types of StaticRoute
+const (
+	// RouteTypeUnicast routes the packets through the gateway
+	RouteTypeUnicast = "unicast"
+	// RouteTypeBlackhole drops the packets silently
+	RouteTypeBlackhole = "blackhole"
+	// RouteTypeUnreachable rejects the packets with ICMP host unreachable
+	RouteTypeUnreachable = "unreachable"
+	// RouteTypeProhibit rejects the packets with ICMP communication administratively prohibited
+	RouteTypeProhibit = "prohibit"
+	// RouteTypeThrow continues the lookup with the next policy routing rule
+	RouteTypeThrow = "throw"
+)
+
+// Scopes of StaticRoute
+const (
+	// ScopeUniverse the destination is more than one hop away
+	ScopeUniverse = "universe"
+	// ScopeSite the destination is inside the site (interior route)
+	ScopeSite = "site"
+	// ScopeLink the destination is directly attached to the link
+	ScopeLink = "link"
+	// ScopeHost the destination is on the node itself
+	ScopeHost = "host"
+)
+
 // StaticRouteSpec defines the desired state of StaticRoute
 type StaticRouteSpec struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Subnet defines the required IP subnet in the form of: "x.x.x.x/x"
-	// +kubebuilder:validation:Pattern=`^([0-9]{1,3}\.){3}[0-9]{1,3}(\/([0-9]|[1-2][0-9]|3[0-2]))?$`
-	Subnet string `json:"subnet"`
+	// Subnet defines the required IP subnet in the form of: "x.x.x.x/x" or "x:x::x/x".
+	// Either subnet or subnets must be set.
+	// +kubebuilder:validation:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}(\/([0-9]|[1-2][0-9]|3[0-2]))?|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7}(\/([0-9]|[1-9][0-9]|1[0-1][0-9]|12[0-8]))?)$`
+	Subnet string `json:"subnet,omitempty"`
+
+	// Subnets defines several subnets of the same IP family sharing the rest of the Spec (optional).
+	// Every subnet is installed as a separate route and reported individually in the node status.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=256
+	// +kubebuilder:validation:items:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}(\/([0-9]|[1-2][0-9]|3[0-2]))?|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7}(\/([0-9]|[1-9][0-9]|1[0-1][0-9]|12[0-8]))?)$`
+	Subnets []string `json:"subnets,omitempty"`
 
-	// Gateway the gateway the subnet is routed through (optional, discovered if not set)
-	// +kubebuilder:validation:Pattern=`^([0-9]{1,3}\.){3}[0-9]{1,3}$`
+	// Gateway the gateway the subnet is routed through (optional, discovered if not set). Must be the same IP family as the subnet.
+	// +kubebuilder:validation:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7})$`
 	Gateway string `json:"gateway,omitempty"`
 
+	// Gateways the list of next hops to spread the traffic across (optional, mutually exclusive with gateway).
+	// The status reports the next hops which are actually installed on the node.
+	// +kubebuilder:validation:MinItems=1
+	Gateways []NextHop `json:"gateways,omitempty"`
+
+	// CandidateGateways the gateways in the order of preference (optional, mutually exclusive with gateway and
+	// gateways). The route is installed through the first healthy one according to the probe, and it fails back
+	// when a preferred gateway recovers.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7})$`
+	CandidateGateways []string `json:"candidateGateways,omitempty"`
+
+	// Probe checks the health of the candidate gateways on each node, required with candidateGateways
+	Probe *GatewayProbe `json:"probe,omitempty"`
+
+	// Interface the name of the output interface on the nodes (optional). Without gateway the subnet is routed
+	// on-link through the interface, otherwise the gateway is reached through it.
+	// +kubebuilder:validation:MaxLength=15
+	Interface string `json:"interface,omitempty"`
+
+	// InterfaceAddressIn selects the output interface by its address (optional, mutually exclusive with interface):
+	// the interface holding an address inside this subnet is used on each node, i.e. "10.0.0.0/8".
+	// +kubebuilder:validation:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}(\/([0-9]|[1-2][0-9]|3[0-2]))?|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7}(\/([0-9]|[1-9][0-9]|1[0-1][0-9]|12[0-8]))?)$`
+	InterfaceAddressIn string `json:"interfaceAddressIn,omitempty"`
+
+	// Src the preferred source address of the packets sent to the subnet (optional). Must be an address of the node
+	// with the same IP family as the subnet.
+	// +kubebuilder:validation:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7})$`
+	Src string `json:"src,omitempty"`
+
+	// SrcInterface selects the preferred source address by interface name (optional, mutually exclusive with src):
+	// the first global address of the interface with the same IP family as the subnet is used on each node.
+	// +kubebuilder:validation:MaxLength=15
+	SrcInterface string `json:"srcInterface,omitempty"`
+
+	// Scope of the route (optional, default is link for device routes, universe otherwise).
+	// IPv6 routes support only the universe scope.
+	// +kubebuilder:validation:Enum=universe;site;link;host
+	Scope string `json:"scope,omitempty"`
+
+	// OnLink pretends that the gateway is directly attached to the interface, even if it does not match any
+	// subnet of the interface (optional). Needs gateway and interface or interfaceAddressIn.
+	OnLink bool `json:"onLink,omitempty"`
+
+	// MTU the path MTU of the subnet (optional, default is the MTU of the interface)
+	// +kubebuilder:validation:Minimum=68
+	// +kubebuilder:validation:Maximum=65535
+	MTU int `json:"mtu,omitempty"`
+
+	// AdvMSS the maximal TCP segment size advertised to the subnet (optional)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	AdvMSS int `json:"advMSS,omitempty"`
+
+	// InitCwnd the initial TCP congestion window in packets (optional)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	InitCwnd int `json:"initCwnd,omitempty"`
+
+	// InitRwnd the initial TCP receive window advertised in packets (optional)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	InitRwnd int `json:"initRwnd,omitempty"`
+
+	// HopLimit the TTL (IPv4) or hop limit (IPv6) of the packets sent to the subnet (optional)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=255
+	HopLimit int `json:"hopLimit,omitempty"`
+
 	// Table the route will be installed in (optional, uses default table if not set)
 	// +kubebuilder:validation:Minimum=0
-	// +kubebuilder:validation:Maximum=254
+	// +kubebuilder:validation:Maximum=4294967295
 	Table *int `json:"table,omitempty"`
 
+	// Metric the priority of the route, lower value is preferred (optional, default is 0).
+	// Routes for the same subnet with different metrics can coexist, i.e. as primary and backup.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	Metric *int64 `json:"metric,omitempty"`
+
 	// Selector defines the target nodes by requirement (optional, default is apply to all)
 	Selectors []metav1.LabelSelectorRequirement `json:"selectors,omitempty"`
+
+	// Type of the route (optional, default is unicast). Routes of the other types have no gateway, they are
+	// used to drop or reject the traffic of the subnet, or to continue the lookup with the next rule (throw).
+	// +kubebuilder:validation:Enum=unicast;blackhole;unreachable;prohibit;throw
+	Type string `json:"type,omitempty"`
+}
+
+// AllSubnets returns the subnets of the Spec, which is either the subnet or the list of subnets
+func (s *StaticRouteSpec) AllSubnets() []string {
+	if len(s.Subnets) != 0 {
+		return s.Subnets
+	}
+	return []string{s.Subnet}
+}
+
+// SubnetStatus defines the observed state of one subnet of a StaticRoute with multiple subnets
+type SubnetStatus struct {
+	Subnet string `json:"subnet"`
+	Error  string `json:"error,omitempty"`
+}
+
+// GatewayProbeStatus defines the observed health of a candidate gateway on a node
+type GatewayProbeStatus struct {
+	Gateway string `json:"gateway"`
+	Healthy bool   `json:"healthy"`
+	// Since is the last time when the health of the gateway changed
+	Since *metav1.Time `json:"since,omitempty"`
+	// Message is the error of the probe which found the gateway unhealthy
+	Message string `json:"message,omitempty"`
 }
 
 // StaticRouteNodeStatus defines the observed state of one IKS node, related to the StaticRoute
@@ -49,13 +244,53 @@ type StaticRouteNodeStatus struct {
 	Hostname string          `json:"hostname"`
 	State    StaticRouteSpec `json:"state"`
 	Error    string          `json:"error"`
+	// ObservedGeneration is the generation of the spec the node reported the state for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Subnets reports the subnets one by one if the StaticRoute has multiple subnets
+	Subnets []SubnetStatus `json:"subnets,omitempty"`
+
+	// ActiveGateway is the candidate gateway the route is installed through
+	ActiveGateway string `json:"activeGateway,omitempty"`
+	// GatewayProbes reports the health of the candidate gateways
+	GatewayProbes []GatewayProbeStatus `json:"gatewayProbes,omitempty"`
+
+	// TamperedAt is the last time when the route was deleted by an external entity and had to be re-created
+	TamperedAt *metav1.Time `json:"tamperedAt,omitempty"`
+	// TamperCount counts how many times the route was deleted by an external entity
+	TamperCount int `json:"tamperCount,omitempty"`
 }
 
+// Condition types of StaticRoute
+const (
+	// ConditionReady is true if the route is applied on all the selected nodes without error
+	ConditionReady = "Ready"
+	// ConditionProgressing is true while some of the selected nodes did not report the route yet
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded is true if the route failed on some of the nodes
+	ConditionDegraded = "Degraded"
+)
+
 // StaticRouteStatus defines the observed state of StaticRoute
 type StaticRouteStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
+	// ObservedGeneration is the generation of the spec every reporting node reconciled, the summary below counts
+	// only the nodes at the current generation
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// DesiredNodes is the number of nodes selected by the selectors
+	DesiredNodes int `json:"desiredNodes,omitempty"`
+	// AppliedNodes is the number of nodes reporting the route without error
+	AppliedNodes int `json:"appliedNodes,omitempty"`
+	// FailedNodes is the number of nodes reporting an error
+	FailedNodes int `json:"failedNodes,omitempty"`
+
+	// Conditions summarize the node statuses (Ready, Progressing, Degraded)
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
 	NodeStatus []StaticRouteNodeStatus `json:"nodeStatus"`
 }
 
@@ -63,15 +298,33 @@ type StaticRouteStatus struct {
 
 // StaticRoute is the Schema for the staticroutes API
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 // +kubebuilder:resource:path=staticroutes,scope=Cluster
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,priority=0
+// +kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.status.desiredNodes`,priority=0
+// +kubebuilder:printcolumn:name="Applied",type=integer,JSONPath=`.status.appliedNodes`,priority=0
+// +kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.failedNodes`,priority=0
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,priority=0
 // +kubebuilder:printcolumn:name="Network",type=string,JSONPath=`.spec.subnet`,priority=1
+// +kubebuilder:printcolumn:name="Networks",type=string,JSONPath=`.spec.subnets`,priority=1
 // +kubebuilder:printcolumn:name="Gateway",type=string,JSONPath=`.spec.gateway`,description="empty field means default gateway",priority=1
 // +kubebuilder:printcolumn:name="Table",type=integer,JSONPath=`.spec.table`,description="empty field means default table",priority=1
+// +kubebuilder:printcolumn:name="Metric",type=integer,JSONPath=`.spec.metric`,description="empty field means metric 0",priority=1
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`,description="empty field means unicast",priority=1
 type StaticRoute struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
+	// +kubebuilder:validation:XValidation:rule="has(self.subnet) != has(self.subnets)",message="exactly one of subnet and subnets must be set"
+	// +kubebuilder:validation:XValidation:rule="!has(self.gateway) || !has(self.gateways)",message="gateway and gateways are mutually exclusive"
+	// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type == 'unicast' || (!has(self.gateway) && !has(self.gateways) && !has(self.interface) && !has(self.interfaceAddressIn))",message="only unicast routes can have a gateway or an interface"
+	// +kubebuilder:validation:XValidation:rule="!has(self.interface) || !has(self.interfaceAddressIn)",message="interface and interfaceAddressIn are mutually exclusive"
+	// +kubebuilder:validation:XValidation:rule="!has(self.gateways) || (!has(self.interface) && !has(self.interfaceAddressIn))",message="gateways can not be combined with an interface"
+	// +kubebuilder:validation:XValidation:rule="!has(self.src) || !has(self.srcInterface)",message="src and srcInterface are mutually exclusive"
+	// +kubebuilder:validation:XValidation:rule="!has(self.onLink) || !self.onLink || (has(self.gateway) && (has(self.interface) || has(self.interfaceAddressIn)))",message="onLink needs a gateway and an interface"
+	// +kubebuilder:validation:XValidation:rule="has(self.candidateGateways) == has(self.probe)",message="candidateGateways and probe must be set together"
+	// +kubebuilder:validation:XValidation:rule="!has(self.candidateGateways) || (!has(self.gateway) && !has(self.gateways))",message="candidateGateways is mutually exclusive with gateway and gateways"
+	// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type == 'unicast' || !has(self.candidateGateways)",message="only unicast routes can have candidate gateways"
 	Spec   StaticRouteSpec   `json:"spec,omitempty"`
 	Status StaticRouteStatus `json:"status,omitempty"`
 }
@@ -0,0 +1,331 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+
+	"github.com/IBM/staticroute-operator/pkg/cidr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var webhookLog = logf.Log.WithName("staticroute_webhook")
+
+// StaticRouteValidator rejects StaticRoute objects at admission time which would fail on the nodes anyway
+// +kubebuilder:object:generate=false
+type StaticRouteValidator struct {
+	// Client is used to look up the other StaticRoutes
+	Client client.Reader
+	// ProtectedSubnets are the subnets a StaticRoute must not overlap with
+	ProtectedSubnets *cidr.Set
+	// DefaultTable is the table of the StaticRoutes which do not specify one
+	DefaultTable int
+	// Policies is true if the StaticRoutePolicy CRD is installed, the policies are not looked up otherwise
+	Policies bool
+}
+
+// SetupWebhookWithManager registers the validating webhook of StaticRoute in the Manager. The conversion webhook of
+// the other versions is registered as well, if they are in the scheme of the Manager.
+func (v *StaticRouteValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&StaticRoute{}).
+		WithValidator(v).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-static-route-ibm-com-v1-staticroute,mutating=false,failurePolicy=fail,sideEffects=None,groups=static-route.ibm.com,resources=staticroutes,verbs=create;update,versions=v1,name=vstaticroute.kb.io,admissionReviewVersions=v1
+
+// blank assignment to verify that StaticRouteValidator implements admission.CustomValidator
+var _ admission.CustomValidator = &StaticRouteValidator{}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *StaticRouteValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	route, ok := obj.(*StaticRoute)
+	if !ok {
+		return nil, fmt.Errorf("expected a StaticRoute but got a %T", obj)
+	}
+	return nil, v.validate(ctx, route)
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *StaticRouteValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldRoute, ok := oldObj.(*StaticRoute)
+	if !ok {
+		return nil, fmt.Errorf("expected a StaticRoute but got a %T", oldObj)
+	}
+	route, ok := newObj.(*StaticRoute)
+	if !ok {
+		return nil, fmt.Errorf("expected a StaticRoute but got a %T", newObj)
+	}
+	// Metadata changes (i.e. finalizer removal) must not be blocked by the state of other objects
+	if reflect.DeepEqual(oldRoute.Spec, route.Spec) {
+		return nil, nil
+	}
+	return nil, v.validate(ctx, route)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *StaticRouteValidator) ValidateDelete(context.Context, runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *StaticRouteValidator) validate(ctx context.Context, route *StaticRoute) error {
+	webhookLog.Info("Validating StaticRoute", "Name", route.Name)
+	specPath := field.NewPath("spec")
+	allErrs := field.ErrorList{}
+
+	subnets, subnetPaths, subnetErrs := specSubnets(route, specPath)
+	allErrs = append(allErrs, subnetErrs...)
+	if len(subnetErrs) == 0 {
+		allErrs = append(allErrs, v.validateGateways(route, subnets[0], specPath)...)
+		// The kernel installs every IPv6 route with the universe scope
+		if subnets[0].IP.To4() == nil && len(route.Spec.Scope) != 0 && route.Spec.Scope != ScopeUniverse {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("scope"), "IPv6 routes support only the universe scope"))
+		}
+		for i, subnet := range subnets {
+			if protected := v.ProtectedSubnets.Overlapping(subnet); protected != nil {
+				allErrs = append(allErrs, field.Forbidden(subnetPaths[i], fmt.Sprintf("overlaps with the protected subnet %s", protected.String())))
+			}
+		}
+	}
+
+	for i, selector := range route.Spec.Selectors {
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelectorRequirement(selector, metav1validation.LabelSelectorValidationOptions{}, specPath.Child("selectors").Index(i))...)
+	}
+
+	if len(allErrs) == 0 {
+		policyErrs, err := v.validatePolicies(ctx, subnets, subnetPaths)
+		if err != nil {
+			return apierrors.NewInternalError(err)
+		}
+		allErrs = append(allErrs, policyErrs...)
+	}
+
+	if len(allErrs) == 0 {
+		overlapErrs, err := v.validateOverlapWithOthers(ctx, route, subnets, subnetPaths)
+		if err != nil {
+			return apierrors.NewInternalError(err)
+		}
+		allErrs = append(allErrs, overlapErrs...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("StaticRoute").GroupKind(), route.Name, allErrs)
+}
+
+// specSubnets parses the subnets of the route together with their field paths. The subnets of the list
+// must be unique and of the same IP family, as they share the gateway.
+func specSubnets(route *StaticRoute, specPath *field.Path) ([]*net.IPNet, []*field.Path, field.ErrorList) {
+	allErrs := field.ErrorList{}
+	if len(route.Spec.Subnet) != 0 && len(route.Spec.Subnets) != 0 {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("subnets"), "subnet and subnets are mutually exclusive"))
+		return nil, nil, allErrs
+	}
+	paths := []*field.Path{specPath.Child("subnet")}
+	if len(route.Spec.Subnets) != 0 {
+		paths = nil
+		for i := range route.Spec.Subnets {
+			paths = append(paths, specPath.Child("subnets").Index(i))
+		}
+	}
+	subnets := []*net.IPNet{}
+	seen := map[string]bool{}
+	for i, s := range route.Spec.AllSubnets() {
+		_, subnet, err := net.ParseCIDR(s)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(paths[i], s, "must be a subnet in CIDR notation"))
+			continue
+		}
+		if seen[subnet.String()] {
+			allErrs = append(allErrs, field.Duplicate(paths[i], s))
+		} else if len(subnets) != 0 && (subnet.IP.To4() == nil) != (subnets[0].IP.To4() == nil) {
+			allErrs = append(allErrs, field.Invalid(paths[i], s, "must be the same IP family as the other subnets"))
+		}
+		seen[subnet.String()] = true
+		subnets = append(subnets, subnet)
+	}
+	return subnets, paths, allErrs
+}
+
+func (v *StaticRouteValidator) validateGateways(route *StaticRoute, subnet *net.IPNet, specPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	ipv6 := subnet.IP.To4() == nil
+	validateGateway := func(gateway string, path *field.Path) {
+		ip := net.ParseIP(gateway)
+		if ip == nil {
+			allErrs = append(allErrs, field.Invalid(path, gateway, "must be an IP address"))
+		} else if (ip.To4() == nil) != ipv6 {
+			allErrs = append(allErrs, field.Invalid(path, gateway, "must be the same IP family as the subnet"))
+		}
+	}
+
+	// Only unicast routes forward the packets to a gateway
+	if route.Spec.Type != "" && route.Spec.Type != RouteTypeUnicast {
+		if len(route.Spec.Gateway) != 0 {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("gateway"), fmt.Sprintf("must not be set for %s routes", route.Spec.Type)))
+		}
+		if len(route.Spec.Gateways) != 0 {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("gateways"), fmt.Sprintf("must not be set for %s routes", route.Spec.Type)))
+		}
+		if len(route.Spec.CandidateGateways) != 0 {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("candidateGateways"), fmt.Sprintf("must not be set for %s routes", route.Spec.Type)))
+		}
+		if len(route.Spec.Interface) != 0 || len(route.Spec.InterfaceAddressIn) != 0 {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("interface"), fmt.Sprintf("must not be set for %s routes", route.Spec.Type)))
+		}
+		return allErrs
+	}
+
+	if len(route.Spec.Interface) != 0 && len(route.Spec.InterfaceAddressIn) != 0 {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("interfaceAddressIn"), "interface and interfaceAddressIn are mutually exclusive"))
+	}
+	if len(route.Spec.InterfaceAddressIn) != 0 {
+		if _, _, err := net.ParseCIDR(route.Spec.InterfaceAddressIn); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("interfaceAddressIn"), route.Spec.InterfaceAddressIn, "must be a subnet in CIDR notation"))
+		}
+	}
+	if len(route.Spec.Gateways) != 0 && (len(route.Spec.Interface) != 0 || len(route.Spec.InterfaceAddressIn) != 0) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("gateways"), "can not be combined with an interface"))
+	}
+	if len(route.Spec.Src) != 0 {
+		validateGateway(route.Spec.Src, specPath.Child("src"))
+		if len(route.Spec.SrcInterface) != 0 {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("srcInterface"), "src and srcInterface are mutually exclusive"))
+		}
+	}
+	if route.Spec.OnLink && (len(route.Spec.Gateway) == 0 || (len(route.Spec.Interface) == 0 && len(route.Spec.InterfaceAddressIn) == 0)) {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("onLink"), "needs a gateway and an interface"))
+	}
+
+	if len(route.Spec.Gateway) != 0 {
+		validateGateway(route.Spec.Gateway, specPath.Child("gateway"))
+		if len(route.Spec.Gateways) != 0 {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("gateways"), "gateway and gateways are mutually exclusive"))
+		}
+	}
+	for i, nextHop := range route.Spec.Gateways {
+		validateGateway(nextHop.Gateway, specPath.Child("gateways").Index(i).Child("gateway"))
+	}
+	return append(allErrs, validateCandidateGateways(route, specPath, validateGateway)...)
+}
+
+// validateCandidateGateways checks the candidate gateways and their probe
+func validateCandidateGateways(route *StaticRoute, specPath *field.Path, validateGateway func(string, *field.Path)) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(route.Spec.CandidateGateways) == 0 {
+		if route.Spec.Probe != nil {
+			allErrs = append(allErrs, field.Forbidden(specPath.Child("probe"), "needs candidateGateways"))
+		}
+		return allErrs
+	}
+	if len(route.Spec.Gateway) != 0 || len(route.Spec.Gateways) != 0 {
+		allErrs = append(allErrs, field.Forbidden(specPath.Child("candidateGateways"), "candidateGateways is mutually exclusive with gateway and gateways"))
+	}
+	seen := map[string]bool{}
+	for i, candidate := range route.Spec.CandidateGateways {
+		validateGateway(candidate, specPath.Child("candidateGateways").Index(i))
+		if ip := net.ParseIP(candidate); ip != nil {
+			if seen[ip.String()] {
+				allErrs = append(allErrs, field.Duplicate(specPath.Child("candidateGateways").Index(i), candidate))
+			}
+			seen[ip.String()] = true
+		}
+	}
+	if route.Spec.Probe == nil {
+		allErrs = append(allErrs, field.Required(specPath.Child("probe"), "candidateGateways need a probe"))
+	} else if route.Spec.Probe.Type == ProbeTypeTCP && route.Spec.Probe.Port == 0 {
+		allErrs = append(allErrs, field.Required(specPath.Child("probe", "port"), "tcp probe needs a port"))
+	}
+	return allErrs
+}
+
+func (v *StaticRouteValidator) validateOverlapWithOthers(ctx context.Context, route *StaticRoute, subnets []*net.IPNet, subnetPaths []*field.Path) (field.ErrorList, error) {
+	routes := &StaticRouteList{}
+	if err := v.Client.List(ctx, routes); err != nil {
+		webhookLog.Error(err, "Unable to fetch StaticRoutes")
+		return nil, err
+	}
+	allErrs := field.ErrorList{}
+	table := v.tableOf(route)
+	for _, other := range routes.Items {
+		// Routes with different metrics can coexist, i.e. as primary and backup
+		if other.Name == route.Name || v.tableOf(&other) != table || metricOf(&other) != metricOf(route) {
+			continue
+		}
+		for _, otherSubnetText := range other.Spec.AllSubnets() {
+			_, otherSubnet, err := net.ParseCIDR(otherSubnetText)
+			if err != nil {
+				continue
+			}
+			for i, subnet := range subnets {
+				if cidr.Overlap(subnet, otherSubnet) {
+					allErrs = append(allErrs, field.Forbidden(subnetPaths[i], fmt.Sprintf("overlaps with the subnet %s of StaticRoute %s in table %d with the same metric", otherSubnetText, other.Name, table)))
+				}
+			}
+		}
+	}
+	return allErrs, nil
+}
+
+// validatePolicies checks the subnets against the StaticRoutePolicy objects, the same way as the nodes do
+func (v *StaticRouteValidator) validatePolicies(ctx context.Context, subnets []*net.IPNet, subnetPaths []*field.Path) (field.ErrorList, error) {
+	if !v.Policies {
+		return nil, nil
+	}
+	policies := &StaticRoutePolicyList{}
+	if err := v.Client.List(ctx, policies); err != nil {
+		return nil, err
+	}
+	protected, allowed, _ := policies.Subnets()
+	protectedSet, allowedSet := cidr.NewSet(protected...), cidr.NewSet(allowed...)
+	allErrs := field.ErrorList{}
+	for i, subnet := range subnets {
+		if p := protectedSet.Overlapping(subnet); p != nil {
+			allErrs = append(allErrs, field.Forbidden(subnetPaths[i], fmt.Sprintf("overlaps with the protected subnet %s of a StaticRoutePolicy", p.String())))
+		}
+		if len(allowed) != 0 && allowedSet.Containing(subnet) == nil {
+			allErrs = append(allErrs, field.Forbidden(subnetPaths[i], "is not inside any allowed subnet of the StaticRoutePolicies"))
+		}
+	}
+	return allErrs, nil
+}
+
+func (v *StaticRouteValidator) tableOf(route *StaticRoute) int {
+	if route.Spec.Table != nil {
+		return *route.Spec.Table
+	}
+	return v.DefaultTable
+}
+
+func metricOf(route *StaticRoute) int64 {
+	if route.Spec.Metric != nil {
+		return *route.Spec.Metric
+	}
+	return 0
+}
@@ -0,0 +1,260 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/IBM/staticroute-operator/pkg/cidr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newValidator(routes ...runtime.Object) *StaticRouteValidator {
+	s := runtime.NewScheme()
+	if err := AddToScheme(s); err != nil {
+		panic(err)
+	}
+	_, protected, _ := net.ParseCIDR("172.16.0.0/16")
+	return &StaticRouteValidator{
+		Client:           fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(routes...).Build(),
+		ProtectedSubnets: cidr.NewSet(protected),
+		DefaultTable:     254,
+		Policies:         true,
+	}
+}
+
+func newRoute(name, subnet string) *StaticRoute {
+	return &StaticRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       StaticRouteSpec{Subnet: subnet},
+	}
+}
+
+func TestValidateCreate(t *testing.T) {
+	table := 42
+	defaultTable := 254
+	metric := int64(100)
+	var testData = []struct {
+		name    string
+		modify  func(*StaticRoute)
+		message string
+	}{
+		{"valid", func(r *StaticRoute) {}, ""},
+		{"valid gateway", func(r *StaticRoute) { r.Spec.Gateway = "10.0.0.1" }, ""},
+		{"valid next hops", func(r *StaticRoute) { r.Spec.Gateways = []NextHop{{Gateway: "10.0.0.1"}, {Gateway: "10.0.0.2"}} }, ""},
+		{"overlap in other table", func(r *StaticRoute) { r.Spec.Subnet = "192.168.0.0/16"; r.Spec.Table = &table }, ""},
+		{"subnet is not CIDR", func(r *StaticRoute) { r.Spec.Subnet = "192.168.1.1" }, "spec.subnet: Invalid value"},
+		{"gateway is not IP", func(r *StaticRoute) { r.Spec.Gateway = "10.0.0" }, "must be an IP address"},
+		{"gateway family mismatch", func(r *StaticRoute) { r.Spec.Gateway = "fd00::1" }, "must be the same IP family as the subnet"},
+		{"next hop family mismatch", func(r *StaticRoute) { r.Spec.Gateways = []NextHop{{Gateway: "fd00::1"}} }, "spec.gateways[0].gateway"},
+		{"gateway and gateways", func(r *StaticRoute) { r.Spec.Gateway = "10.0.0.1"; r.Spec.Gateways = []NextHop{{Gateway: "10.0.0.2"}} }, "mutually exclusive"},
+		{"blackhole", func(r *StaticRoute) { r.Spec.Type = RouteTypeBlackhole }, ""},
+		{"unicast with gateway", func(r *StaticRoute) { r.Spec.Type = RouteTypeUnicast; r.Spec.Gateway = "10.0.0.1" }, ""},
+		{"prohibit with gateway", func(r *StaticRoute) { r.Spec.Type = RouteTypeProhibit; r.Spec.Gateway = "10.0.0.1" }, "spec.gateway: Forbidden: must not be set for prohibit routes"},
+		{"throw with next hops", func(r *StaticRoute) { r.Spec.Type = RouteTypeThrow; r.Spec.Gateways = []NextHop{{Gateway: "10.0.0.1"}} }, "spec.gateways: Forbidden"},
+		{"device route", func(r *StaticRoute) { r.Spec.Interface = "eth1" }, ""},
+		{"gateway with interface", func(r *StaticRoute) { r.Spec.Gateway = "10.0.0.1"; r.Spec.InterfaceAddressIn = "10.0.0.0/8" }, ""},
+		{"interface and interfaceAddressIn", func(r *StaticRoute) { r.Spec.Interface = "eth1"; r.Spec.InterfaceAddressIn = "10.0.0.0/8" }, "mutually exclusive"},
+		{"interfaceAddressIn is not CIDR", func(r *StaticRoute) { r.Spec.InterfaceAddressIn = "10.0.0.1" }, "spec.interfaceAddressIn: Invalid value"},
+		{"next hops with interface", func(r *StaticRoute) { r.Spec.Gateways = []NextHop{{Gateway: "10.0.0.1"}}; r.Spec.Interface = "eth1" }, "can not be combined with an interface"},
+		{"blackhole with interface", func(r *StaticRoute) { r.Spec.Type = RouteTypeBlackhole; r.Spec.Interface = "eth1" }, "spec.interface: Forbidden"},
+		{"src", func(r *StaticRoute) { r.Spec.Src = "10.0.0.5"; r.Spec.Scope = ScopeLink }, ""},
+		{"src family mismatch", func(r *StaticRoute) { r.Spec.Src = "fd00::5" }, "spec.src: Invalid value"},
+		{"IPv6 universe scope", func(r *StaticRoute) { r.Spec.Subnet = "fd00:10::/64"; r.Spec.Scope = ScopeUniverse }, ""},
+		{"IPv6 link scope", func(r *StaticRoute) { r.Spec.Subnet = "fd00:10::/64"; r.Spec.Scope = ScopeLink }, "spec.scope: Forbidden: IPv6 routes support only the universe scope"},
+		{"src and srcInterface", func(r *StaticRoute) { r.Spec.Src = "10.0.0.5"; r.Spec.SrcInterface = "tun0" }, "src and srcInterface are mutually exclusive"},
+		{"onLink", func(r *StaticRoute) {
+			r.Spec.Gateway = "192.168.100.1"
+			r.Spec.Interface = "eth1"
+			r.Spec.OnLink = true
+		}, ""},
+		{"onLink without interface", func(r *StaticRoute) { r.Spec.Gateway = "192.168.100.1"; r.Spec.OnLink = true }, "spec.onLink: Forbidden"},
+		{"candidate gateways", func(r *StaticRoute) {
+			r.Spec.CandidateGateways = []string{"10.0.0.1", "10.0.0.2"}
+			r.Spec.Probe = &GatewayProbe{Type: ProbeTypeTCP, Port: 22}
+		}, ""},
+		{"candidate gateways without probe", func(r *StaticRoute) { r.Spec.CandidateGateways = []string{"10.0.0.1"} }, "spec.probe: Required value"},
+		{"probe without candidate gateways", func(r *StaticRoute) { r.Spec.Probe = &GatewayProbe{Type: ProbeTypeICMP} }, "spec.probe: Forbidden"},
+		{"tcp probe without port", func(r *StaticRoute) {
+			r.Spec.CandidateGateways = []string{"10.0.0.1"}
+			r.Spec.Probe = &GatewayProbe{Type: ProbeTypeTCP}
+		}, "spec.probe.port: Required value"},
+		{"candidate gateways and gateway", func(r *StaticRoute) {
+			r.Spec.Gateway = "10.0.0.1"
+			r.Spec.CandidateGateways = []string{"10.0.0.2"}
+			r.Spec.Probe = &GatewayProbe{Type: ProbeTypeNeighbor}
+		}, "candidateGateways is mutually exclusive"},
+		{"duplicate candidate gateway", func(r *StaticRoute) {
+			r.Spec.CandidateGateways = []string{"10.0.0.1", "10.0.0.1"}
+			r.Spec.Probe = &GatewayProbe{Type: ProbeTypeNeighbor}
+		}, "spec.candidateGateways[1]: Duplicate value"},
+		{"candidate gateway family mismatch", func(r *StaticRoute) {
+			r.Spec.CandidateGateways = []string{"fd00::1"}
+			r.Spec.Probe = &GatewayProbe{Type: ProbeTypeNeighbor}
+		}, "spec.candidateGateways[0]: Invalid value"},
+		{"blackhole with candidate gateways", func(r *StaticRoute) {
+			r.Spec.Type = RouteTypeBlackhole
+			r.Spec.CandidateGateways = []string{"10.0.0.1"}
+			r.Spec.Probe = &GatewayProbe{Type: ProbeTypeNeighbor}
+		}, "spec.candidateGateways: Forbidden"},
+		{"protected", func(r *StaticRoute) { r.Spec.Subnet = "172.16.10.0/24" }, "overlaps with the protected subnet 172.16.0.0/16"},
+		{"protected inside", func(r *StaticRoute) { r.Spec.Subnet = "172.0.0.0/8" }, "overlaps with the protected subnet 172.16.0.0/16"},
+		{"wrong selector operator", func(r *StaticRoute) {
+			r.Spec.Selectors = []metav1.LabelSelectorRequirement{{Key: "key", Operator: "Equals", Values: []string{"value"}}}
+		}, "spec.selectors[0].operator"},
+		{"wrong selector values", func(r *StaticRoute) {
+			r.Spec.Selectors = []metav1.LabelSelectorRequirement{{Key: "key", Operator: metav1.LabelSelectorOpExists, Values: []string{"value"}}}
+		}, "spec.selectors[0].values"},
+		{"overlap with other route", func(r *StaticRoute) { r.Spec.Subnet = "192.168.0.0/16" }, "overlaps with the subnet 192.168.1.0/24 of StaticRoute other in table 254 with the same metric"},
+		{"overlap with other metric", func(r *StaticRoute) { r.Spec.Subnet = "192.168.1.0/24"; r.Spec.Metric = &metric }, ""},
+		{"overlap with other route in explicit default table", func(r *StaticRoute) { r.Spec.Subnet = "192.168.1.128/25"; r.Spec.Table = &defaultTable }, "StaticRoute other"},
+		{"subnets", func(r *StaticRoute) { r.Spec.Subnet = ""; r.Spec.Subnets = []string{"10.10.0.0/16", "10.20.0.0/16"} }, ""},
+		{"subnet and subnets", func(r *StaticRoute) { r.Spec.Subnets = []string{"10.20.0.0/16"} }, "subnet and subnets are mutually exclusive"},
+		{"subnets item is not CIDR", func(r *StaticRoute) { r.Spec.Subnet = ""; r.Spec.Subnets = []string{"10.20.0.0/16", "10.30.0.1"} }, "spec.subnets[1]: Invalid value"},
+		{"subnets duplicate", func(r *StaticRoute) { r.Spec.Subnet = ""; r.Spec.Subnets = []string{"10.20.0.0/16", "10.20.0.1/16"} }, "spec.subnets[1]: Duplicate value"},
+		{"subnets family mismatch", func(r *StaticRoute) { r.Spec.Subnet = ""; r.Spec.Subnets = []string{"10.20.0.0/16", "fd00::/64"} }, "must be the same IP family as the other subnets"},
+		{"subnets protected", func(r *StaticRoute) { r.Spec.Subnet = ""; r.Spec.Subnets = []string{"10.20.0.0/16", "172.16.10.0/24"} }, "spec.subnets[1]: Forbidden: overlaps with the protected subnet 172.16.0.0/16"},
+		{"subnets overlap with other route", func(r *StaticRoute) { r.Spec.Subnet = ""; r.Spec.Subnets = []string{"10.20.0.0/16", "192.168.1.0/25"} }, "spec.subnets[1]: Forbidden: overlaps with the subnet 192.168.1.0/24 of StaticRoute other"},
+	}
+	for _, td := range testData {
+		route := newRoute("route", "10.10.0.0/16")
+		td.modify(route)
+		v := newValidator(newRoute("other", "192.168.1.0/24"))
+
+		_, err := v.ValidateCreate(context.Background(), route)
+
+		if td.message == "" && err != nil {
+			t.Errorf("Error must be nil at %s: %s", td.name, err.Error())
+		} else if td.message != "" && (err == nil || !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), td.message)) {
+			t.Errorf("Error must contain '%s' at %s: %v", td.message, td.name, err)
+		}
+	}
+}
+
+func TestValidateCreateSelf(t *testing.T) {
+	route := newRoute("route", "192.168.1.0/24")
+	v := newValidator(route.DeepCopy())
+
+	_, err := v.ValidateCreate(context.Background(), route)
+
+	if err != nil {
+		t.Errorf("Route must not overlap with itself: %s", err.Error())
+	}
+}
+
+func TestValidateCreatePolicy(t *testing.T) {
+	var testData = []struct {
+		name    string
+		subnet  string
+		message string
+	}{
+		{"allowed", "192.168.10.0/24", ""},
+		{"protected by policy", "10.10.0.0/16", "overlaps with the protected subnet 10.0.0.0/8 of a StaticRoutePolicy"},
+		{"not allowed", "11.0.0.0/8", "is not inside any allowed subnet"},
+	}
+	for _, td := range testData {
+		v := newValidator(&StaticRoutePolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Spec:       StaticRoutePolicySpec{ProtectedSubnets: []string{"10.0.0.0/8"}, AllowedSubnets: []string{"192.168.0.0/16", "10.0.0.0/8"}},
+		})
+
+		_, err := v.ValidateCreate(context.Background(), newRoute("route", td.subnet))
+
+		if td.message == "" && err != nil {
+			t.Errorf("Error must be nil at %s: %s", td.name, err.Error())
+		} else if td.message != "" && (err == nil || !apierrors.IsInvalid(err) || !strings.Contains(err.Error(), td.message)) {
+			t.Errorf("Error must contain '%s' at %s: %v", td.message, td.name, err)
+		}
+	}
+}
+
+func TestValidateCreatePoliciesDisabled(t *testing.T) {
+	v := newValidator(&StaticRoutePolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+		Spec:       StaticRoutePolicySpec{ProtectedSubnets: []string{"10.0.0.0/8"}},
+	})
+	v.Policies = false
+
+	_, err := v.ValidateCreate(context.Background(), newRoute("route", "10.10.0.0/16"))
+
+	if err != nil {
+		t.Errorf("Policies must not be looked up without the CRD: %s", err.Error())
+	}
+}
+
+func TestValidateCreateListFails(t *testing.T) {
+	v := newValidator()
+	v.Client = failingReader{}
+
+	_, err := v.ValidateCreate(context.Background(), newRoute("route", "192.168.1.0/24"))
+
+	if !apierrors.IsInternalError(err) {
+		t.Errorf("Error must be internal error: %v", err)
+	}
+}
+
+func TestValidateUpdateSpecNotChanged(t *testing.T) {
+	old := newRoute("route", "172.16.1.0/24")
+	route := old.DeepCopy()
+	route.Finalizers = nil
+	v := newValidator()
+
+	_, err := v.ValidateUpdate(context.Background(), old, route)
+
+	if err != nil {
+		t.Errorf("Metadata change must be accepted: %s", err.Error())
+	}
+}
+
+func TestValidateUpdateSpecChanged(t *testing.T) {
+	old := newRoute("route", "192.168.1.0/24")
+	route := old.DeepCopy()
+	route.Spec.Subnet = "172.16.1.0/24"
+	v := newValidator()
+
+	_, err := v.ValidateUpdate(context.Background(), old, route)
+
+	if !apierrors.IsInvalid(err) {
+		t.Errorf("Spec change must be validated: %v", err)
+	}
+}
+
+func TestValidateDelete(t *testing.T) {
+	v := newValidator()
+
+	_, err := v.ValidateDelete(context.Background(), newRoute("route", "172.16.1.0/24"))
+
+	if err != nil {
+		t.Errorf("Delete must be accepted: %s", err.Error())
+	}
+}
+
+type failingReader struct {
+	client.Reader
+}
+
+func (failingReader) List(context.Context, client.ObjectList, ...client.ListOption) error {
+	return errors.New("list failed")
+}
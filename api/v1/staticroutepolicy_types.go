@@ -0,0 +1,82 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1
+
+import (
+	"net"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StaticRoutePolicySpec defines the subnets the StaticRoutes may point to
+type StaticRoutePolicySpec struct {
+	// ProtectedSubnets are the subnets a StaticRoute must not overlap with, in CIDR notation
+	// +optional
+	ProtectedSubnets []string `json:"protectedSubnets,omitempty"`
+	// AllowedSubnets restrict the StaticRoutes to the given subnets, in CIDR notation. The subnet of a StaticRoute
+	// must be inside one of the allowed subnets of any policy. Empty list allows every subnet.
+	// +optional
+	AllowedSubnets []string `json:"allowedSubnets,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// StaticRoutePolicy restricts the subnets of the StaticRoutes, it is evaluated by every node
+// +kubebuilder:resource:path=staticroutepolicies,scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,priority=0
+// +kubebuilder:printcolumn:name="Protected",type=string,JSONPath=`.spec.protectedSubnets`,priority=1
+// +kubebuilder:printcolumn:name="Allowed",type=string,JSONPath=`.spec.allowedSubnets`,priority=1
+type StaticRoutePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec StaticRoutePolicySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// StaticRoutePolicyList contains a list of StaticRoutePolicy
+type StaticRoutePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StaticRoutePolicy `json:"items"`
+}
+
+// Subnets merges the subnets of all the policies. Entries which are not in CIDR notation are returned as invalid,
+// so the callers can report them.
+func (l *StaticRoutePolicyList) Subnets() (protected, allowed []*net.IPNet, invalid []string) {
+	parse := func(cidrs []string, subnets []*net.IPNet) []*net.IPNet {
+		for _, cidr := range cidrs {
+			_, subnet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				invalid = append(invalid, cidr)
+				continue
+			}
+			subnets = append(subnets, subnet)
+		}
+		return subnets
+	}
+	for i := range l.Items {
+		protected = parse(l.Items[i].Spec.ProtectedSubnets, protected)
+		allowed = parse(l.Items[i].Spec.AllowedSubnets, allowed)
+	}
+	return
+}
+
+func init() {
+	SchemeBuilder.Register(&StaticRoutePolicy{}, &StaticRoutePolicyList{})
+}
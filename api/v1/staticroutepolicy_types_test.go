@@ -0,0 +1,40 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1
+
+import (
+	"testing"
+)
+
+func TestStaticRoutePolicyListSubnets(t *testing.T) {
+	policies := &StaticRoutePolicyList{Items: []StaticRoutePolicy{
+		{Spec: StaticRoutePolicySpec{ProtectedSubnets: []string{"10.0.0.0/8", "10.0.0"}}},
+		{Spec: StaticRoutePolicySpec{ProtectedSubnets: []string{"fd00::/8"}, AllowedSubnets: []string{"192.168.0.0/16"}}},
+	}}
+
+	protected, allowed, invalid := policies.Subnets()
+
+	if len(protected) != 2 || protected[0].String() != "10.0.0.0/8" || protected[1].String() != "fd00::/8" {
+		t.Errorf("Protected subnets of all policies must be merged: %v", protected)
+	}
+	if len(allowed) != 1 || allowed[0].String() != "192.168.0.0/16" {
+		t.Errorf("Allowed subnets must be returned: %v", allowed)
+	}
+	if len(invalid) != 1 || invalid[0] != "10.0.0" {
+		t.Errorf("Invalid subnets must be reported: %v", invalid)
+	}
+}
@@ -0,0 +1,112 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StaticRouteRuleSpec defines the desired state of StaticRouteRule, it is the equivalent of an `ip rule`
+// +kubebuilder:validation:XValidation:rule="!has(self.family) || (!has(self.from) && !has(self.to))",message="family can be set only if neither from nor to is set"
+type StaticRouteRuleSpec struct {
+	// From matches the source address of the packets in the form of: "x.x.x.x/x" or "x:x::x/x" (optional)
+	// +kubebuilder:validation:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}(\/([0-9]|[1-2][0-9]|3[0-2]))?|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7}(\/([0-9]|[1-9][0-9]|1[0-1][0-9]|12[0-8]))?)$`
+	From string `json:"from,omitempty"`
+
+	// To matches the destination address of the packets, must be the same IP family as from (optional)
+	// +kubebuilder:validation:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}(\/([0-9]|[1-2][0-9]|3[0-2]))?|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7}(\/([0-9]|[1-9][0-9]|1[0-1][0-9]|12[0-8]))?)$`
+	To string `json:"to,omitempty"`
+
+	// FwMark matches the firewall mark of the packets (optional)
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	FwMark *int64 `json:"fwMark,omitempty"`
+
+	// FwMask is applied on the firewall mark before the comparison (optional, all bits by default)
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	FwMask *int64 `json:"fwMask,omitempty"`
+
+	// Iif matches the incoming interface of the packets (optional)
+	// +kubebuilder:validation:MaxLength=15
+	Iif string `json:"iif,omitempty"`
+
+	// Oif matches the outgoing interface of the packets (optional)
+	// +kubebuilder:validation:MaxLength=15
+	Oif string `json:"oif,omitempty"`
+
+	// Priority of the rule, the rules are evaluated in increasing priority order
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32765
+	Priority int `json:"priority"`
+
+	// Table the matching packets are looked up in
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=254
+	Table int `json:"table"`
+
+	// Family of the rule if neither from nor to is set (optional, default is IPv4)
+	// +kubebuilder:validation:Enum=IPv4;IPv6
+	Family string `json:"family,omitempty"`
+
+	// Selector defines the target nodes by requirement (optional, default is apply to all)
+	Selectors []metav1.LabelSelectorRequirement `json:"selectors,omitempty"`
+}
+
+// StaticRouteRuleNodeStatus defines the observed state of one node, related to the StaticRouteRule
+type StaticRouteRuleNodeStatus struct {
+	Hostname string              `json:"hostname"`
+	State    StaticRouteRuleSpec `json:"state"`
+	Error    string              `json:"error"`
+}
+
+// StaticRouteRuleStatus defines the observed state of StaticRouteRule
+type StaticRouteRuleStatus struct {
+	NodeStatus []StaticRouteRuleNodeStatus `json:"nodeStatus"`
+}
+
+// +kubebuilder:object:root=true
+
+// StaticRouteRule is the Schema for the staticrouterules API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=staticrouterules,scope=Cluster
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`,priority=0
+// +kubebuilder:printcolumn:name="Table",type=integer,JSONPath=`.spec.table`,priority=0
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,priority=0
+// +kubebuilder:printcolumn:name="From",type=string,JSONPath=`.spec.from`,priority=1
+// +kubebuilder:printcolumn:name="To",type=string,JSONPath=`.spec.to`,priority=1
+// +kubebuilder:printcolumn:name="FwMark",type=integer,JSONPath=`.spec.fwMark`,priority=1
+type StaticRouteRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StaticRouteRuleSpec   `json:"spec,omitempty"`
+	Status StaticRouteRuleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// StaticRouteRuleList contains a list of StaticRouteRule
+type StaticRouteRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StaticRouteRule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&StaticRouteRule{}, &StaticRouteRuleList{})
+}
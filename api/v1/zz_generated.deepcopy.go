@@ -25,6 +25,60 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayProbe) DeepCopyInto(out *GatewayProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayProbe.
+func (in *GatewayProbe) DeepCopy() *GatewayProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayProbeStatus) DeepCopyInto(out *GatewayProbeStatus) {
+	*out = *in
+	if in.Since != nil {
+		in, out := &in.Since, &out.Since
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayProbeStatus.
+func (in *GatewayProbeStatus) DeepCopy() *GatewayProbeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayProbeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NextHop) DeepCopyInto(out *NextHop) {
+	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NextHop.
+func (in *NextHop) DeepCopy() *NextHop {
+	if in == nil {
+		return nil
+	}
+	out := new(NextHop)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StaticRoute) DeepCopyInto(out *StaticRoute) {
 	*out = *in
@@ -88,6 +142,22 @@ func (in *StaticRouteList) DeepCopyObject() runtime.Object {
 func (in *StaticRouteNodeStatus) DeepCopyInto(out *StaticRouteNodeStatus) {
 	*out = *in
 	in.State.DeepCopyInto(&out.State)
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]SubnetStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.GatewayProbes != nil {
+		in, out := &in.GatewayProbes, &out.GatewayProbes
+		*out = make([]GatewayProbeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TamperedAt != nil {
+		in, out := &in.TamperedAt, &out.TamperedAt
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticRouteNodeStatus.
@@ -100,14 +170,253 @@ func (in *StaticRouteNodeStatus) DeepCopy() *StaticRouteNodeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticRoutePolicy) DeepCopyInto(out *StaticRoutePolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticRoutePolicy.
+func (in *StaticRoutePolicy) DeepCopy() *StaticRoutePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticRoutePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StaticRoutePolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticRoutePolicyList) DeepCopyInto(out *StaticRoutePolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StaticRoutePolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticRoutePolicyList.
+func (in *StaticRoutePolicyList) DeepCopy() *StaticRoutePolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticRoutePolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StaticRoutePolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticRoutePolicySpec) DeepCopyInto(out *StaticRoutePolicySpec) {
+	*out = *in
+	if in.ProtectedSubnets != nil {
+		in, out := &in.ProtectedSubnets, &out.ProtectedSubnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedSubnets != nil {
+		in, out := &in.AllowedSubnets, &out.AllowedSubnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticRoutePolicySpec.
+func (in *StaticRoutePolicySpec) DeepCopy() *StaticRoutePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticRoutePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticRouteRule) DeepCopyInto(out *StaticRouteRule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticRouteRule.
+func (in *StaticRouteRule) DeepCopy() *StaticRouteRule {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticRouteRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StaticRouteRule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticRouteRuleList) DeepCopyInto(out *StaticRouteRuleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StaticRouteRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticRouteRuleList.
+func (in *StaticRouteRuleList) DeepCopy() *StaticRouteRuleList {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticRouteRuleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StaticRouteRuleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticRouteRuleNodeStatus) DeepCopyInto(out *StaticRouteRuleNodeStatus) {
+	*out = *in
+	in.State.DeepCopyInto(&out.State)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticRouteRuleNodeStatus.
+func (in *StaticRouteRuleNodeStatus) DeepCopy() *StaticRouteRuleNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticRouteRuleNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticRouteRuleSpec) DeepCopyInto(out *StaticRouteRuleSpec) {
+	*out = *in
+	if in.FwMark != nil {
+		in, out := &in.FwMark, &out.FwMark
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FwMask != nil {
+		in, out := &in.FwMask, &out.FwMask
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Selectors != nil {
+		in, out := &in.Selectors, &out.Selectors
+		*out = make([]metav1.LabelSelectorRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticRouteRuleSpec.
+func (in *StaticRouteRuleSpec) DeepCopy() *StaticRouteRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticRouteRuleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticRouteRuleStatus) DeepCopyInto(out *StaticRouteRuleStatus) {
+	*out = *in
+	if in.NodeStatus != nil {
+		in, out := &in.NodeStatus, &out.NodeStatus
+		*out = make([]StaticRouteRuleNodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticRouteRuleStatus.
+func (in *StaticRouteRuleStatus) DeepCopy() *StaticRouteRuleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticRouteRuleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StaticRouteSpec) DeepCopyInto(out *StaticRouteSpec) {
 	*out = *in
+	if in.Subnets != nil {
+		in, out := &in.Subnets, &out.Subnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Gateways != nil {
+		in, out := &in.Gateways, &out.Gateways
+		*out = make([]NextHop, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CandidateGateways != nil {
+		in, out := &in.CandidateGateways, &out.CandidateGateways
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Probe != nil {
+		in, out := &in.Probe, &out.Probe
+		*out = new(GatewayProbe)
+		**out = **in
+	}
 	if in.Table != nil {
 		in, out := &in.Table, &out.Table
 		*out = new(int)
 		**out = **in
 	}
+	if in.Metric != nil {
+		in, out := &in.Metric, &out.Metric
+		*out = new(int64)
+		**out = **in
+	}
 	if in.Selectors != nil {
 		in, out := &in.Selectors, &out.Selectors
 		*out = make([]metav1.LabelSelectorRequirement, len(*in))
@@ -130,6 +439,13 @@ func (in *StaticRouteSpec) DeepCopy() *StaticRouteSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StaticRouteStatus) DeepCopyInto(out *StaticRouteStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.NodeStatus != nil {
 		in, out := &in.NodeStatus, &out.NodeStatus
 		*out = make([]StaticRouteNodeStatus, len(*in))
@@ -148,3 +464,18 @@ func (in *StaticRouteStatus) DeepCopy() *StaticRouteStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnetStatus) DeepCopyInto(out *SubnetStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetStatus.
+func (in *SubnetStatus) DeepCopy() *SubnetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,304 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	v1 "github.com/IBM/staticroute-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// blank assignment to verify that StaticRoute implements conversion.Convertible
+var _ conversion.Convertible = &StaticRoute{}
+
+// nodeSelectorAnnotation keeps the node selector of v2 on the v1 object, as v1 has no matchLabels
+const nodeSelectorAnnotation = "static-route.ibm.com/v2-node-selector"
+
+// ConvertTo converts the StaticRoute to the hub version (v1). The conversion keeps the meaning of the route, but
+// not always its form: a single subnet becomes subnet, a single next hop without weight becomes gateway and the
+// matchLabels of the node selector become In requirements. The node selector is kept in an annotation as well,
+// so ConvertFrom restores its form as long as the selectors are not changed in v1.
+func (src *StaticRoute) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1.StaticRoute)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	spec, err := specToV1(src.Spec)
+	if err != nil {
+		return err
+	}
+	dst.Spec = spec
+	if src.Spec.NodeSelector != nil && len(src.Spec.NodeSelector.MatchLabels) != 0 {
+		selector, err := json.Marshal(src.Spec.NodeSelector)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[nodeSelectorAnnotation] = string(selector)
+	} else {
+		deleteAnnotation(&dst.ObjectMeta, nodeSelectorAnnotation)
+	}
+	dst.Status = v1.StaticRouteStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		DesiredNodes:       src.Status.DesiredNodes,
+		AppliedNodes:       src.Status.AppliedNodes,
+		FailedNodes:        src.Status.FailedNodes,
+		Conditions:         copyConditions(src.Status.Conditions),
+	}
+	for _, nodeStatus := range src.Status.NodeStatus {
+		state, err := specToV1(nodeStatus.State)
+		if err != nil {
+			return err
+		}
+		out := v1.StaticRouteNodeStatus{
+			Hostname:           nodeStatus.Hostname,
+			State:              state,
+			Error:              nodeStatus.Error,
+			ObservedGeneration: nodeStatus.ObservedGeneration,
+			ActiveGateway:      nodeStatus.ActiveGateway,
+			TamperedAt:         nodeStatus.TamperedAt.DeepCopy(),
+			TamperCount:        nodeStatus.TamperCount,
+		}
+		for _, subnet := range nodeStatus.Subnets {
+			out.Subnets = append(out.Subnets, v1.SubnetStatus{Subnet: subnet.Subnet, Error: subnet.Error})
+		}
+		for _, probe := range nodeStatus.GatewayProbes {
+			out.GatewayProbes = append(out.GatewayProbes, v1.GatewayProbeStatus{Gateway: probe.Gateway, Healthy: probe.Healthy, Since: probe.Since.DeepCopy(), Message: probe.Message})
+		}
+		dst.Status.NodeStatus = append(dst.Status.NodeStatus, out)
+	}
+	return nil
+}
+
+// ConvertFrom converts the hub version (v1) to this version
+func (dst *StaticRoute) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1.StaticRoute)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	deleteAnnotation(&dst.ObjectMeta, nodeSelectorAnnotation)
+	dst.Spec = specFromV1(src.Spec)
+	selector := keptNodeSelector(src)
+	if selector != nil {
+		dst.Spec.NodeSelector = selector
+	}
+	dst.Status = StaticRouteStatus{
+		ObservedGeneration: src.Status.ObservedGeneration,
+		DesiredNodes:       src.Status.DesiredNodes,
+		AppliedNodes:       src.Status.AppliedNodes,
+		FailedNodes:        src.Status.FailedNodes,
+		Conditions:         copyConditions(src.Status.Conditions),
+	}
+	for _, nodeStatus := range src.Status.NodeStatus {
+		out := StaticRouteNodeStatus{
+			Hostname:           nodeStatus.Hostname,
+			State:              specFromV1(nodeStatus.State),
+			Error:              nodeStatus.Error,
+			ObservedGeneration: nodeStatus.ObservedGeneration,
+			ActiveGateway:      nodeStatus.ActiveGateway,
+			TamperedAt:         nodeStatus.TamperedAt.DeepCopy(),
+			TamperCount:        nodeStatus.TamperCount,
+		}
+		// The state reports the selectors of the Spec, so it has the same form as the Spec
+		if selector != nil && reflect.DeepEqual(nodeStatus.State.Selectors, src.Spec.Selectors) {
+			out.State.NodeSelector = selector.DeepCopy()
+		}
+		for _, subnet := range nodeStatus.Subnets {
+			out.Subnets = append(out.Subnets, SubnetStatus{Subnet: subnet.Subnet, Error: subnet.Error})
+		}
+		for _, probe := range nodeStatus.GatewayProbes {
+			out.GatewayProbes = append(out.GatewayProbes, GatewayProbeStatus{Gateway: probe.Gateway, Healthy: probe.Healthy, Since: probe.Since.DeepCopy(), Message: probe.Message})
+		}
+		dst.Status.NodeStatus = append(dst.Status.NodeStatus, out)
+	}
+	return nil
+}
+
+func specToV1(in StaticRouteSpec) (v1.StaticRouteSpec, error) {
+	out := v1.StaticRouteSpec{
+		Interface:          in.Interface,
+		InterfaceAddressIn: in.InterfaceAddressIn,
+		Src:                in.Src,
+		SrcInterface:       in.SrcInterface,
+		Scope:              in.Scope,
+		OnLink:             in.OnLink,
+		MTU:                in.MTU,
+		AdvMSS:             in.AdvMSS,
+		InitCwnd:           in.InitCwnd,
+		InitRwnd:           in.InitRwnd,
+		HopLimit:           in.HopLimit,
+		Type:               in.Type,
+	}
+	if len(in.Subnets) == 1 {
+		out.Subnet = in.Subnets[0]
+	} else if len(in.Subnets) != 0 {
+		out.Subnets = append([]string{}, in.Subnets...)
+	}
+	if len(in.NextHops) == 1 && in.NextHops[0].Weight == nil {
+		out.Gateway = in.NextHops[0].Gateway
+	} else {
+		for _, nextHop := range in.NextHops {
+			out.Gateways = append(out.Gateways, v1.NextHop{Gateway: nextHop.Gateway, Weight: copyInt(nextHop.Weight)})
+		}
+	}
+	if in.Table != nil {
+		table, err := tableToV1(*in.Table)
+		if err != nil {
+			return out, err
+		}
+		out.Table = &table
+	}
+	if in.Metric != nil {
+		metric := *in.Metric
+		out.Metric = &metric
+	}
+	if len(in.CandidateGateways) != 0 {
+		out.CandidateGateways = append([]string{}, in.CandidateGateways...)
+	}
+	if in.Probe != nil {
+		probe := v1.GatewayProbe(*in.Probe)
+		out.Probe = &probe
+	}
+	out.Selectors = selectorRequirements(in.NodeSelector)
+	return out, nil
+}
+
+// tableToV1 converts the table to the int of v1, if it is a table of the kernel, which v1 can hold
+func tableToV1(table int64) (int, error) {
+	if table < 0 || table > math.MaxUint32 || int64(int(table)) != table {
+		return 0, fmt.Errorf("table %d is out of the range of v1", table)
+	}
+	return int(table), nil
+}
+
+func specFromV1(in v1.StaticRouteSpec) StaticRouteSpec {
+	out := StaticRouteSpec{
+		Interface:          in.Interface,
+		InterfaceAddressIn: in.InterfaceAddressIn,
+		Src:                in.Src,
+		SrcInterface:       in.SrcInterface,
+		Scope:              in.Scope,
+		OnLink:             in.OnLink,
+		MTU:                in.MTU,
+		AdvMSS:             in.AdvMSS,
+		InitCwnd:           in.InitCwnd,
+		InitRwnd:           in.InitRwnd,
+		HopLimit:           in.HopLimit,
+		Type:               in.Type,
+	}
+	if len(in.Subnet) != 0 || len(in.Subnets) != 0 {
+		out.Subnets = append([]string{}, in.AllSubnets()...)
+	}
+	if len(in.Gateway) != 0 {
+		out.NextHops = []NextHop{{Gateway: in.Gateway}}
+	}
+	for _, nextHop := range in.Gateways {
+		out.NextHops = append(out.NextHops, NextHop{Gateway: nextHop.Gateway, Weight: copyInt(nextHop.Weight)})
+	}
+	if in.Table != nil {
+		table := int64(*in.Table)
+		out.Table = &table
+	}
+	if in.Metric != nil {
+		metric := *in.Metric
+		out.Metric = &metric
+	}
+	if len(in.CandidateGateways) != 0 {
+		out.CandidateGateways = append([]string{}, in.CandidateGateways...)
+	}
+	if in.Probe != nil {
+		probe := GatewayProbe(*in.Probe)
+		out.Probe = &probe
+	}
+	if len(in.Selectors) != 0 {
+		out.NodeSelector = &metav1.LabelSelector{}
+		for _, requirement := range in.Selectors {
+			out.NodeSelector.MatchExpressions = append(out.NodeSelector.MatchExpressions, *requirement.DeepCopy())
+		}
+	}
+	return out
+}
+
+// selectorRequirements converts the label selector to requirements, matchLabels are sorted by the key
+func selectorRequirements(selector *metav1.LabelSelector) []metav1.LabelSelectorRequirement {
+	if selector == nil {
+		return nil
+	}
+	var requirements []metav1.LabelSelectorRequirement
+	keys := make([]string, 0, len(selector.MatchLabels))
+	for key := range selector.MatchLabels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		requirements = append(requirements, metav1.LabelSelectorRequirement{Key: key, Operator: metav1.LabelSelectorOpIn, Values: []string{selector.MatchLabels[key]}})
+	}
+	for _, requirement := range selector.MatchExpressions {
+		requirements = append(requirements, *requirement.DeepCopy())
+	}
+	return requirements
+}
+
+// keptNodeSelector returns the node selector kept in the annotation, if the selectors were not changed since then
+func keptNodeSelector(route *v1.StaticRoute) *metav1.LabelSelector {
+	annotation, found := route.Annotations[nodeSelectorAnnotation]
+	if !found {
+		return nil
+	}
+	selector := &metav1.LabelSelector{}
+	if err := json.Unmarshal([]byte(annotation), selector); err != nil {
+		return nil
+	}
+	if !reflect.DeepEqual(selectorRequirements(selector), route.Spec.Selectors) {
+		return nil
+	}
+	return selector
+}
+
+// deleteAnnotation removes the annotation, the annotations become nil if it was the only one
+func deleteAnnotation(meta *metav1.ObjectMeta, key string) {
+	if _, found := meta.Annotations[key]; !found {
+		return
+	}
+	delete(meta.Annotations, key)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+}
+
+func copyConditions(conditions []metav1.Condition) []metav1.Condition {
+	if conditions == nil {
+		return nil
+	}
+	out := make([]metav1.Condition, len(conditions))
+	for i := range conditions {
+		conditions[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
+
+func copyInt(i *int) *int {
+	if i == nil {
+		return nil
+	}
+	out := *i
+	return &out
+}
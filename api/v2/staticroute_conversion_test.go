@@ -0,0 +1,237 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v2
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/IBM/staticroute-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConvertFromV1(t *testing.T) {
+	table := 42
+	weight := 2
+	var testData = []struct {
+		name     string
+		in       v1.StaticRouteSpec
+		expected StaticRouteSpec
+	}{
+		{
+			"single subnet and gateway",
+			v1.StaticRouteSpec{Subnet: "10.0.0.0/16", Gateway: "10.1.0.1", Table: &table},
+			StaticRouteSpec{Subnets: []string{"10.0.0.0/16"}, NextHops: []NextHop{{Gateway: "10.1.0.1"}}, Table: ptr(int64(42))},
+		},
+		{
+			"multiple subnets and gateways",
+			v1.StaticRouteSpec{Subnets: []string{"10.0.0.0/16", "10.1.0.0/16"}, Gateways: []v1.NextHop{{Gateway: "10.2.0.1", Weight: &weight}, {Gateway: "10.2.0.2"}}},
+			StaticRouteSpec{Subnets: []string{"10.0.0.0/16", "10.1.0.0/16"}, NextHops: []NextHop{{Gateway: "10.2.0.1", Weight: &weight}, {Gateway: "10.2.0.2"}}},
+		},
+		{
+			"selectors",
+			v1.StaticRouteSpec{Subnet: "10.0.0.0/16", Selectors: []metav1.LabelSelectorRequirement{{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"a"}}}},
+			StaticRouteSpec{Subnets: []string{"10.0.0.0/16"}, NodeSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"a"}}}}},
+		},
+		{
+			"blackhole",
+			v1.StaticRouteSpec{Subnet: "10.0.0.0/16", Type: v1.RouteTypeBlackhole},
+			StaticRouteSpec{Subnets: []string{"10.0.0.0/16"}, Type: v1.RouteTypeBlackhole},
+		},
+		{
+			"candidate gateways",
+			v1.StaticRouteSpec{Subnet: "10.0.0.0/16", CandidateGateways: []string{"10.1.0.1", "10.1.0.2"}, Probe: &v1.GatewayProbe{Type: v1.ProbeTypeTCP, Port: 22}},
+			StaticRouteSpec{Subnets: []string{"10.0.0.0/16"}, CandidateGateways: []string{"10.1.0.1", "10.1.0.2"}, Probe: &GatewayProbe{Type: v1.ProbeTypeTCP, Port: 22}},
+		},
+	}
+	for _, td := range testData {
+		t.Run(td.name, func(t *testing.T) {
+			src := &v1.StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: "CR"}, Spec: td.in}
+			src.Status.NodeStatus = []v1.StaticRouteNodeStatus{{Hostname: "hostname", State: td.in, Subnets: []v1.SubnetStatus{{Subnet: "10.0.0.0/16", Error: "failed"}}}}
+			dst := &StaticRoute{}
+
+			if err := dst.ConvertFrom(src); err != nil {
+				t.Fatal(err)
+			}
+
+			if dst.Name != "CR" {
+				t.Errorf("Metadata must be copied: %v", dst.ObjectMeta)
+			}
+			if !reflect.DeepEqual(dst.Spec, td.expected) {
+				t.Errorf("Spec mismatch: expected %+v, actual %+v", td.expected, dst.Spec)
+			}
+			if len(dst.Status.NodeStatus) != 1 || !reflect.DeepEqual(dst.Status.NodeStatus[0].State, td.expected) || dst.Status.NodeStatus[0].Subnets[0].Error != "failed" {
+				t.Errorf("Status mismatch: %+v", dst.Status)
+			}
+		})
+	}
+}
+
+func TestConvertToV1(t *testing.T) {
+	weight := 2
+	var testData = []struct {
+		name     string
+		in       StaticRouteSpec
+		expected v1.StaticRouteSpec
+	}{
+		{
+			"single subnet and next hop",
+			StaticRouteSpec{Subnets: []string{"10.0.0.0/16"}, NextHops: []NextHop{{Gateway: "10.1.0.1"}}, Table: ptr(int64(42))},
+			v1.StaticRouteSpec{Subnet: "10.0.0.0/16", Gateway: "10.1.0.1", Table: ptr(42)},
+		},
+		{
+			"single next hop with weight",
+			StaticRouteSpec{Subnets: []string{"10.0.0.0/16"}, NextHops: []NextHop{{Gateway: "10.1.0.1", Weight: &weight}}},
+			v1.StaticRouteSpec{Subnet: "10.0.0.0/16", Gateways: []v1.NextHop{{Gateway: "10.1.0.1", Weight: &weight}}},
+		},
+		{
+			"table above main",
+			StaticRouteSpec{Subnets: []string{"10.0.0.0/16"}, Table: ptr(int64(1000))},
+			v1.StaticRouteSpec{Subnet: "10.0.0.0/16", Table: ptr(1000)},
+		},
+		{
+			"multiple subnets and next hops",
+			StaticRouteSpec{Subnets: []string{"10.0.0.0/16", "10.1.0.0/16"}, NextHops: []NextHop{{Gateway: "10.2.0.1"}, {Gateway: "10.2.0.2"}}},
+			v1.StaticRouteSpec{Subnets: []string{"10.0.0.0/16", "10.1.0.0/16"}, Gateways: []v1.NextHop{{Gateway: "10.2.0.1"}, {Gateway: "10.2.0.2"}}},
+		},
+		{
+			"node selector",
+			StaticRouteSpec{Subnets: []string{"10.0.0.0/16"}, NodeSelector: &metav1.LabelSelector{
+				MatchLabels:      map[string]string{"zone": "a", "pool": "b"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "edge", Operator: metav1.LabelSelectorOpExists}},
+			}},
+			v1.StaticRouteSpec{Subnet: "10.0.0.0/16", Selectors: []metav1.LabelSelectorRequirement{
+				{Key: "pool", Operator: metav1.LabelSelectorOpIn, Values: []string{"b"}},
+				{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"a"}},
+				{Key: "edge", Operator: metav1.LabelSelectorOpExists},
+			}},
+		},
+	}
+	for _, td := range testData {
+		t.Run(td.name, func(t *testing.T) {
+			src := &StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: "CR"}, Spec: td.in}
+			src.Status.NodeStatus = []StaticRouteNodeStatus{{Hostname: "hostname", State: td.in, Error: "failed"}}
+			dst := &v1.StaticRoute{}
+
+			if err := src.ConvertTo(dst); err != nil {
+				t.Fatal(err)
+			}
+
+			if dst.Name != "CR" {
+				t.Errorf("Metadata must be copied: %v", dst.ObjectMeta)
+			}
+			if !reflect.DeepEqual(dst.Spec, td.expected) {
+				t.Errorf("Spec mismatch: expected %+v, actual %+v", td.expected, dst.Spec)
+			}
+			if len(dst.Status.NodeStatus) != 1 || !reflect.DeepEqual(dst.Status.NodeStatus[0].State, td.expected) || dst.Status.NodeStatus[0].Error != "failed" {
+				t.Errorf("Status mismatch: %+v", dst.Status)
+			}
+		})
+	}
+}
+
+func TestConvertToV1TableOutOfRange(t *testing.T) {
+	for _, table := range []int64{-1, 1 << 32} {
+		src := &StaticRoute{Spec: StaticRouteSpec{Subnets: []string{"10.0.0.0/16"}}}
+		src.Status.NodeStatus = []StaticRouteNodeStatus{{Hostname: "hostname", State: StaticRouteSpec{Subnets: []string{"10.0.0.0/16"}, Table: &table}}}
+		for _, route := range []*StaticRoute{{Spec: src.Status.NodeStatus[0].State}, src} {
+			if err := route.ConvertTo(&v1.StaticRoute{}); err == nil {
+				t.Errorf("Table %d must not be converted", table)
+			}
+		}
+	}
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	metric := int64(100)
+	route := &v1.StaticRoute{Spec: v1.StaticRouteSpec{Subnets: []string{"10.0.0.0/16", "10.1.0.0/16"}, Interface: "eth1", Src: "10.2.0.2", MTU: 1400, Metric: &metric}}
+	route.Status.Conditions = []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue}}
+	route.Status.NodeStatus = []v1.StaticRouteNodeStatus{{Hostname: "hostname", ObservedGeneration: 3, ActiveGateway: "10.2.0.1", GatewayProbes: []v1.GatewayProbeStatus{{Gateway: "10.2.0.1", Healthy: true}}}}
+	v2 := &StaticRoute{}
+	out := &v1.StaticRoute{}
+
+	if err := v2.ConvertFrom(route); err != nil {
+		t.Fatal(err)
+	}
+	if err := v2.ConvertTo(out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(route, out) {
+		t.Errorf("Round trip must not change the route: expected %+v, actual %+v", route, out)
+	}
+}
+
+func TestConvertNodeSelectorRoundTrip(t *testing.T) {
+	var testData = []struct {
+		name     string
+		selector *metav1.LabelSelector
+	}{
+		{"no selector", nil},
+		{"match labels", &metav1.LabelSelector{MatchLabels: map[string]string{"zone": "a", "pool": "b"}}},
+		{"match labels and expressions", &metav1.LabelSelector{
+			MatchLabels:      map[string]string{"zone": "a"},
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "edge", Operator: metav1.LabelSelectorOpExists}},
+		}},
+		{"single value In expression", &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"a"}}},
+		}},
+	}
+	for _, td := range testData {
+		t.Run(td.name, func(t *testing.T) {
+			spec := StaticRouteSpec{Subnets: []string{"10.0.0.0/16"}, NodeSelector: td.selector}
+			route := &StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: "CR", Annotations: map[string]string{"owner": "team"}}, Spec: spec}
+			route.Status.NodeStatus = []StaticRouteNodeStatus{{Hostname: "hostname", State: *spec.DeepCopy()}}
+			hub := &v1.StaticRoute{}
+			out := &StaticRoute{}
+
+			if err := route.ConvertTo(hub); err != nil {
+				t.Fatal(err)
+			}
+			if err := out.ConvertFrom(hub); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(route, out) {
+				t.Errorf("Round trip must not change the route: expected %+v, actual %+v", route, out)
+			}
+		})
+	}
+}
+
+func TestConvertNodeSelectorChangedInV1(t *testing.T) {
+	route := &StaticRoute{Spec: StaticRouteSpec{Subnets: []string{"10.0.0.0/16"}, NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"zone": "a"}}}}
+	hub := &v1.StaticRoute{}
+	out := &StaticRoute{}
+	if err := route.ConvertTo(hub); err != nil {
+		t.Fatal(err)
+	}
+	hub.Spec.Selectors[0].Values = []string{"b"}
+
+	if err := out.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"b"}}}}
+	if !reflect.DeepEqual(out.Spec.NodeSelector, expected) || out.Annotations != nil {
+		t.Errorf("Selectors changed in v1 must be converted: %+v %v", out.Spec.NodeSelector, out.Annotations)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
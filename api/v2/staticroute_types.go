@@ -0,0 +1,269 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NextHop defines one of the gateways of the route
+type NextHop struct {
+	// Gateway the IP address of the next hop. Must be the same IP family as the subnets.
+	// +kubebuilder:validation:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7})$`
+	Gateway string `json:"gateway"`
+
+	// Weight of the next hop compared to the others (optional, default is 1)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=256
+	Weight *int `json:"weight,omitempty"`
+}
+
+// GatewayProbe defines how the health of the candidate gateways is checked on the nodes
+// +kubebuilder:validation:XValidation:rule="self.type != 'tcp' || has(self.port)",message="tcp probe needs a port"
+type GatewayProbe struct {
+	// Type of the probe: neighbor (ARP or neighbor discovery state), icmp (echo request) or tcp (connect)
+	// +kubebuilder:validation:Enum=neighbor;icmp;tcp
+	Type string `json:"type"`
+
+	// Port of the gateway the tcp probe connects to
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port,omitempty"`
+
+	// PeriodSeconds how often the gateways are probed (optional, default is 10)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=3600
+	PeriodSeconds int `json:"periodSeconds,omitempty"`
+
+	// TimeoutSeconds after the probe of a gateway fails (optional, default is 1)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+
+	// FailureThreshold the number of consecutive failed probes after a gateway is considered unhealthy (optional, default is 3)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	FailureThreshold int `json:"failureThreshold,omitempty"`
+
+	// SuccessThreshold the number of consecutive successful probes after an unhealthy gateway is considered healthy
+	// again (optional, default is 1)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	SuccessThreshold int `json:"successThreshold,omitempty"`
+}
+
+// StaticRouteSpec defines the desired state of StaticRoute
+type StaticRouteSpec struct {
+	// Subnets defines the routed subnets in the form of: "x.x.x.x/x" or "x:x::x/x". The subnets must be of the same
+	// IP family, every subnet is installed as a separate route and reported individually in the node status.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=256
+	// +kubebuilder:validation:items:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}(\/([0-9]|[1-2][0-9]|3[0-2]))?|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7}(\/([0-9]|[1-9][0-9]|1[0-1][0-9]|12[0-8]))?)$`
+	Subnets []string `json:"subnets"`
+
+	// NextHops the gateways the subnets are routed through (optional, discovered if not set). A single next hop
+	// without weight is a plain route, more next hops form a multipath route. The status reports the next hops
+	// which are actually installed on the node.
+	NextHops []NextHop `json:"nextHops,omitempty"`
+
+	// CandidateGateways the gateways in the order of preference (optional, mutually exclusive with nextHops).
+	// The route is installed through the first healthy one according to the probe, and it fails back when a
+	// preferred gateway recovers.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7})$`
+	CandidateGateways []string `json:"candidateGateways,omitempty"`
+
+	// Probe checks the health of the candidate gateways on each node, required with candidateGateways
+	Probe *GatewayProbe `json:"probe,omitempty"`
+
+	// Interface the name of the output interface on the nodes (optional). Without next hops the subnets are routed
+	// on-link through the interface, otherwise the next hop is reached through it.
+	// +kubebuilder:validation:MaxLength=15
+	Interface string `json:"interface,omitempty"`
+
+	// InterfaceAddressIn selects the output interface by its address (optional, mutually exclusive with interface):
+	// the interface holding an address inside this subnet is used on each node, i.e. "10.0.0.0/8".
+	// +kubebuilder:validation:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}(\/([0-9]|[1-2][0-9]|3[0-2]))?|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7}(\/([0-9]|[1-9][0-9]|1[0-1][0-9]|12[0-8]))?)$`
+	InterfaceAddressIn string `json:"interfaceAddressIn,omitempty"`
+
+	// Src the preferred source address of the packets sent to the subnets (optional). Must be an address of the node
+	// with the same IP family as the subnets.
+	// +kubebuilder:validation:Pattern=`^(([0-9]{1,3}\.){3}[0-9]{1,3}|[0-9a-fA-F]{0,4}(:[0-9a-fA-F]{0,4}){2,7})$`
+	Src string `json:"src,omitempty"`
+
+	// SrcInterface selects the preferred source address by interface name (optional, mutually exclusive with src):
+	// the first global address of the interface with the same IP family as the subnets is used on each node.
+	// +kubebuilder:validation:MaxLength=15
+	SrcInterface string `json:"srcInterface,omitempty"`
+
+	// Scope of the route (optional, default is link for device routes, universe otherwise).
+	// IPv6 routes support only the universe scope.
+	// +kubebuilder:validation:Enum=universe;site;link;host
+	Scope string `json:"scope,omitempty"`
+
+	// OnLink pretends that the next hop is directly attached to the interface, even if it does not match any
+	// subnet of the interface (optional). Needs a single next hop and interface or interfaceAddressIn.
+	OnLink bool `json:"onLink,omitempty"`
+
+	// MTU the path MTU of the subnets (optional, default is the MTU of the interface)
+	// +kubebuilder:validation:Minimum=68
+	// +kubebuilder:validation:Maximum=65535
+	MTU int `json:"mtu,omitempty"`
+
+	// AdvMSS the maximal TCP segment size advertised to the subnets (optional)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	AdvMSS int `json:"advMSS,omitempty"`
+
+	// InitCwnd the initial TCP congestion window in packets (optional)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	InitCwnd int `json:"initCwnd,omitempty"`
+
+	// InitRwnd the initial TCP receive window advertised in packets (optional)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	InitRwnd int `json:"initRwnd,omitempty"`
+
+	// HopLimit the TTL (IPv4) or hop limit (IPv6) of the packets sent to the subnets (optional)
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=255
+	HopLimit int `json:"hopLimit,omitempty"`
+
+	// Table the route will be installed in (optional, uses default table if not set). Any table of the kernel
+	// can be used, not only the ones up to main (254).
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	Table *int64 `json:"table,omitempty"`
+
+	// Metric the priority of the route, lower value is preferred (optional, default is 0).
+	// Routes for the same subnet with different metrics can coexist, i.e. as primary and backup.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=4294967295
+	Metric *int64 `json:"metric,omitempty"`
+
+	// NodeSelector selects the target nodes by their labels (optional, default is apply to all)
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// Type of the route (optional, default is unicast). Routes of the other types have no next hop, they are
+	// used to drop or reject the traffic of the subnets, or to continue the lookup with the next rule (throw).
+	// +kubebuilder:validation:Enum=unicast;blackhole;unreachable;prohibit;throw
+	Type string `json:"type,omitempty"`
+}
+
+// SubnetStatus defines the observed state of one subnet of a StaticRoute
+type SubnetStatus struct {
+	Subnet string `json:"subnet"`
+	Error  string `json:"error,omitempty"`
+}
+
+// GatewayProbeStatus defines the observed health of a candidate gateway on a node
+type GatewayProbeStatus struct {
+	Gateway string `json:"gateway"`
+	Healthy bool   `json:"healthy"`
+	// Since is the last time when the health of the gateway changed
+	Since *metav1.Time `json:"since,omitempty"`
+	// Message is the error of the probe which found the gateway unhealthy
+	Message string `json:"message,omitempty"`
+}
+
+// StaticRouteNodeStatus defines the observed state of one node, related to the StaticRoute
+type StaticRouteNodeStatus struct {
+	Hostname string          `json:"hostname"`
+	State    StaticRouteSpec `json:"state"`
+	Error    string          `json:"error"`
+	// ObservedGeneration is the generation of the spec the node reported the state for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Subnets reports the subnets one by one if the StaticRoute has multiple subnets
+	Subnets []SubnetStatus `json:"subnets,omitempty"`
+
+	// ActiveGateway is the candidate gateway the route is installed through
+	ActiveGateway string `json:"activeGateway,omitempty"`
+	// GatewayProbes reports the health of the candidate gateways
+	GatewayProbes []GatewayProbeStatus `json:"gatewayProbes,omitempty"`
+
+	// TamperedAt is the last time when the route was deleted by an external entity and had to be re-created
+	TamperedAt *metav1.Time `json:"tamperedAt,omitempty"`
+	// TamperCount counts how many times the route was deleted by an external entity
+	TamperCount int `json:"tamperCount,omitempty"`
+}
+
+// StaticRouteStatus defines the observed state of StaticRoute
+type StaticRouteStatus struct {
+	// ObservedGeneration is the generation of the spec every reporting node reconciled, the summary below counts
+	// only the nodes at the current generation
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// DesiredNodes is the number of nodes selected by the node selector
+	DesiredNodes int `json:"desiredNodes,omitempty"`
+	// AppliedNodes is the number of nodes reporting the route without error
+	AppliedNodes int `json:"appliedNodes,omitempty"`
+	// FailedNodes is the number of nodes reporting an error
+	FailedNodes int `json:"failedNodes,omitempty"`
+
+	// Conditions summarize the node statuses (Ready, Progressing, Degraded)
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	NodeStatus []StaticRouteNodeStatus `json:"nodeStatus"`
+}
+
+// +kubebuilder:object:root=true
+
+// StaticRoute is the Schema for the staticroutes API
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=staticroutes,scope=Cluster
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`,priority=0
+// +kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.status.desiredNodes`,priority=0
+// +kubebuilder:printcolumn:name="Applied",type=integer,JSONPath=`.status.appliedNodes`,priority=0
+// +kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.failedNodes`,priority=0
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`,priority=0
+// +kubebuilder:printcolumn:name="Networks",type=string,JSONPath=`.spec.subnets`,priority=1
+// +kubebuilder:printcolumn:name="Gateways",type=string,JSONPath=`.spec.nextHops[*].gateway`,description="empty field means default gateway",priority=1
+// +kubebuilder:printcolumn:name="Table",type=integer,JSONPath=`.spec.table`,description="empty field means default table",priority=1
+// +kubebuilder:printcolumn:name="Metric",type=integer,JSONPath=`.spec.metric`,description="empty field means metric 0",priority=1
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`,description="empty field means unicast",priority=1
+type StaticRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type == 'unicast' || (!has(self.nextHops) && !has(self.interface) && !has(self.interfaceAddressIn))",message="only unicast routes can have next hops or an interface"
+	// +kubebuilder:validation:XValidation:rule="!has(self.interface) || !has(self.interfaceAddressIn)",message="interface and interfaceAddressIn are mutually exclusive"
+	// +kubebuilder:validation:XValidation:rule="!has(self.nextHops) || size(self.nextHops) == 1 || (!has(self.interface) && !has(self.interfaceAddressIn))",message="multiple next hops can not be combined with an interface"
+	// +kubebuilder:validation:XValidation:rule="!has(self.src) || !has(self.srcInterface)",message="src and srcInterface are mutually exclusive"
+	// +kubebuilder:validation:XValidation:rule="!has(self.onLink) || !self.onLink || (has(self.nextHops) && size(self.nextHops) == 1 && (has(self.interface) || has(self.interfaceAddressIn)))",message="onLink needs a single next hop and an interface"
+	// +kubebuilder:validation:XValidation:rule="has(self.candidateGateways) == has(self.probe)",message="candidateGateways and probe must be set together"
+	// +kubebuilder:validation:XValidation:rule="!has(self.candidateGateways) || !has(self.nextHops)",message="candidateGateways and nextHops are mutually exclusive"
+	// +kubebuilder:validation:XValidation:rule="!has(self.type) || self.type == 'unicast' || !has(self.candidateGateways)",message="only unicast routes can have candidate gateways"
+	Spec   StaticRouteSpec   `json:"spec,omitempty"`
+	Status StaticRouteStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// StaticRouteList contains a list of StaticRoute
+type StaticRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StaticRoute `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&StaticRoute{}, &StaticRouteList{})
+}
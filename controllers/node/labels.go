@@ -0,0 +1,79 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package node
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	//HostNameLabel label to determine hostname
+	HostNameLabel = "kubernetes.io/hostname"
+)
+
+// HostNameRequirement returns the selector requirement which matches only the node with the hostname
+func HostNameRequirement(hostname string) metav1.LabelSelectorRequirement {
+	return metav1.LabelSelectorRequirement{
+		Key:      HostNameLabel,
+		Operator: metav1.LabelSelectorOpIn,
+		Values:   []string{hostname},
+	}
+}
+
+// WatchLabels adds a controller to the manager, which passes the requests returned by the map function to the
+// reconciler whenever the labels of the node with the hostname are changed. The controllers with node selectors
+// use it to re-evaluate them on their own node.
+func WatchLabels(mgr ctrl.Manager, name, hostname string, mapFunc handler.MapFunc, r reconcile.Reconciler) error {
+	return ctrl.NewControllerManagedBy(mgr).Named(name).
+		For(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: hostname}}).
+		Watches(
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: hostname}},
+			handler.EnqueueRequestsFromMapFunc(mapFunc)).
+		WithEventFilter(labelsChanged(name)).
+		Complete(r)
+}
+
+// labelsChanged passes only the node updates which change the labels
+func labelsChanged(name string) predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if len(e.ObjectNew.GetLabels()) != len(e.ObjectOld.GetLabels()) {
+				log.Info("Node label amount changed. Submitting all CRs for reconciliation.", "Controller", name)
+				return true
+			}
+			for k, v := range e.ObjectOld.GetLabels() {
+				if e.ObjectNew.GetLabels()[k] != v {
+					log.Info("Node labels are changed. Submitting all CRs for reconciliation.", "Controller", name)
+					return true
+				}
+			}
+			return false
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return false
+		},
+	}
+}
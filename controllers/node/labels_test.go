@@ -0,0 +1,63 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package node
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestLabelsChanged(t *testing.T) {
+	var testData = []struct {
+		name     string
+		old      map[string]string
+		new      map[string]string
+		expected bool
+	}{
+		{"same labels", map[string]string{"key": "value"}, map[string]string{"key": "value"}, false},
+		{"label added", map[string]string{"key": "value"}, map[string]string{"key": "value", "other": "value"}, true},
+		{"label removed", map[string]string{"key": "value"}, nil, true},
+		{"value changed", map[string]string{"key": "value"}, map[string]string{"key": "other"}, true},
+		{"key changed", map[string]string{"key": "value"}, map[string]string{"other": "value"}, true},
+	}
+	filter := labelsChanged("controller")
+	for _, td := range testData {
+		t.Run(td.name, func(t *testing.T) {
+			e := event.UpdateEvent{
+				ObjectOld: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "hostname", Labels: td.old}},
+				ObjectNew: &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "hostname", Labels: td.new}},
+			}
+			if filter.Update(e) != td.expected {
+				t.Errorf("Update must return %t", td.expected)
+			}
+		})
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "hostname"}}
+	if filter.Create(event.CreateEvent{Object: node}) || filter.Delete(event.DeleteEvent{Object: node}) {
+		t.Error("Only the updates must pass")
+	}
+}
+
+func TestHostNameRequirement(t *testing.T) {
+	requirement := HostNameRequirement("hostname")
+	if requirement.Key != HostNameLabel || requirement.Operator != metav1.LabelSelectorOpIn || len(requirement.Values) != 1 || requirement.Values[0] != "hostname" {
+		t.Errorf("Requirement must select the hostname: %v", requirement)
+	}
+}
@@ -87,9 +87,10 @@ func newReconcileImplParams(client reconcileImplClient) *reconcileImplParams {
 	}
 }
 
-func newFakeClient(routes *staticroutev1.StaticRouteList) client.Client {
+func newFakeClient(routes *staticroutev1.StaticRouteList, objs ...client.Object) client.Client {
 	s := runtime.NewScheme()
-	s.AddKnownTypes(staticroutev1.GroupVersion, routes)
+	s.AddKnownTypes(staticroutev1.GroupVersion, routes, &staticroutev1.StaticRouteRule{}, &staticroutev1.StaticRouteRuleList{})
 	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Node{})
-	return fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects([]runtime.Object{routes}...).Build()
+	return fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects([]runtime.Object{routes}...).
+		WithStatusSubresource(&staticroutev1.StaticRouteRule{}).WithObjects(objs...).Build()
 }
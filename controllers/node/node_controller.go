@@ -22,6 +22,7 @@ import (
 	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -106,9 +107,11 @@ var (
 	nodeStillExists = &reconcile.Result{}
 	finished        = &reconcile.Result{}
 
-	nodeGetError         = &reconcile.Result{}
-	staticRouteListError = &reconcile.Result{}
-	deleteRouteError     = &reconcile.Result{}
+	nodeGetError             = &reconcile.Result{}
+	staticRouteListError     = &reconcile.Result{}
+	deleteRouteError         = &reconcile.Result{}
+	staticRouteRuleListError = &reconcile.Result{}
+	deleteRuleError          = &reconcile.Result{}
 )
 
 func reconcileImpl(params reconcileImplParams) (*reconcile.Result, error) {
@@ -141,9 +144,39 @@ func reconcileImpl(params reconcileImplParams) (*reconcile.Result, error) {
 		return deleteRouteError, err
 	}
 
+	rules := &staticroutev1.StaticRouteRuleList{}
+	if err := params.client.List(context.Background(), rules); meta.IsNoMatchError(err) {
+		// StaticRouteRule CRD is optional
+		return finished, nil
+	} else if err != nil {
+		reqLogger.Error(err, "Unable to fetch StaticRouteRule CRD")
+		return staticRouteRuleListError, err
+	}
+	for i := range rules.Items {
+		if !removeRuleStatus(&rules.Items[i], params.request.Name) {
+			continue
+		}
+		reqLogger.Info("Found the node to delete in StaticRouteRule", "StaticRouteRule", rules.Items[i].Name)
+		if err := params.client.Status().Update(context.Background(), &rules.Items[i]); err != nil {
+			reqLogger.Error(err, "Unable to update StaticRouteRule CR")
+			return deleteRuleError, err
+		}
+	}
+
 	return finished, nil
 }
 
+// removeRuleStatus removes the status of the node from the StaticRouteRule, returns false if it was not there
+func removeRuleStatus(rule *staticroutev1.StaticRouteRule, nodeName string) bool {
+	for i, status := range rule.Status.NodeStatus {
+		if status.Hostname == nodeName {
+			rule.Status.NodeStatus = append(rule.Status.NodeStatus[:i], rule.Status.NodeStatus[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 type nodeFinder struct {
 	nodeName       string
 	updateCallback func(*staticroutev1.StaticRoute) error
@@ -23,8 +23,11 @@ import (
 
 	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -220,6 +223,70 @@ func TestReconcileDeleteError(t *testing.T) {
 	}
 }
 
+func TestReconcileImplRemovesRuleStatus(t *testing.T) {
+	rule := &staticroutev1.StaticRouteRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "rule"},
+		Status:     staticroutev1.StaticRouteRuleStatus{NodeStatus: []staticroutev1.StaticRouteRuleNodeStatus{{Hostname: "foo"}, {Hostname: "CR"}}},
+	}
+	fakeClient := newFakeClient(&staticroutev1.StaticRouteList{}, rule)
+	params, mockClient := getReconcileContextForHappyFlow(nil)
+	mockClient.client = fakeClient
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	updated := &staticroutev1.StaticRouteRule{}
+	if err := fakeClient.Get(context.Background(), types.NamespacedName{Name: "rule"}, updated); err != nil {
+		t.Fatalf("Failed to read the rule: %s", err.Error())
+	}
+	if len(updated.Status.NodeStatus) != 1 || updated.Status.NodeStatus[0].Hostname != "foo" {
+		t.Errorf("Status of the deleted node must be removed: %v", updated.Status.NodeStatus)
+	}
+}
+
+func TestReconcileImplRuleCRDMissing(t *testing.T) {
+	params, mockClient := getReconcileContextForHappyFlow(nil)
+	mockClient.list = func(ctx context.Context, obj runtime.Object, options ...client.ListOption) error {
+		if _, ok := obj.(*staticroutev1.StaticRouteRuleList); ok {
+			return &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "static-route.ibm.com", Kind: "StaticRouteRule"}}
+		}
+		return nil
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplRuleListError(t *testing.T) {
+	params, mockClient := getReconcileContextForHappyFlow(nil)
+	mockClient.list = func(ctx context.Context, obj runtime.Object, options ...client.ListOption) error {
+		if _, ok := obj.(*staticroutev1.StaticRouteRuleList); ok {
+			return errors.New("list failed")
+		}
+		return nil
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != staticRouteRuleListError {
+		t.Error("Result must be staticRouteRuleListError")
+	}
+	if err == nil {
+		t.Error("Error must be not nil")
+	}
+}
+
 func getReconcileContextForHappyFlow(statusUpdateCallback func() client.StatusWriter) (*reconcileImplParams, *reconcileImplClientMock) {
 	routes := &staticroutev1.StaticRouteList{}
 	mockClient := reconcileImplClientMock{
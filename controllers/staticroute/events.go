@@ -0,0 +1,98 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"sync"
+	"time"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// Reasons of the events emitted by the static route controller
+const (
+	reasonRouteInstalled             = "RouteInstalled"
+	reasonRouteUpdated               = "RouteUpdated"
+	reasonRouteRemoved               = "RouteRemoved"
+	reasonGatewayNotDirectlyRoutable = "GatewayNotDirectlyRoutable"
+	reasonInterfaceNotFound          = "InterfaceNotFound"
+	reasonSubnetOverlapsProtected    = "SubnetOverlapsProtected"
+	reasonSubnetNotAllowed           = "SubnetNotAllowed"
+	reasonRouteTampered              = "RouteTampered"
+	reasonRouteDrifted               = "RouteDrifted"
+	reasonNoHealthyGateway           = "NoHealthyGateway"
+	reasonGatewaySwitched            = "GatewaySwitched"
+)
+
+const (
+	// eventRepeatInterval is the minimum time between two identical events of the same StaticRoute
+	eventRepeatInterval = 5 * time.Minute
+	// eventQPS and eventBurst limit the events of a single node, so large clusters do not flood the API server
+	eventQPS   = 0.2
+	eventBurst = 10
+)
+
+// routeEventRecorder emits the events of a StaticRoute both on the StaticRoute and on the Node.
+// A nil routeEventRecorder drops the events.
+type routeEventRecorder struct {
+	recorder record.EventRecorder
+	node     *corev1.ObjectReference
+	limiter  flowcontrol.RateLimiter
+	now      func() time.Time
+	mutex    sync.Mutex
+	sent     map[string]time.Time
+}
+
+func newRouteEventRecorder(recorder record.EventRecorder, hostname string) *routeEventRecorder {
+	return &routeEventRecorder{
+		recorder: recorder,
+		// Same reference as the kubelet uses for the node events
+		node:    &corev1.ObjectReference{Kind: "Node", Name: hostname, UID: k8stypes.UID(hostname)},
+		limiter: flowcontrol.NewTokenBucketRateLimiter(eventQPS, eventBurst),
+		now:     time.Now,
+		sent:    make(map[string]time.Time),
+	}
+}
+
+func (r *routeEventRecorder) event(route *staticroutev1.StaticRoute, eventType, reason, message string) {
+	if r == nil || !r.allowed(string(route.UID)+"/"+route.Name+"/"+reason+"/"+message) {
+		return
+	}
+	r.recorder.Event(route, eventType, reason, message)
+	r.recorder.Eventf(r.node, eventType, reason, "StaticRoute %s: %s", route.Name, message)
+}
+
+// allowed suppresses the repetition of the same event and limits the rate of the node's events
+func (r *routeEventRecorder) allowed(key string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := r.now()
+	for k, sent := range r.sent {
+		if now.Sub(sent) >= eventRepeatInterval {
+			delete(r.sent, k)
+		}
+	}
+	if _, found := r.sent[key]; found || !r.limiter.TryAccept() {
+		return false
+	}
+	r.sent[key] = now
+	return true
+}
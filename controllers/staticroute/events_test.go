@@ -0,0 +1,193 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IBM/staticroute-operator/pkg/cidr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+type recordedEvent struct {
+	object  runtime.Object
+	message string
+}
+
+// objectRecorder records the involved objects next to the events
+type objectRecorder struct {
+	record.EventRecorder
+	events []recordedEvent
+}
+
+func (r *objectRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.events = append(r.events, recordedEvent{object, eventtype + " " + reason + " " + message})
+}
+
+func (r *objectRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.events = append(r.events, recordedEvent{object, eventtype + " " + reason + " " + messageFmt})
+}
+
+func newTestEventRecorder() (*routeEventRecorder, *objectRecorder) {
+	recorder := &objectRecorder{}
+	return newRouteEventRecorder(recorder, "hostname"), recorder
+}
+
+func TestRouteEventRecorderStaticRouteAndNode(t *testing.T) {
+	events, recorder := newTestEventRecorder()
+	route := newStaticRouteWithValues(true, false)
+
+	events.event(route, corev1.EventTypeNormal, reasonRouteInstalled, "installed")
+
+	if len(recorder.events) != 2 {
+		t.Fatalf("Event must be emitted on the StaticRoute and the Node: %v", recorder.events)
+	}
+	if recorder.events[0].object != route {
+		t.Errorf("First event must be emitted on the StaticRoute: %v", recorder.events[0].object)
+	}
+	if node, ok := recorder.events[1].object.(*corev1.ObjectReference); !ok || node.Kind != "Node" || node.Name != "hostname" {
+		t.Errorf("Second event must be emitted on the Node: %v", recorder.events[1].object)
+	}
+}
+
+func TestRouteEventRecorderSuppressesRepetition(t *testing.T) {
+	events, recorder := newTestEventRecorder()
+	now := time.Now()
+	events.now = func() time.Time { return now }
+	route := newStaticRouteWithValues(true, false)
+
+	events.event(route, corev1.EventTypeWarning, reasonSubnetOverlapsProtected, "overlaps")
+	events.event(route, corev1.EventTypeWarning, reasonSubnetOverlapsProtected, "overlaps")
+	events.event(route, corev1.EventTypeNormal, reasonRouteInstalled, "installed")
+	if len(recorder.events) != 4 {
+		t.Errorf("Identical event must be suppressed: %v", recorder.events)
+	}
+
+	now = now.Add(eventRepeatInterval)
+	events.event(route, corev1.EventTypeWarning, reasonSubnetOverlapsProtected, "overlaps")
+	if len(recorder.events) != 6 {
+		t.Errorf("Identical event must be emitted again after the interval: %v", recorder.events)
+	}
+}
+
+func TestRouteEventRecorderRateLimit(t *testing.T) {
+	events, recorder := newTestEventRecorder()
+	events.limiter = flowcontrol.NewFakeNeverRateLimiter()
+
+	events.event(newStaticRouteWithValues(true, false), corev1.EventTypeNormal, reasonRouteInstalled, "installed")
+
+	if len(recorder.events) != 0 {
+		t.Errorf("Events must be dropped above the rate limit: %v", recorder.events)
+	}
+}
+
+func TestRouteEventRecorderNil(t *testing.T) {
+	var events *routeEventRecorder
+
+	events.event(newStaticRouteWithValues(true, false), corev1.EventTypeNormal, reasonRouteInstalled, "installed")
+}
+
+func TestReconcileImplEmitsRouteInstalled(t *testing.T) {
+	events, recorder := newTestEventRecorder()
+	params, _ := getReconcileContextForAddFlow(newStaticRouteWithValues(true, false), false, false)
+	params.events = events
+
+	//nolint:errcheck
+	reconcileImpl(*params)
+
+	if len(recorder.events) != 2 || !strings.HasPrefix(recorder.events[0].message, "Normal RouteInstalled") {
+		t.Errorf("RouteInstalled must be emitted: %v", recorder.events)
+	}
+}
+
+func TestReconcileImplEmitsRouteRemoved(t *testing.T) {
+	events, recorder := newTestEventRecorder()
+	params, _ := getReconcileContextForAddFlow(nil, true, true)
+	params.events = events
+
+	//nolint:errcheck
+	reconcileImpl(*params)
+
+	if len(recorder.events) != 2 || !strings.HasPrefix(recorder.events[0].message, "Normal RouteRemoved") {
+		t.Errorf("RouteRemoved must be emitted: %v", recorder.events)
+	}
+}
+
+func TestReconcileImplEmitsSubnetOverlapsProtected(t *testing.T) {
+	events, recorder := newTestEventRecorder()
+	params, _ := getReconcileContextForAddFlow(nil, false, false)
+	params.events = events
+	params.options.ProtectedSubnets = cidr.NewSet(&net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.IPv4Mask(0xff, 0, 0, 0)})
+
+	//nolint:errcheck
+	reconcileImpl(*params)
+
+	if len(recorder.events) != 2 || !strings.HasPrefix(recorder.events[0].message, "Warning SubnetOverlapsProtected") {
+		t.Errorf("SubnetOverlapsProtected must be emitted: %v", recorder.events)
+	}
+}
+
+func TestReconcileImplEmitsGatewayNotDirectlyRoutable(t *testing.T) {
+	events, recorder := newTestEventRecorder()
+	params, _ := getReconcileContextForAddFlow(nil, false, false)
+	params.events = events
+	params.options.GetGw = func(net.IP) (net.IP, error) {
+		return net.IP{10, 0, 0, 254}, nil
+	}
+
+	//nolint:errcheck
+	reconcileImpl(*params)
+
+	if len(recorder.events) != 2 || !strings.HasPrefix(recorder.events[0].message, "Warning GatewayNotDirectlyRoutable") {
+		t.Errorf("GatewayNotDirectlyRoutable must be emitted: %v", recorder.events)
+	}
+}
+
+func TestReconcileImplEmitsRouteTampered(t *testing.T) {
+	events, recorder := newTestEventRecorder()
+	params, _ := getReconcileContextForAddFlow(nil, true, false)
+	params.events = events
+	params.tampered = &metav1.Time{Time: time.Now()}
+
+	//nolint:errcheck
+	reconcileImpl(*params)
+
+	if len(recorder.events) != 2 || !strings.HasPrefix(recorder.events[0].message, "Warning RouteTampered") {
+		t.Errorf("RouteTampered must be emitted: %v", recorder.events)
+	}
+}
+
+func TestReconcileImplEmitsRouteDrifted(t *testing.T) {
+	events, recorder := newTestEventRecorder()
+	params, _ := getReconcileContextForAddFlow(nil, true, false)
+	params.events = events
+	params.drifted = "Route to 10.0.0.0/16 was modified in table 254, repaired"
+
+	//nolint:errcheck
+	reconcileImpl(*params)
+
+	if len(recorder.events) != 2 || recorder.events[0].message != "Warning RouteDrifted Route to 10.0.0.0/16 was modified in table 254, repaired on node hostname" {
+		t.Errorf("RouteDrifted must be emitted: %v", recorder.events)
+	}
+}
@@ -0,0 +1,105 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"context"
+	"net"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/pkg/routemanager"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// garbageCollector runs once at startup. It re-adopts the routes left in the kernel by a previous run of the operator
+// which still belong to a StaticRoute, and removes the ones whose StaticRoute was deleted meanwhile.
+type garbageCollector struct {
+	reader  client.Reader
+	options ManagerOptions
+}
+
+// blank assignment to verify that garbageCollector implements manager.Runnable
+var _ manager.Runnable = &garbageCollector{}
+
+// NeedLeaderElection is false, as every node has to clean up its own routes
+func (gc *garbageCollector) NeedLeaderElection() bool {
+	return false
+}
+
+func (gc *garbageCollector) Start(ctx context.Context) error {
+	routes := &staticroutev1.StaticRouteList{}
+	if err := gc.reader.List(ctx, routes); err != nil {
+		log.Error(err, "Unable to fetch StaticRoutes for garbage collection")
+		return nil
+	}
+	if err := gc.options.RouteManager.CollectGarbage(gc.adopter(routes)); err != nil {
+		log.Error(err, "Unable to remove orphaned routes")
+	}
+	return nil
+}
+
+// adopter returns the name of the StaticRoute which reports the given route as installed on this node
+func (gc *garbageCollector) adopter(routes *staticroutev1.StaticRouteList) func(routemanager.Route) (string, bool) {
+	return func(route routemanager.Route) (string, bool) {
+		for i := range routes.Items {
+			for _, status := range routes.Items[i].Status.NodeStatus {
+				if status.Hostname != gc.options.Hostname {
+					continue
+				}
+				if name, found := gc.adopterOf(routes.Items[i].Name, status, route); found {
+					log.Info("Adopting route", "Request.Name", routes.Items[i].Name, "Subnet", route.Dst.String())
+					return name, true
+				}
+			}
+		}
+		log.Info("Removing orphaned route", "Subnet", route.Dst.String(), "Table", route.Table)
+		return "", false
+	}
+}
+
+// adopterOf returns the name the route is registered under if the node status reports it as installed.
+// The subnets of a StaticRoute with multiple subnets are reported one by one.
+func (gc *garbageCollector) adopterOf(name string, status staticroutev1.StaticRouteNodeStatus, route routemanager.Route) (string, bool) {
+	if len(status.State.Subnets) == 0 {
+		return name, status.Error == "" && gc.matches(status.State, status.State.Subnet, route)
+	}
+	for _, subnet := range status.Subnets {
+		if subnet.Error == "" && gc.matches(status.State, subnet.Subnet, route) {
+			return routeKey(name, subnet.Subnet), true
+		}
+	}
+	return "", false
+}
+
+func (gc *garbageCollector) matches(state staticroutev1.StaticRouteSpec, stateSubnet string, route routemanager.Route) bool {
+	_, subnet, err := net.ParseCIDR(stateSubnet)
+	if err != nil || subnet.String() != route.Dst.String() {
+		return false
+	}
+	table := gc.options.Table
+	if state.Table != nil {
+		table = *state.Table
+	}
+	metric := 0
+	if state.Metric != nil {
+		metric = int(*state.Metric)
+	}
+	// The kernel reports the IPv6 routes installed without a metric with its default priority
+	metric = routemanager.Route{Dst: *subnet, Priority: metric}.InKernel().Priority
+	return table == route.Table && metric == route.Priority && routeType(state.Type) == route.Type
+}
@@ -0,0 +1,168 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/pkg/routemanager"
+	"golang.org/x/sys/unix"
+)
+
+func TestGarbageCollectorAdopter(t *testing.T) {
+	table := 42
+	metric := int64(100)
+	route := newStaticRouteWithValues(true, true)
+	route.Status.NodeStatus = append(route.Status.NodeStatus, staticroutev1.StaticRouteNodeStatus{
+		Hostname: "hostname",
+		State:    staticroutev1.StaticRouteSpec{Subnet: "192.168.0.0/24", Table: &table, Metric: &metric},
+	}, staticroutev1.StaticRouteNodeStatus{
+		Hostname: "hostname",
+		State:    staticroutev1.StaticRouteSpec{Subnet: "192.168.1.0/24"},
+		Error:    "failed",
+	}, staticroutev1.StaticRouteNodeStatus{
+		Hostname: "hostname",
+		State:    staticroutev1.StaticRouteSpec{Subnet: "192.168.3.0/24", Type: staticroutev1.RouteTypeBlackhole},
+	}, staticroutev1.StaticRouteNodeStatus{
+		Hostname: "hostname",
+		State:    staticroutev1.StaticRouteSpec{Subnet: "fd00:1::/64"},
+	}, staticroutev1.StaticRouteNodeStatus{
+		Hostname: "hostname",
+		State:    staticroutev1.StaticRouteSpec{Subnet: "fd00:2::/64", Metric: &metric},
+	}, staticroutev1.StaticRouteNodeStatus{
+		Hostname: "other",
+		State:    staticroutev1.StaticRouteSpec{Subnet: "192.168.2.0/24"},
+	})
+	gc := garbageCollector{options: ManagerOptions{Hostname: "hostname", Table: 254}}
+	adopt := gc.adopter(&staticroutev1.StaticRouteList{Items: []staticroutev1.StaticRoute{*route}})
+
+	var testData = []struct {
+		subnet   string
+		table    int
+		priority int
+		kind     int
+		adopted  bool
+	}{
+		{"10.0.0.0/16", 254, 0, 0, true},
+		{"10.0.0.0/16", 42, 0, 0, false},
+		{"10.0.0.0/16", 254, 0, unix.RTN_PROHIBIT, false},
+		{"10.0.0.0/24", 254, 0, 0, false},
+		{"192.168.0.0/24", 42, 100, 0, true},
+		{"192.168.0.0/24", 42, 0, 0, false},
+		{"192.168.1.0/24", 254, 0, 0, false},
+		{"192.168.2.0/24", 254, 0, 0, false},
+		{"192.168.3.0/24", 254, 0, unix.RTN_BLACKHOLE, true},
+		{"192.168.3.0/24", 254, 0, 0, false},
+		{"fd00:1::/64", 254, 1024, 0, true},
+		{"fd00:1::/64", 254, 100, 0, false},
+		{"fd00:2::/64", 254, 100, 0, true},
+		{"fd00:2::/64", 254, 1024, 0, false},
+	}
+	for i, td := range testData {
+		_, dst, _ := net.ParseCIDR(td.subnet)
+
+		name, adopted := adopt(routemanager.Route{Dst: *dst, Table: td.table, Priority: td.priority, Type: td.kind})
+
+		if adopted != td.adopted || (adopted && name != "CR") {
+			t.Errorf("Result must be %t, it is %t (%s) at %d", td.adopted, adopted, name, i)
+		}
+	}
+}
+
+func TestGarbageCollectorAdopterSubnets(t *testing.T) {
+	route := newStaticRouteWithValues(false, false)
+	route.Status.NodeStatus = []staticroutev1.StaticRouteNodeStatus{{
+		Hostname: "hostname",
+		State:    staticroutev1.StaticRouteSpec{Subnets: []string{"10.1.0.0/16", "10.2.0.0/16"}},
+		Error:    "1 of 2 subnets failed",
+		Subnets:  []staticroutev1.SubnetStatus{{Subnet: "10.1.0.0/16"}, {Subnet: "10.2.0.0/16", Error: "failed"}},
+	}}
+	gc := garbageCollector{options: ManagerOptions{Hostname: "hostname", Table: 254}}
+	adopt := gc.adopter(&staticroutev1.StaticRouteList{Items: []staticroutev1.StaticRoute{*route}})
+
+	var testData = []struct {
+		subnet  string
+		name    string
+		adopted bool
+	}{
+		{"10.1.0.0/16", "CR/10.1.0.0/16", true},
+		{"10.2.0.0/16", "", false},
+		{"10.3.0.0/16", "", false},
+	}
+	for i, td := range testData {
+		_, dst, _ := net.ParseCIDR(td.subnet)
+
+		name, adopted := adopt(routemanager.Route{Dst: *dst, Table: 254})
+
+		if adopted != td.adopted || name != td.name {
+			t.Errorf("Result must be %t (%s), it is %t (%s) at %d", td.adopted, td.name, adopted, name, i)
+		}
+	}
+}
+
+func TestGarbageCollectorStart(t *testing.T) {
+	route := newStaticRouteWithValues(true, true)
+	called := false
+	gc := garbageCollector{
+		reader: newFakeClient(route),
+		options: ManagerOptions{
+			Hostname: "hostname",
+			RouteManager: routeManagerMock{
+				collectGarbageCallback: func(adopt func(routemanager.Route) (string, bool)) error {
+					called = true
+					_, dst, _ := net.ParseCIDR("10.0.0.0/16")
+					if name, ok := adopt(routemanager.Route{Dst: *dst}); !ok || name != "CR" {
+						t.Error("Route of the existing CR must be adopted")
+					}
+					return errors.New("failed")
+				},
+			},
+		},
+	}
+
+	if err := gc.Start(context.Background()); err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if !called {
+		t.Error("CollectGarbage must be called")
+	}
+	if gc.NeedLeaderElection() {
+		t.Error("Garbage collection must run on every node")
+	}
+}
+
+func TestGarbageCollectorStartListFails(t *testing.T) {
+	gc := garbageCollector{
+		reader: reconcileImplClientMock{listErr: errors.New("failed")},
+		options: ManagerOptions{
+			RouteManager: routeManagerMock{
+				collectGarbageCallback: func(func(routemanager.Route) (string, bool)) error {
+					t.Error("CollectGarbage must not be called without the StaticRoutes")
+					return nil
+				},
+			},
+		},
+	}
+
+	if err := gc.Start(context.Background()); err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
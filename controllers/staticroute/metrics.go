@@ -0,0 +1,78 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var reconcileResults = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "staticroute",
+	Name:      "reconcile_results_total",
+	Help:      "Number of StaticRoute reconciliations by outcome",
+}, []string{"result"})
+
+// resultNames maps the reconcile.Result sentinels to their metric label
+var resultNames = map[*reconcile.Result]string{
+	crNotFound:        "crNotFound",
+	nodeNotFound:      "nodeNotFound",
+	overlapsProtected: "overlapsProtected",
+	notAllowed:        "notAllowed",
+	alreadyDeleted:    "alreadyDeleted",
+	deletionFinished:  "deletionFinished",
+	updateFinished:    "updateFinished",
+	routeUpdated:      "routeUpdated",
+	finished:          "finished",
+
+	crGetError:                      "crGetError",
+	wrongSelectorErr:                "wrongSelectorErr",
+	nodeGetError:                    "nodeGetError",
+	deRegisterError:                 "deRegisterError",
+	delStatusUpdateError:            "delStatusUpdateError",
+	emptyFinalizerError:             "emptyFinalizerError",
+	setFinalizerError:               "setFinalizerError",
+	invalidGatewayError:             "invalidGatewayError",
+	gatewayNotDirectlyRoutableError: "gatewayNotDirectlyRoutableError",
+	routeGetError:                   "routeGetError",
+	missingFallbackIPError:          "missingFallbackIPError",
+	invalidInterfaceError:           "invalidInterfaceError",
+	interfaceNotFoundError:          "interfaceNotFoundError",
+	linkGetError:                    "linkGetError",
+	invalidSrcError:                 "invalidSrcError",
+	invalidScopeError:               "invalidScopeError",
+	srcNotFoundError:                "srcNotFoundError",
+	parseSubnetError:                "parseSubnetError",
+	registerRouteError:              "registerRouteError",
+	updateRouteError:                "updateRouteError",
+	addStatusUpdateError:            "addStatusUpdateError",
+	policyGetError:                  "policyGetError",
+	noHealthyGatewayError:           "noHealthyGatewayError",
+}
+
+func init() {
+	metrics.Registry.MustRegister(reconcileResults)
+}
+
+func countResult(result *reconcile.Result) {
+	name, found := resultNames[result]
+	if !found {
+		name = "unknown"
+	}
+	reconcileResults.WithLabelValues(name).Inc()
+}
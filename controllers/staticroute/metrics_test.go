@@ -0,0 +1,49 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestCountResult(t *testing.T) {
+	before := testutil.ToFloat64(reconcileResults.WithLabelValues("overlapsProtected"))
+	unknownBefore := testutil.ToFloat64(reconcileResults.WithLabelValues("unknown"))
+
+	countResult(overlapsProtected)
+	countResult(&reconcile.Result{})
+
+	if value := testutil.ToFloat64(reconcileResults.WithLabelValues("overlapsProtected")); value != before+1 {
+		t.Errorf("overlapsProtected must be counted: %f", value)
+	}
+	if value := testutil.ToFloat64(reconcileResults.WithLabelValues("unknown")); value != unknownBefore+1 {
+		t.Errorf("Unknown result must be counted: %f", value)
+	}
+}
+
+func TestResultNamesAreUnique(t *testing.T) {
+	names := make(map[string]bool)
+	for _, name := range resultNames {
+		if names[name] {
+			t.Errorf("Result name %s is used twice", name)
+		}
+		names[name] = true
+	}
+}
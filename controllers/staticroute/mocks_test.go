@@ -102,14 +102,33 @@ func (m *statusWriterMock) Patch(ctx context.Context, obj client.Object, patch c
 }
 
 type routeManagerMock struct {
-	isRegistered       bool
-	registeredCallback func(string, routemanager.Route) error
-	registerRouteErr   error
-	deRegisterRouteErr error
+	isRegistered           bool
+	isRegisteredCallback   func(string) bool
+	registeredCallback     func(string, routemanager.Route) error
+	updatedCallback        func(string, routemanager.Route) error
+	deRegisteredCallback   func(string) error
+	collectGarbageCallback func(func(routemanager.Route) (string, bool)) error
+	registerRouteErr       error
+	updateRouteErr         error
+	deRegisterRouteErr     error
+}
+
+func (m routeManagerMock) IsRegistered(n string) bool {
+	if m.isRegisteredCallback != nil {
+		return m.isRegisteredCallback(n)
+	}
+	return m.isRegistered
 }
 
-func (m routeManagerMock) IsRegistered(string) bool {
-	return m.isRegistered
+func (m routeManagerMock) GetRoute(n string) (routemanager.Route, error) {
+	if m.IsRegistered(n) {
+		return routemanager.Route{}, nil
+	}
+	return routemanager.Route{}, routemanager.ErrNotFound
+}
+
+func (m routeManagerMock) ListRoutes() map[string]routemanager.Route {
+	return map[string]routemanager.Route{}
 }
 
 func (m routeManagerMock) RegisterRoute(n string, r routemanager.Route) error {
@@ -119,10 +138,31 @@ func (m routeManagerMock) RegisterRoute(n string, r routemanager.Route) error {
 	return m.registerRouteErr
 }
 
-func (m routeManagerMock) DeRegisterRoute(string) error {
+func (m routeManagerMock) UpdateRoute(n string, r routemanager.Route) error {
+	if m.updatedCallback != nil {
+		return m.updatedCallback(n, r)
+	}
+	return m.updateRouteErr
+}
+
+func (m routeManagerMock) DeRegisterRoute(n string) error {
+	if m.deRegisteredCallback != nil {
+		return m.deRegisteredCallback(n)
+	}
 	return m.deRegisterRouteErr
 }
 
+func (m routeManagerMock) CollectGarbage(adopt func(routemanager.Route) (string, bool)) error {
+	if m.collectGarbageCallback != nil {
+		return m.collectGarbageCallback(adopt)
+	}
+	return nil
+}
+
+func (m routeManagerMock) Audit() ([]routemanager.Drift, error) {
+	return nil, nil
+}
+
 func (m routeManagerMock) RegisterWatcher(routemanager.RouteWatcher) {
 }
 
@@ -135,9 +175,8 @@ func (m routeManagerMock) Run(chan struct{}) error {
 
 func newFakeClient(route *staticroutev1.StaticRoute) client.Client {
 	s := runtime.NewScheme()
-	s.AddKnownTypes(staticroutev1.GroupVersion, route)
-	nodes := &corev1.NodeList{}
-	s.AddKnownTypes(corev1.SchemeGroupVersion, nodes)
+	s.AddKnownTypes(staticroutev1.GroupVersion, route, &staticroutev1.StaticRouteList{}, &staticroutev1.StaticRoutePolicy{}, &staticroutev1.StaticRoutePolicyList{})
+	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Node{}, &corev1.NodeList{})
 	return fake.NewClientBuilder().
 		WithScheme(s).
 		WithStatusSubresource(route).
@@ -154,7 +193,7 @@ func newReconcileImplParams(client reconcileImplClient) *reconcileImplParams {
 			},
 		},
 		client:  client,
-		options: ManagerOptions{},
+		options: ManagerOptions{Policies: true},
 	}
 }
 
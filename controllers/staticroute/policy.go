@@ -0,0 +1,96 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/pkg/cidr"
+	"github.com/IBM/staticroute-operator/pkg/routemanager"
+	"github.com/IBM/staticroute-operator/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// policySubnets merges the protected subnets of the configuration and of the StaticRoutePolicy objects.
+// Allowed is nil if none of the policies restricts the subnets or the StaticRoutePolicy CRD is not installed.
+func policySubnets(params reconcileImplParams, logger types.Logger) (protected, allowed *cidr.Set, err error) {
+	if !params.options.Policies {
+		return params.options.ProtectedSubnets, nil, nil
+	}
+	policies := &staticroutev1.StaticRoutePolicyList{}
+	if err = params.client.List(context.Background(), policies); err != nil {
+		return nil, nil, err
+	}
+	protectedSubnets, allowedSubnets, invalid := policies.Subnets()
+	if len(invalid) != 0 {
+		logger.Info("Ignoring invalid subnets of StaticRoutePolicy", "Subnets", invalid)
+	}
+	protected = params.options.ProtectedSubnets
+	if len(protectedSubnets) != 0 {
+		protected = cidr.NewSet(append(append([]*net.IPNet{}, params.options.ProtectedSubnets.Subnets()...), protectedSubnets...)...)
+	}
+	if len(allowedSubnets) != 0 {
+		allowed = cidr.NewSet(allowedSubnets...)
+	}
+	return protected, allowed, nil
+}
+
+// checkPolicy returns overlapsProtected or notAllowed if the subnet of the StaticRoute is forbidden
+func checkPolicy(params reconcileImplParams, rw *routeWrapper, logger types.Logger) (*reconcile.Result, error) {
+	protected, allowed, err := policySubnets(params, logger)
+	if err != nil {
+		logger.Error(err, "Failed to fetch StaticRoutePolicies")
+		return policyGetError, err
+	}
+	if rw.isProtected(protected) {
+		return overlapsProtected, nil
+	}
+	if !rw.isAllowed(allowed) {
+		return notAllowed, nil
+	}
+	return nil, nil
+}
+
+// removeForbidden removes the route if it was installed before the policy forbade it
+func removeForbidden(params reconcileImplParams, rw *routeWrapper, logger types.Logger) error {
+	if !params.options.RouteManager.IsRegistered(params.request.Name) {
+		return nil
+	}
+	logger.Info("Deregistering route forbidden by the policy")
+	if err := params.options.RouteManager.DeRegisterRoute(params.request.Name); err != nil && err != routemanager.ErrNotFound {
+		logger.Error(err, "Unable to deregister route")
+		return err
+	}
+	params.events.event(rw.instance, corev1.EventTypeNormal, reasonRouteRemoved, fmt.Sprintf("Route to %s removed from node %s", rw.instance.Spec.Subnet, params.options.Hostname))
+	return nil
+}
+
+// subnetPolicyError returns the event reason and the status error if the subnet is forbidden by the policies
+func subnetPolicyError(subnet string, protected, allowed *cidr.Set) (string, error) {
+	if isProtectedSubnet(subnet, protected) {
+		return reasonSubnetOverlapsProtected, errors.New("given subnet overlaps with some protected subnet")
+	}
+	if !isAllowedSubnet(subnet, allowed) {
+		return reasonSubnetNotAllowed, errors.New("given subnet is not inside any allowed subnet")
+	}
+	return "", nil
+}
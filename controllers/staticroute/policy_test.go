@@ -0,0 +1,159 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/pkg/cidr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func getReconcileContextWithPolicy(t *testing.T, spec staticroutev1.StaticRoutePolicySpec) (*reconcileImplParams, *reconcileImplClientMock) {
+	route := newStaticRouteWithValues(true, true)
+	fakeClient := newFakeClient(route)
+	policy := &staticroutev1.StaticRoutePolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy"}, Spec: spec}
+	if err := fakeClient.Create(context.Background(), policy); err != nil {
+		t.Fatalf("Failed to create policy: %s", err.Error())
+	}
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	mockClient := &reconcileImplClientMock{client: fakeClient}
+	params.client = mockClient
+	return params, mockClient
+}
+
+func TestReconcileImplPolicyProtected(t *testing.T) {
+	params, mockClient := getReconcileContextWithPolicy(t, staticroutev1.StaticRoutePolicySpec{ProtectedSubnets: []string{"10.0.0.0/8"}})
+	deRegistered := false
+	params.options.RouteManager = routeManagerMock{
+		isRegistered: true,
+		deRegisteredCallback: func(string) error {
+			deRegistered = true
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != overlapsProtected {
+		t.Error("Result must be overlapsProtected")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if !deRegistered {
+		t.Error("Installed route must be removed")
+	}
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: "CR", Namespace: "default"}, instance); err != nil {
+		t.Errorf("Failed to read the CR: %s", err.Error())
+	}
+	if instance.Status.NodeStatus[0].Error != "given subnet overlaps with some protected subnet" {
+		t.Errorf("Error must be reported in the status: %s", instance.Status.NodeStatus[0].Error)
+	}
+}
+
+func TestReconcileImplPolicyMergedWithConfig(t *testing.T) {
+	params, _ := getReconcileContextWithPolicy(t, staticroutev1.StaticRoutePolicySpec{ProtectedSubnets: []string{"192.168.0.0/16"}})
+	params.options.ProtectedSubnets = cidr.NewSet(&net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.IPv4Mask(0xff, 0, 0, 0)})
+
+	res, _ := reconcileImpl(*params)
+
+	if res != overlapsProtected {
+		t.Error("Result must be overlapsProtected")
+	}
+	if len(params.options.ProtectedSubnets.Subnets()) != 1 {
+		t.Errorf("Configured subnets must not be modified: %v", params.options.ProtectedSubnets.Subnets())
+	}
+}
+
+func TestReconcileImplPolicyNotAllowed(t *testing.T) {
+	params, _ := getReconcileContextWithPolicy(t, staticroutev1.StaticRoutePolicySpec{AllowedSubnets: []string{"192.168.0.0/16", "invalid"}})
+
+	res, err := reconcileImpl(*params)
+
+	if res != notAllowed {
+		t.Error("Result must be notAllowed")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplPolicyAllowed(t *testing.T) {
+	params, _ := getReconcileContextWithPolicy(t, staticroutev1.StaticRoutePolicySpec{AllowedSubnets: []string{"10.0.0.0/8"}})
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplPolicyGetError(t *testing.T) {
+	params, mockClient := getReconcileContextWithPolicy(t, staticroutev1.StaticRoutePolicySpec{})
+	mockClient.listErr = errors.New("list failed")
+
+	res, err := reconcileImpl(*params)
+
+	if res != policyGetError {
+		t.Error("Result must be policyGetError")
+	}
+	if err == nil {
+		t.Error("Error must be not nil")
+	}
+}
+
+func TestReconcileImplPoliciesDisabled(t *testing.T) {
+	params, mockClient := getReconcileContextWithPolicy(t, staticroutev1.StaticRoutePolicySpec{ProtectedSubnets: []string{"10.0.0.0/8"}})
+	params.options.Policies = false
+	mockClient.listErr = errors.New("no kind is registered")
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished, the policies must not be looked up without the CRD")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplForbiddenButCantDeregister(t *testing.T) {
+	params, _ := getReconcileContextWithPolicy(t, staticroutev1.StaticRoutePolicySpec{ProtectedSubnets: []string{"10.0.0.0/8"}})
+	params.options.RouteManager = routeManagerMock{
+		isRegistered:       true,
+		deRegisterRouteErr: errors.New("deregister failed"),
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != deRegisterError {
+		t.Error("Result must be deRegisterError")
+	}
+	if err == nil {
+		t.Error("Error must be not nil")
+	}
+}
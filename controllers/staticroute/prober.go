@@ -0,0 +1,200 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// Defaults of the optional fields of GatewayProbe
+const (
+	defaultProbePeriod           = 10 * time.Second
+	defaultProbeTimeout          = time.Second
+	defaultProbeFailureThreshold = 3
+	defaultProbeSuccessThreshold = 1
+)
+
+// gatewayProber probes the candidate gateways of the StaticRoutes on this node. Every StaticRoute with candidate
+// gateways has its own probe loop, which re-enqueues the StaticRoute when the health of a gateway changes, so the
+// next reconciliation installs the route through the first healthy candidate. A nil gatewayProber considers every
+// gateway healthy.
+type gatewayProber struct {
+	probe   func(string, net.IP, int, time.Duration) error
+	events  chan event.GenericEvent
+	mutex   sync.Mutex
+	targets map[string]*probeTarget
+}
+
+// probeTarget is the probe loop of one StaticRoute, the gateways and the spec are not modified after creation
+type probeTarget struct {
+	spec     staticroutev1.GatewayProbe
+	gateways []net.IP
+	health   []gatewayHealth
+	stop     chan struct{}
+}
+
+type gatewayHealth struct {
+	healthy   bool
+	failures  int
+	successes int
+	since     *metav1.Time
+	message   string
+}
+
+func newGatewayProber(probe func(string, net.IP, int, time.Duration) error) *gatewayProber {
+	return &gatewayProber{
+		probe:   probe,
+		events:  make(chan event.GenericEvent),
+		targets: make(map[string]*probeTarget),
+	}
+}
+
+// watch starts probing the candidate gateways of the StaticRoute, or restarts it if the candidates or the probe
+// changed, and returns the current health of the candidates. The gateways are healthy until the probes prove otherwise.
+func (p *gatewayProber) watch(name string, gateways []net.IP, spec staticroutev1.GatewayProbe) []staticroutev1.GatewayProbeStatus {
+	if p == nil {
+		return (&probeTarget{gateways: gateways, health: newGatewayHealth(len(gateways))}).status()
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	target, found := p.targets[name]
+	if !found || target.spec != spec || !slices.EqualFunc(target.gateways, gateways, net.IP.Equal) {
+		if found {
+			close(target.stop)
+		}
+		target = &probeTarget{spec: spec, gateways: gateways, health: newGatewayHealth(len(gateways)), stop: make(chan struct{})}
+		p.targets[name] = target
+		go p.run(name, target)
+	}
+	return target.status()
+}
+
+// forget stops probing the candidate gateways of the StaticRoute
+func (p *gatewayProber) forget(name string) {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if target, found := p.targets[name]; found {
+		close(target.stop)
+		delete(p.targets, name)
+	}
+}
+
+func (p *gatewayProber) run(name string, target *probeTarget) {
+	ticker := time.NewTicker(durationOrDefault(target.spec.PeriodSeconds, defaultProbePeriod))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-target.stop:
+			return
+		case <-ticker.C:
+		}
+		if !p.probeAll(target) {
+			continue
+		}
+		log.Info("Health of a candidate gateway changed", "Request.Name", name)
+		select {
+		case p.events <- event.GenericEvent{Object: &staticroutev1.StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: name}}}:
+		case <-target.stop:
+			return
+		}
+	}
+}
+
+// probeAll probes every candidate gateway once, returns true if the health of any of them changed
+func (p *gatewayProber) probeAll(target *probeTarget) bool {
+	// The probes can take long, so they run without holding the lock
+	errs := make([]error, len(target.gateways))
+	for i, gateway := range target.gateways {
+		errs[i] = p.probe(target.spec.Type, gateway, target.spec.Port, durationOrDefault(target.spec.TimeoutSeconds, defaultProbeTimeout))
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	changed := false
+	for i := range target.health {
+		changed = target.health[i].update(errs[i], target.spec) || changed
+	}
+	return changed
+}
+
+// status returns the health of the gateways, the caller must hold the lock of the prober
+func (t *probeTarget) status() []staticroutev1.GatewayProbeStatus {
+	statuses := make([]staticroutev1.GatewayProbeStatus, 0, len(t.gateways))
+	for i, gateway := range t.gateways {
+		statuses = append(statuses, staticroutev1.GatewayProbeStatus{
+			Gateway: gateway.String(),
+			Healthy: t.health[i].healthy,
+			Since:   t.health[i].since.DeepCopy(),
+			Message: t.health[i].message,
+		})
+	}
+	return statuses
+}
+
+func newGatewayHealth(n int) []gatewayHealth {
+	health := make([]gatewayHealth, n)
+	for i := range health {
+		health[i].healthy = true
+	}
+	return health
+}
+
+// update counts the result of a probe, returns true if the gateway became healthy or unhealthy
+func (h *gatewayHealth) update(err error, spec staticroutev1.GatewayProbe) bool {
+	if err != nil {
+		h.successes = 0
+		h.failures++
+		if !h.healthy || h.failures < intOrDefault(spec.FailureThreshold, defaultProbeFailureThreshold) {
+			return false
+		}
+		h.healthy, h.message = false, err.Error()
+	} else {
+		h.failures = 0
+		h.successes++
+		if h.healthy || h.successes < intOrDefault(spec.SuccessThreshold, defaultProbeSuccessThreshold) {
+			return false
+		}
+		h.healthy, h.message = true, ""
+	}
+	// The status stores the time in seconds
+	since := metav1.NewTime(time.Now().Truncate(time.Second))
+	h.since = &since
+	return true
+}
+
+func durationOrDefault(seconds int, defaultDuration time.Duration) time.Duration {
+	if seconds == 0 {
+		return defaultDuration
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func intOrDefault(value, defaultValue int) int {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,116 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+)
+
+var testProbe = staticroutev1.GatewayProbe{Type: staticroutev1.ProbeTypeTCP, Port: 22, PeriodSeconds: 3600, FailureThreshold: 2, SuccessThreshold: 2}
+
+func TestGatewayHealthUpdate(t *testing.T) {
+	health := newGatewayHealth(1)[0]
+	probeErr := errors.New("timeout")
+
+	if health.update(probeErr, testProbe) || !health.healthy {
+		t.Fatal("Gateway must be healthy until the failure threshold")
+	}
+	if !health.update(probeErr, testProbe) || health.healthy || health.message != "timeout" || health.since == nil {
+		t.Fatalf("Gateway must be unhealthy at the failure threshold: %+v", health)
+	}
+	if health.update(probeErr, testProbe) {
+		t.Error("Further failures must not change the health")
+	}
+	if health.update(nil, testProbe) || health.healthy {
+		t.Fatal("Gateway must be unhealthy until the success threshold")
+	}
+	if !health.update(nil, testProbe) || !health.healthy || health.message != "" {
+		t.Errorf("Gateway must be healthy at the success threshold: %+v", health)
+	}
+}
+
+func TestGatewayProberWatch(t *testing.T) {
+	gateways := []net.IP{{10, 0, 0, 1}, {10, 0, 0, 2}}
+	prober := newGatewayProber(func(_ string, gateway net.IP, _ int, _ time.Duration) error {
+		if gateway.Equal(gateways[0]) {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	defer prober.forget("CR")
+
+	probes := prober.watch("CR", gateways, testProbe)
+	if len(probes) != 2 || !probes[0].Healthy || !probes[1].Healthy {
+		t.Fatalf("Gateways must be healthy before the first probe: %+v", probes)
+	}
+	target := prober.targets["CR"]
+	if prober.probeAll(target) || !prober.probeAll(target) {
+		t.Fatal("Health must change at the failure threshold")
+	}
+	probes = prober.watch("CR", gateways, testProbe)
+	if probes[0].Healthy || probes[0].Message != "connection refused" || probes[0].Since == nil || !probes[1].Healthy {
+		t.Errorf("Failed gateway must be reported: %+v", probes)
+	}
+	if prober.targets["CR"] != target {
+		t.Error("Probing must go on if nothing changed")
+	}
+
+	changed := testProbe
+	changed.Port = 80
+	if probes = prober.watch("CR", gateways, changed); !probes[0].Healthy || prober.targets["CR"] == target {
+		t.Errorf("Probing must restart if the probe changed: %+v", probes)
+	}
+
+	prober.forget("CR")
+	if len(prober.targets) != 0 {
+		t.Error("Probing must stop")
+	}
+}
+
+func TestGatewayProberRun(t *testing.T) {
+	prober := newGatewayProber(func(string, net.IP, int, time.Duration) error {
+		return errors.New("no route to host")
+	})
+	defer prober.forget("CR")
+
+	prober.watch("CR", []net.IP{{10, 0, 0, 1}}, staticroutev1.GatewayProbe{Type: staticroutev1.ProbeTypeICMP, PeriodSeconds: 1, FailureThreshold: 1})
+
+	select {
+	case e := <-prober.events:
+		if e.Object.GetName() != "CR" {
+			t.Errorf("Wrong StaticRoute is enqueued: %s", e.Object.GetName())
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("StaticRoute must be enqueued when the gateway becomes unhealthy")
+	}
+}
+
+func TestGatewayProberNil(t *testing.T) {
+	var prober *gatewayProber
+
+	probes := prober.watch("CR", []net.IP{{10, 0, 0, 1}}, testProbe)
+	prober.forget("CR")
+
+	if len(probes) != 1 || !probes[0].Healthy || probes[0].Gateway != "10.0.0.1" {
+		t.Errorf("Gateways must be healthy without prober: %+v", probes)
+	}
+}
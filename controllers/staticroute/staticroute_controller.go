@@ -21,8 +21,11 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"time"
 
 	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/controllers/node"
+	"github.com/IBM/staticroute-operator/pkg/cidr"
 	"github.com/IBM/staticroute-operator/pkg/routemanager"
 	"github.com/IBM/staticroute-operator/pkg/types"
 	corev1 "k8s.io/api/core/v1"
@@ -34,29 +37,41 @@ import (
 	k8stypes "k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
-	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
-)
-
-var (
-	//HostNameLabel label to determine hostname
-	HostNameLabel = "kubernetes.io/hostname"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 var log = logf.Log.WithName("controller_staticroute")
 
 // ManagerOptions contains static route management related node properties
 type ManagerOptions struct {
-	RouteManager             routemanager.RouteManager
-	Hostname                 string
-	Table                    int
-	ProtectedSubnets         []*net.IPNet
+	RouteManager routemanager.RouteManager
+	Hostname     string
+	Table        int
+	// ProtectedSubnets are the subnets a StaticRoute must not overlap with
+	ProtectedSubnets *cidr.Set
+	// Policies is true if the StaticRoutePolicy CRD is installed, the StaticRoutes are checked only against the
+	// ProtectedSubnets otherwise
+	Policies                 bool
 	FallbackIPForGwSelection net.IP
-	GetGw                    func(net.IP) (net.IP, error)
+	// FallbackIPv6ForGwSelection is used instead of FallbackIPForGwSelection for IPv6 subnets (optional)
+	FallbackIPv6ForGwSelection net.IP
+	GetGw                      func(net.IP) (net.IP, error)
+	// GetLinkIndex returns the index of the interface by its name, 0 if there is no such interface
+	GetLinkIndex func(string) (int, error)
+	// GetLinkIndexByAddress returns the index of the interface holding an address inside the subnet, 0 if there is none
+	GetLinkIndexByAddress func(*net.IPNet) (int, error)
+	// GetLinkAddress returns the first global address of the interface with the given IP family (IPv6 if true),
+	// nil if there is no such interface or address
+	GetLinkAddress func(string, bool) (net.IP, error)
+	// TamperReactionBackoff is the initial delay before re-creating a route deleted by an external entity
+	TamperReactionBackoff time.Duration
+	// ProbeGateway checks the health of a candidate gateway with the given probe type, port and timeout,
+	// nil means healthy. The candidate gateways are not probed if it is not set.
+	ProbeGateway func(string, net.IP, int, time.Duration) error
 }
 
 // StaticRouteReconciler reconciles a StaticRoute object
@@ -66,15 +81,30 @@ type StaticRouteReconciler struct {
 	client  client.Client
 	scheme  *runtime.Scheme
 	options ManagerOptions
+	watcher *tamperWatcher
+	prober  *gatewayProber
+	events  *routeEventRecorder
 }
 
 // Add creates a new StaticRoute Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager, options ManagerOptions) error {
+	watcher := newTamperWatcher(options.RouteManager, options.TamperReactionBackoff)
+	options.RouteManager.RegisterWatcher(watcher)
+	if err := mgr.Add(&garbageCollector{reader: mgr.GetAPIReader(), options: options}); err != nil {
+		return err
+	}
+	var prober *gatewayProber
+	if options.ProbeGateway != nil {
+		prober = newGatewayProber(options.ProbeGateway)
+	}
 	return (&StaticRouteReconciler{
 		client:  mgr.GetClient(),
 		scheme:  mgr.GetScheme(),
-		options: options}).
+		options: options,
+		watcher: watcher,
+		prober:  prober,
+		events:  newRouteEventRecorder(mgr.GetEventRecorderFor("static-route-operator"), options.Hostname)}).
 		SetupWithManager(mgr)
 }
 
@@ -86,6 +116,7 @@ var _ reconcile.Reconciler = &StaticRouteReconciler{}
 //+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;create
 //+kubebuilder:rbac:groups=apps,resourceNames=static-route-operator,resources=deployments/finalizers,verbs=update
 //+kubebuilder:rbac:groups=static-route.ibm.com,resources=*,verbs=*
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile reads that state of the cluster for a StaticRoute object and makes changes based on the state read
 // and what is in the StaticRoute.Spec
@@ -94,8 +125,15 @@ func (r *StaticRouteReconciler) Reconcile(ctx context.Context, request reconcile
 		request: request,
 		client:  r.client.(reconcileImplClient),
 		options: r.options,
+		prober:  r.prober,
+		events:  r.events,
+	}
+	if r.watcher != nil {
+		params.tampered = r.watcher.popTampered(request.Name)
+		params.drifted = r.watcher.popDrifted(request.Name)
 	}
 	result, err := reconcileImpl(params)
+	countResult(result)
 	return *result, err
 }
 
@@ -110,15 +148,23 @@ type reconcileImplParams struct {
 	request reconcile.Request
 	client  reconcileImplClient
 	options ManagerOptions
+	// tampered is set if the route was deleted by an external entity since the last reconciliation
+	tampered *metav1.Time
+	// drifted describes the last drift from the kernel found by the audit of the RouteManager since the last reconciliation
+	drifted string
+	prober  *gatewayProber
+	events  *routeEventRecorder
 }
 
 var (
 	crNotFound        = &reconcile.Result{}
 	nodeNotFound      = &reconcile.Result{}
 	overlapsProtected = &reconcile.Result{}
+	notAllowed        = &reconcile.Result{}
 	alreadyDeleted    = &reconcile.Result{}
 	deletionFinished  = &reconcile.Result{}
 	updateFinished    = &reconcile.Result{Requeue: true}
+	routeUpdated      = &reconcile.Result{}
 	finished          = &reconcile.Result{}
 
 	crGetError                      = &reconcile.Result{}
@@ -131,18 +177,28 @@ var (
 	invalidGatewayError             = &reconcile.Result{}
 	gatewayNotDirectlyRoutableError = &reconcile.Result{}
 	routeGetError                   = &reconcile.Result{}
+	missingFallbackIPError          = &reconcile.Result{}
+	invalidInterfaceError           = &reconcile.Result{}
+	interfaceNotFoundError          = &reconcile.Result{}
+	linkGetError                    = &reconcile.Result{}
+	invalidSrcError                 = &reconcile.Result{}
+	invalidScopeError               = &reconcile.Result{}
+	srcNotFoundError                = &reconcile.Result{}
 	parseSubnetError                = &reconcile.Result{}
 	registerRouteError              = &reconcile.Result{}
+	updateRouteError                = &reconcile.Result{}
 	addStatusUpdateError            = &reconcile.Result{}
+	policyGetError                  = &reconcile.Result{}
+	noHealthyGatewayError           = &reconcile.Result{}
 )
 
 func reconcileImpl(params reconcileImplParams) (res *reconcile.Result, err error) {
 	reqLogger := log.WithValues("Node", params.options.Hostname, "Request.Name", params.request.Name)
 	reqLogger.Info("Reconciling StaticRoute")
 
-	// Default 0.0.0.0 is set to fulfill the CRD requirements
-	gateway := net.IP{0, 0, 0, 0}
 	reportStatus := true
+	// statusOutdated is set when the route was updated in place, so the node status must be rewritten with the new state
+	statusOutdated := false
 
 	// Fetch the StaticRoute instance
 	instance := &staticroutev1.StaticRoute{}
@@ -152,6 +208,7 @@ func reconcileImpl(params reconcileImplParams) (res *reconcile.Result, err error
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
 			reqLogger.Info("Object not found. Probably deleted meanwhile")
+			params.prober.forget(params.request.Name)
 			return crNotFound, nil
 		}
 		// Error reading the object - requeue the request.
@@ -160,6 +217,24 @@ func reconcileImpl(params reconcileImplParams) (res *reconcile.Result, err error
 
 	rw := routeWrapper{instance: instance}
 
+	// Default 0.0.0.0 (or :: for IPv6) is reported until the gateway is selected, only by unicast routes without
+	// candidate gateways, see stateGateway
+	gateway := net.IP{0, 0, 0, 0}
+	if rw.isIPv6() {
+		gateway = net.IPv6zero
+	}
+	// Next hops of multipath routes, which are installed on the node
+	var nextHops []staticroutev1.NextHop
+	// Index of the output interface, 0 if the route does not specify one
+	linkIndex := 0
+	// Preferred source address, nil if the route does not specify one
+	var src net.IP
+	// Results of the subnets one by one, only for StaticRoutes with multiple subnets
+	var subnetStatuses []staticroutev1.SubnetStatus
+	// Health of the candidate gateways, only for StaticRoutes with candidate gateways
+	var probeStatuses []staticroutev1.GatewayProbeStatus
+	activeGateway := ""
+
 	defer func() {
 		if !reportStatus {
 			return
@@ -169,26 +244,64 @@ func reconcileImpl(params reconcileImplParams) (res *reconcile.Result, err error
 		switch res {
 		case overlapsProtected:
 			serr = errors.New("given subnet overlaps with some protected subnet")
+			params.events.event(instance, corev1.EventTypeWarning, reasonSubnetOverlapsProtected, fmt.Sprintf("Subnet %s overlaps with some protected subnet", instance.Spec.Subnet))
+		case notAllowed:
+			serr = errors.New("given subnet is not inside any allowed subnet")
+			params.events.event(instance, corev1.EventTypeWarning, reasonSubnetNotAllowed, fmt.Sprintf("Subnet %s is not inside any allowed subnet", instance.Spec.Subnet))
 		case gatewayNotDirectlyRoutableError:
 			serr = errors.New("given gateway IP is not directly routable, cannot setup the route")
+			params.events.event(instance, corev1.EventTypeWarning, reasonGatewayNotDirectlyRoutable, fmt.Sprintf("Gateway of subnet %s is not directly routable on node %s", rw.subnetText(), params.options.Hostname))
+		case noHealthyGatewayError:
+			serr = errors.New("none of the candidate gateways is healthy and directly routable, cannot setup the route")
+			params.events.event(instance, corev1.EventTypeWarning, reasonNoHealthyGateway, fmt.Sprintf("None of the candidate gateways of subnet %s is healthy on node %s", rw.subnetText(), params.options.Hostname))
+		case missingFallbackIPError:
+			serr = errors.New("no IPv6 fallback IP is configured, cannot select the gateway")
+		case invalidScopeError:
+			serr = errors.New("IPv6 routes support only the universe scope, cannot setup the route")
+		case interfaceNotFoundError:
+			serr = errors.New("given interface is not found on the node, cannot setup the route")
+			params.events.event(instance, corev1.EventTypeWarning, reasonInterfaceNotFound, fmt.Sprintf("Interface of subnet %s is not found on node %s", rw.subnetText(), params.options.Hostname))
+		case srcNotFoundError:
+			serr = errors.New("given source interface has no address of the subnet's IP family on the node, cannot setup the route")
+			params.events.event(instance, corev1.EventTypeWarning, reasonInterfaceNotFound, fmt.Sprintf("Source interface of subnet %s is not found on node %s", rw.subnetText(), params.options.Hostname))
 		default:
 			serr = err
 		}
-		if !rw.statusMatch(params.options.Hostname, gateway, serr) {
+		// the node reports an error if any of the subnets failed, the details are in the subnet statuses
+		if failed := failedSubnets(subnetStatuses); failed != 0 {
+			serr = fmt.Errorf("%d of %d subnets failed", failed, len(subnetStatuses))
+		}
+		statusChanged := false
+		if statusOutdated || !rw.statusMatch(params.options.Hostname, gateway, nextHops, serr) || !rw.subnetStatusMatch(params.options.Hostname, subnetStatuses) || !rw.probeStatusMatch(params.options.Hostname, activeGateway, probeStatuses) {
+			tamperedAt, tamperCount := rw.tamperStatus(params.options.Hostname)
 			_ = rw.removeFromStatus(params.options.Hostname)
-			if rw.addToStatus(params.options.Hostname, gateway, serr) {
-				reqLogger.Info("Update the StaticRoute status", "staticroute", rw.instance.Status)
-				if cerr := params.client.Status().Update(context.Background(), rw.instance); cerr != nil {
-					reqLogger.Error(err, "failed to update the staticroute")
-					res = addStatusUpdateError
-					err = cerr
-				}
+			statusChanged = rw.addToStatus(params.options.Hostname, gateway, nextHops, serr)
+			rw.setSubnetStatus(params.options.Hostname, subnetStatuses)
+			rw.setProbeStatus(params.options.Hostname, activeGateway, probeStatuses)
+			rw.setTamperStatus(params.options.Hostname, tamperedAt, tamperCount)
+		}
+		statusChanged = rw.setObservedGeneration(params.options.Hostname) || statusChanged
+		if params.tampered != nil {
+			params.events.event(instance, corev1.EventTypeWarning, reasonRouteTampered, fmt.Sprintf("Route to %s was deleted by an external entity on node %s", rw.subnetText(), params.options.Hostname))
+			_, tamperCount := rw.tamperStatus(params.options.Hostname)
+			statusChanged = rw.setTamperStatus(params.options.Hostname, params.tampered, tamperCount+1) || statusChanged
+		}
+		if params.drifted != "" {
+			params.events.event(instance, corev1.EventTypeWarning, reasonRouteDrifted, fmt.Sprintf("%s on node %s", params.drifted, params.options.Hostname))
+		}
+		statusChanged = updateSummary(params, &rw, reqLogger) || statusChanged
+		if statusChanged {
+			reqLogger.Info("Update the StaticRoute status", "staticroute", rw.instance.Status)
+			if cerr := params.client.Status().Update(context.Background(), rw.instance); cerr != nil {
+				reqLogger.Error(err, "failed to update the staticroute")
+				res = addStatusUpdateError
+				err = cerr
 			}
 		}
 
 		// if staticroute deletion started, fire delete operation
 		if !instance.DeletionTimestamp.IsZero() {
-			res, err = deleteOperation(params, &rw, reqLogger)
+			res, err = deleteOperation(params, &rw, rw.managedRoutes(params.request.Name, params.options.Hostname), reqLogger)
 		}
 	}()
 
@@ -208,29 +321,98 @@ func reconcileImpl(params reconcileImplParams) (res *reconcile.Result, err error
 		}
 	}
 
-	// Check if the staticroute overlaps with some protected subnets
-	if rw.isProtected(params.options.ProtectedSubnets) {
-		// a subnet overlaps some protected, ignore, but set error in nodeStatus
-		reqLogger.Info("Error: subnet overlaps some protected", "Subnet", rw.instance.Spec.Subnet)
-		res = overlapsProtected
+	// Check if the staticroute overlaps with some protected subnets or it is outside of the allowed ones.
+	// The subnets of the list are checked one by one by subnetsOperation, so a forbidden subnet does not block the others.
+	if rw.hasSubnets() {
+		reqLogger.Info("Multiple subnets found", "Subnets", rw.instance.Spec.Subnets)
+	} else if res, err = checkPolicy(params, &rw, reqLogger); res != nil {
+		if res == overlapsProtected || res == notAllowed {
+			// the subnet is forbidden, ignore, but set error in nodeStatus
+			reqLogger.Info("Error: subnet is forbidden by the policy", "Subnet", rw.instance.Spec.Subnet)
+			// The policy may have changed since the route was installed
+			if err = removeForbidden(params, &rw, reqLogger); err != nil {
+				res = deRegisterError
+			}
+		}
 		return
 	}
-	// If "gateway" is empty, we'll create the route through the default private network gateway
-	res, gateway, err = selectGateway(params, rw, reqLogger)
-	if gateway == nil || res == gatewayNotDirectlyRoutableError {
+	if rw.hasInterface() {
+		if res, linkIndex, err = selectInterface(params, rw, reqLogger); res != nil {
+			return
+		}
+	}
+	if len(rw.instance.Spec.Src) != 0 || len(rw.instance.Spec.SrcInterface) != 0 {
+		if res, src, err = selectSrc(params, rw, reqLogger); res != nil {
+			return
+		}
+	}
+	// The kernel installs every IPv6 route with the universe scope, an other scope would be reported but not applied
+	if rw.isIPv6() && len(rw.instance.Spec.Scope) != 0 && rw.instance.Spec.Scope != staticroutev1.ScopeUniverse {
+		reqLogger.Error(errors.New("IPv6 routes support only the universe scope"), rw.instance.Spec.Scope)
+		res = invalidScopeError
 		return
 	}
+	if !rw.hasCandidateGateways() {
+		// The candidate gateways may have been removed from the Spec
+		params.prober.forget(params.request.Name)
+	}
+	if rw.hasCandidateGateways() {
+		var selectedGateway net.IP
+		res, selectedGateway, probeStatuses, err = selectCandidateGateway(params, rw, reqLogger)
+		if res != nil {
+			return
+		}
+		gateway, activeGateway = selectedGateway, selectedGateway.String()
+	} else if len(rw.instance.Spec.Gateways) != 0 {
+		var selectedNextHops []staticroutev1.NextHop
+		res, selectedNextHops, err = selectNextHops(params, rw, reqLogger)
+		if selectedNextHops == nil {
+			return
+		}
+		gateway, nextHops = nil, selectedNextHops
+	} else {
+		// If "gateway" is empty, we'll create the route through the default private network gateway
+		var selectedGateway net.IP
+		res, selectedGateway, err = selectGateway(params, rw, reqLogger)
+		// Special route types and device routes have no gateway at all
+		if res != nil || (selectedGateway == nil && rw.needsGateway()) {
+			return
+		}
+		gateway = selectedGateway
+	}
+
+	table := params.options.Table
+	if rw.instance.Spec.Table != nil {
+		table = *rw.instance.Spec.Table
+	}
 
-	isChanged := rw.isChanged(params.options.Hostname, gateway.String(), rw.instance.Spec.Selectors)
+	isChanged := rw.isChanged(params.options.Hostname, gatewayString(gateway), nextHops, rw.instance.Spec.Selectors)
 	reqLogger.Info("The resource is", "changed", isChanged)
+	if rw.hasSubnets() && instance.GetDeletionTimestamp() == nil && !selectorNoLongerMatches {
+		res, subnetStatuses, err = subnetsOperation(params, &rw, gateway, nextHops, linkIndex, src, table, isChanged, reqLogger)
+		statusOutdated = isChanged
+		return
+	}
+	if isChanged && instance.GetDeletionTimestamp() == nil && !selectorNoLongerMatches && params.options.RouteManager.IsRegistered(params.request.Name) {
+		if res, err = updateOperation(params, &rw, gateway, nextHops, linkIndex, src, table, reqLogger); res == routeUpdated {
+			statusOutdated = true
+			return
+		}
+		// The route is re-created below, the registration reports the error if it persists
+		reqLogger.Info("Unable to update the route in place, re-creating it")
+	}
 	if instance.GetDeletionTimestamp() != nil ||
 		isChanged ||
 		selectorNoLongerMatches {
 		reportStatus = false
+		if !isChanged {
+			params.prober.forget(params.request.Name)
+		}
+		routes := rw.managedRoutes(params.request.Name, params.options.Hostname)
 		if !rw.removeFromStatus(params.options.Hostname) {
 			return alreadyDeleted, nil
 		}
-		res, err = deleteOperation(params, &rw, reqLogger)
+		res, err = deleteOperation(params, &rw, routes, reqLogger)
 
 		if isChanged {
 			return updateFinished, err
@@ -238,84 +420,84 @@ func reconcileImpl(params reconcileImplParams) (res *reconcile.Result, err error
 		return
 	}
 
-	table := params.options.Table
-	if rw.instance.Spec.Table != nil {
-		table = *rw.instance.Spec.Table
-	}
-
-	return addOperation(params, &rw, gateway, table, reqLogger)
+	return addOperation(params, &rw, gateway, nextHops, linkIndex, src, table, reqLogger)
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *StaticRouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Watch for changes to primary resource StaticRoute
-	err := ctrl.NewControllerManagedBy(mgr).Named("staticroute-controller").
+	builder := ctrl.NewControllerManagedBy(mgr).Named("staticroute-controller").
 		For(&staticroutev1.StaticRoute{}).
-		Watches(&staticroutev1.StaticRoute{}, &handler.EnqueueRequestForObject{}).
-		Complete(r)
-	if err != nil {
+		Watches(&staticroutev1.StaticRoute{}, &handler.EnqueueRequestForObject{})
+	if r.options.Policies {
+		// Every route is re-evaluated if a policy changes
+		builder = builder.Watches(&staticroutev1.StaticRoutePolicy{}, handler.EnqueueRequestsFromMapFunc(r.allRoutes))
+	}
+	if r.watcher != nil {
+		// Routes deleted by external entities are re-enqueued by the watcher
+		builder = builder.WatchesRawSource(source.Channel(r.watcher.events, &handler.EnqueueRequestForObject{}))
+	}
+	if r.prober != nil {
+		// Routes whose candidate gateways changed health are re-enqueued by the prober
+		builder = builder.WatchesRawSource(source.Channel(r.prober.events, &handler.EnqueueRequestForObject{}))
+	}
+	if err := builder.Complete(r); err != nil {
 		return err
 	}
 
 	// Watch if the self node labels are changed, so reconcile every route
-	err = ctrl.NewControllerManagedBy(mgr).Named("staticroute-controller").
-		For(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: r.options.Hostname}}).
-		Watches(
-			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: r.options.Hostname}},
-			handler.EnqueueRequestsFromMapFunc(
-				func(ctx context.Context, a client.Object) []reconcile.Request {
-					routes := &staticroutev1.StaticRouteList{}
-					if err := r.client.List(ctx, routes); err != nil {
-						log.Error(err, "Failed to List StaticRoute CRs")
-						return nil
-					}
-
-					var result []reconcile.Request
-					for _, route := range routes.Items {
-						result = append(result, reconcile.Request{
-							NamespacedName: k8stypes.NamespacedName{
-								Name:      route.GetName(),
-								Namespace: route.GetNamespace(),
-							},
-						})
-					}
-					return result
-				},
-			)).
-		WithEventFilter(
-			&predicate.Funcs{
-				CreateFunc: func(e event.CreateEvent) bool {
-					return false
-				},
-				UpdateFunc: func(e event.UpdateEvent) bool {
-					if len(e.ObjectNew.GetLabels()) != len(e.ObjectOld.GetLabels()) {
-						log.Info("Node label amount changed. Submitting all StaticRoute CRs for reconciliation.")
-						return true
-					}
-					for k, v := range e.ObjectOld.GetLabels() {
-						if e.ObjectNew.GetLabels()[k] != v {
-							log.Info("Node labels are changed. Submitting all StaticRoute CRs for reconciliation.")
-							return true
-						}
-					}
-					return false
-				},
-				DeleteFunc: func(e event.DeleteEvent) bool {
-					return false
-				},
-			}).
-		Complete(r)
-
-	return err
+	return node.WatchLabels(mgr, "staticroute-controller", r.options.Hostname, r.allRoutes, r)
+}
+
+// allRoutes returns a reconcile request for every StaticRoute
+func (r *StaticRouteReconciler) allRoutes(ctx context.Context, _ client.Object) []reconcile.Request {
+	routes := &staticroutev1.StaticRouteList{}
+	if err := r.client.List(ctx, routes); err != nil {
+		log.Error(err, "Failed to List StaticRoute CRs")
+		return nil
+	}
+
+	var result []reconcile.Request
+	for _, route := range routes.Items {
+		result = append(result, reconcile.Request{
+			NamespacedName: k8stypes.NamespacedName{
+				Name:      route.GetName(),
+				Namespace: route.GetNamespace(),
+			},
+		})
+	}
+	return result
 }
 
 func selectGateway(params reconcileImplParams, rw routeWrapper, logger types.Logger) (*reconcile.Result, net.IP, error) {
 	gateway := rw.getGateway()
+	if !rw.isUnicast() {
+		if len(rw.instance.Spec.Gateway) != 0 {
+			logger.Error(errors.New("only unicast routes can have a gateway"), rw.instance.Spec.Gateway)
+			return invalidGatewayError, nil, nil
+		}
+		return nil, nil, nil
+	}
 	if gateway == nil && len(rw.instance.Spec.Gateway) != 0 {
 		logger.Error(errors.New("invalid gateway found in Spec"), rw.instance.Spec.Gateway)
 		return invalidGatewayError, nil, nil
 	}
-	if gateway != nil {
+	if rw.instance.Spec.OnLink && (gateway == nil || !rw.hasInterface()) {
+		logger.Error(errors.New("onLink needs a gateway and an interface"), rw.instance.Spec.Gateway)
+		return invalidGatewayError, nil, nil
+	}
+	if gateway == nil && rw.hasInterface() {
+		logger.Info("No gateway is set, routing on-link through the interface")
+		return nil, nil, nil
+	}
+	if gateway != nil && (gateway.To4() == nil) != rw.isIPv6() {
+		logger.Error(errors.New("gateway and subnet IP families are different"), rw.instance.Spec.Gateway)
+		return invalidGatewayError, nil, nil
+	}
+	if rw.instance.Spec.OnLink {
+		// The gateway is reachable on the link by definition, the kernel does not check it
+		logger.Info("Gateway is on-link, skipping the routability check", "Gateway", rw.instance.Spec.Gateway)
+	} else if gateway != nil {
 		extraGw, err := params.options.GetGw(gateway)
 		if err != nil {
 			logger.Error(err, "")
@@ -326,7 +508,16 @@ func selectGateway(params reconcileImplParams, rw routeWrapper, logger types.Log
 			return gatewayNotDirectlyRoutableError, gateway, nil
 		}
 	} else {
-		defaultGateway, err := params.options.GetGw(params.options.FallbackIPForGwSelection)
+		fallbackIP := params.options.FallbackIPForGwSelection
+		if rw.isIPv6() {
+			// There is no default for IPv6, it must be configured explicitly
+			if params.options.FallbackIPv6ForGwSelection == nil {
+				logger.Error(errors.New("no IPv6 fallback IP configured for gateway selection"), rw.instance.Spec.Subnet)
+				return missingFallbackIPError, nil, nil
+			}
+			fallbackIP = params.options.FallbackIPv6ForGwSelection
+		}
+		defaultGateway, err := params.options.GetGw(fallbackIP)
 		if err != nil {
 			logger.Error(err, "")
 			return routeGetError, nil, err
@@ -337,27 +528,159 @@ func selectGateway(params reconcileImplParams, rw routeWrapper, logger types.Log
 	return nil, gateway, nil
 }
 
-func validateNodeBySelector(params reconcileImplParams, rw *routeWrapper, logger types.Logger) (*reconcile.Result, error) {
-	nodes := &corev1.NodeList{}
-	selector := labels.NewSelector()
-	allSelector := rw.instance.Spec.Selectors
-	allSelector = append(allSelector, metav1.LabelSelectorRequirement{
-		Key:      HostNameLabel,
-		Operator: metav1.LabelSelectorOpIn,
-		Values:   []string{params.options.Hostname},
-	})
-	for _, s := range allSelector {
-		operator, err := convertToOperator(s.Operator)
+// selectCandidateGateway returns the first candidate gateway, which is healthy and directly routable on the node.
+// The candidates are probed in the background, their health is reported in the node status.
+func selectCandidateGateway(params reconcileImplParams, rw routeWrapper, logger types.Logger) (*reconcile.Result, net.IP, []staticroutev1.GatewayProbeStatus, error) {
+	if !rw.isUnicast() {
+		logger.Error(errors.New("only unicast routes can have candidate gateways"), rw.instance.Spec.Type)
+		return invalidGatewayError, nil, nil, nil
+	}
+	if len(rw.instance.Spec.Gateway) != 0 || len(rw.instance.Spec.Gateways) != 0 {
+		logger.Error(errors.New("candidateGateways is mutually exclusive with gateway and gateways"), rw.instance.Spec.Gateway)
+		return invalidGatewayError, nil, nil, nil
+	}
+	if rw.instance.Spec.Probe == nil {
+		logger.Error(errors.New("candidateGateways need a probe"), rw.instance.Spec.Subnet)
+		return invalidGatewayError, nil, nil, nil
+	}
+	candidates := []net.IP{}
+	for _, candidate := range rw.instance.Spec.CandidateGateways {
+		gateway := net.ParseIP(candidate)
+		if gateway == nil || (gateway.To4() == nil) != rw.isIPv6() {
+			logger.Error(errors.New("invalid candidate gateway found in Spec"), candidate)
+			return invalidGatewayError, nil, nil, nil
+		}
+		candidates = append(candidates, gateway)
+	}
+	probes := params.prober.watch(params.request.Name, candidates, *rw.instance.Spec.Probe)
+	for i, candidate := range candidates {
+		if !probes[i].Healthy {
+			logger.Info("Candidate gateway is unhealthy, skipping it", "Gateway", probes[i].Gateway, "Message", probes[i].Message)
+			continue
+		}
+		extraGw, err := params.options.GetGw(candidate)
 		if err != nil {
-			log.Info("There is something wrong with the node selector operator", "Value", s)
-			return wrongSelectorErr, nil
+			logger.Error(err, "")
+			return routeGetError, nil, probes, err
 		}
-		req, err := labels.NewRequirement(s.Key, operator, s.Values)
+		if extraGw != nil {
+			logger.Info("Candidate gateway is not directly routable, skipping it", "Gateway", probes[i].Gateway, "Next hop", extraGw.String())
+			continue
+		}
+		if previous := rw.activeGateway(params.options.Hostname); previous != "" && previous != candidate.String() {
+			params.events.event(rw.instance, corev1.EventTypeNormal, reasonGatewaySwitched, fmt.Sprintf("Route to %s switched from gateway %s to %s on node %s", rw.subnetText(), previous, candidate, params.options.Hostname))
+		}
+		return nil, candidate, probes, nil
+	}
+	return noHealthyGatewayError, nil, probes, nil
+}
+
+// selectNextHops returns the next hops of a multipath route, which can be installed on the node.
+// Gateways which are not directly routable are skipped.
+func selectNextHops(params reconcileImplParams, rw routeWrapper, logger types.Logger) (*reconcile.Result, []staticroutev1.NextHop, error) {
+	if len(rw.instance.Spec.Gateway) != 0 {
+		logger.Error(errors.New("gateway and gateways are mutually exclusive"), rw.instance.Spec.Gateway)
+		return invalidGatewayError, nil, nil
+	}
+	if !rw.isUnicast() {
+		logger.Error(errors.New("only unicast routes can have gateways"), rw.instance.Spec.Type)
+		return invalidGatewayError, nil, nil
+	}
+	if rw.hasInterface() {
+		logger.Error(errors.New("gateways can not be combined with an interface"), rw.instance.Spec.Subnet)
+		return invalidGatewayError, nil, nil
+	}
+	nextHops := []staticroutev1.NextHop{}
+	for _, nextHop := range rw.instance.Spec.Gateways {
+		gateway := net.ParseIP(nextHop.Gateway)
+		if gateway == nil || (gateway.To4() == nil) != rw.isIPv6() {
+			logger.Error(errors.New("invalid gateway found in Spec"), nextHop.Gateway)
+			return invalidGatewayError, nil, nil
+		}
+		extraGw, err := params.options.GetGw(gateway)
 		if err != nil {
-			log.Info("There is something wrong with the node selector", "Value", s)
-			return wrongSelectorErr, nil
+			logger.Error(err, "")
+			return routeGetError, nil, err
 		}
-		selector = selector.Add(*req)
+		if extraGw != nil {
+			logger.Info("Next hop is not directly routable, skipping it", "Gateway", nextHop.Gateway, "Next hop", extraGw.String())
+			continue
+		}
+		nextHops = append(nextHops, *nextHop.DeepCopy())
+	}
+	if len(nextHops) == 0 {
+		return gatewayNotDirectlyRoutableError, nil, nil
+	}
+	return nil, nextHops, nil
+}
+
+// selectInterface resolves the output interface of the route on this node
+func selectInterface(params reconcileImplParams, rw routeWrapper, logger types.Logger) (*reconcile.Result, int, error) {
+	if !rw.isUnicast() {
+		logger.Error(errors.New("only unicast routes can have an interface"), rw.instance.Spec.Type)
+		return invalidInterfaceError, 0, nil
+	}
+	var linkIndex int
+	var err error
+	if len(rw.instance.Spec.Interface) != 0 {
+		if len(rw.instance.Spec.InterfaceAddressIn) != 0 {
+			logger.Error(errors.New("interface and interfaceAddressIn are mutually exclusive"), rw.instance.Spec.Interface)
+			return invalidInterfaceError, 0, nil
+		}
+		linkIndex, err = params.options.GetLinkIndex(rw.instance.Spec.Interface)
+	} else {
+		_, addressIn, perr := net.ParseCIDR(rw.instance.Spec.InterfaceAddressIn)
+		if perr != nil {
+			logger.Error(perr, "Unable to parse interfaceAddressIn")
+			return invalidInterfaceError, 0, nil
+		}
+		linkIndex, err = params.options.GetLinkIndexByAddress(addressIn)
+	}
+	if err != nil {
+		logger.Error(err, "Unable to look up the interface")
+		return linkGetError, 0, err
+	}
+	if linkIndex == 0 {
+		logger.Error(errors.New("interface not found on the node"), rw.instance.Spec.Interface, "AddressIn", rw.instance.Spec.InterfaceAddressIn)
+		return interfaceNotFoundError, 0, nil
+	}
+	return nil, linkIndex, nil
+}
+
+// selectSrc resolves the preferred source address of the route on this node
+func selectSrc(params reconcileImplParams, rw routeWrapper, logger types.Logger) (*reconcile.Result, net.IP, error) {
+	if len(rw.instance.Spec.SrcInterface) == 0 {
+		src := net.ParseIP(rw.instance.Spec.Src)
+		if src == nil || (src.To4() == nil) != rw.isIPv6() {
+			logger.Error(errors.New("invalid src found in Spec"), rw.instance.Spec.Src)
+			return invalidSrcError, nil, nil
+		}
+		return nil, src, nil
+	}
+	if len(rw.instance.Spec.Src) != 0 {
+		logger.Error(errors.New("src and srcInterface are mutually exclusive"), rw.instance.Spec.Src)
+		return invalidSrcError, nil, nil
+	}
+	src, err := params.options.GetLinkAddress(rw.instance.Spec.SrcInterface, rw.isIPv6())
+	if err != nil {
+		logger.Error(err, "Unable to look up the address of the source interface")
+		return linkGetError, nil, err
+	}
+	if src == nil {
+		logger.Error(errors.New("source interface or its address not found on the node"), rw.instance.Spec.SrcInterface)
+		return srcNotFoundError, nil, nil
+	}
+	return nil, src, nil
+}
+
+func validateNodeBySelector(params reconcileImplParams, rw *routeWrapper, logger types.Logger) (*reconcile.Result, error) {
+	nodes := &corev1.NodeList{}
+	allSelector := append([]metav1.LabelSelectorRequirement{}, rw.instance.Spec.Selectors...)
+	allSelector = append(allSelector, node.HostNameRequirement(params.options.Hostname))
+	selector, err := nodeSelector(allSelector)
+	if err != nil {
+		log.Info("There is something wrong with the node selector", "Value", allSelector, "Error", err.Error())
+		return wrongSelectorErr, nil
 	}
 	listOptions := &client.ListOptions{LabelSelector: selector}
 	if err := params.client.List(context.Background(), nodes, listOptions); err != nil {
@@ -370,16 +693,50 @@ func validateNodeBySelector(params reconcileImplParams, rw *routeWrapper, logger
 	return nil, nil
 }
 
-func deleteOperation(params reconcileImplParams, rw *routeWrapper, logger types.Logger) (*reconcile.Result, error) {
-	logger.Info("Deregistering route")
-	err := params.options.RouteManager.DeRegisterRoute(params.request.Name)
-	if err != nil && err != routemanager.ErrNotFound {
-		logger.Error(err, "Unable to deregister route")
-		return deRegisterError, err
+// nodeSelector converts the selector requirements of a StaticRoute into a label selector
+func nodeSelector(requirements []metav1.LabelSelectorRequirement) (labels.Selector, error) {
+	selector := labels.NewSelector()
+	for _, s := range requirements {
+		operator, err := convertToOperator(s.Operator)
+		if err != nil {
+			return nil, err
+		}
+		req, err := labels.NewRequirement(s.Key, operator, s.Values)
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*req)
+	}
+	return selector, nil
+}
+
+// updateSummary recomputes the node counters and the conditions of the StaticRoute, returns true if any of them changed.
+// The number of desired nodes is kept if the nodes cannot be listed.
+func updateSummary(params reconcileImplParams, rw *routeWrapper, logger types.Logger) bool {
+	desiredNodes := rw.instance.Status.DesiredNodes
+	selector, err := nodeSelector(rw.instance.Spec.Selectors)
+	if err == nil {
+		nodes := &corev1.NodeList{}
+		if err = params.client.List(context.Background(), nodes, &client.ListOptions{LabelSelector: selector}); err == nil {
+			desiredNodes = len(nodes.Items)
+		}
+	}
+	if err != nil {
+		logger.Info("Unable to count the selected nodes", "Error", err.Error())
+	}
+	return rw.setSummary(desiredNodes)
+}
+
+func deleteOperation(params reconcileImplParams, rw *routeWrapper, routes []managedRoute, logger types.Logger) (*reconcile.Result, error) {
+	for _, route := range routes {
+		if err := deRegisterOperation(params, rw, route, logger); err != nil {
+			return deRegisterError, err
+		}
 	}
 
+	updateSummary(params, rw, logger)
 	logger.Info("Deleted status for StaticRoute", "status", rw.instance.Status)
-	err = params.client.Status().Update(context.Background(), rw.instance)
+	err := params.client.Status().Update(context.Background(), rw.instance)
 	if err != nil {
 		logger.Error(err, "Unable to update status of CR")
 		return delStatusUpdateError, err
@@ -397,7 +754,66 @@ func deleteOperation(params reconcileImplParams, rw *routeWrapper, logger types.
 	return deletionFinished, nil
 }
 
-func addOperation(params reconcileImplParams, rw *routeWrapper, gateway net.IP, table int, logger types.Logger) (*reconcile.Result, error) {
+// deRegisterOperation removes one route of the StaticRoute, it is not an error if the route is not registered
+func deRegisterOperation(params reconcileImplParams, rw *routeWrapper, route managedRoute, logger types.Logger) error {
+	logger.Info("Deregistering route", "Subnet", route.subnet)
+	err := params.options.RouteManager.DeRegisterRoute(route.name)
+	if err != nil && err != routemanager.ErrNotFound {
+		logger.Error(err, "Unable to deregister route")
+		return err
+	} else if err == nil {
+		params.events.event(rw.instance, corev1.EventTypeNormal, reasonRouteRemoved, fmt.Sprintf("Route to %s removed from node %s", route.subnet, params.options.Hostname))
+	}
+	return nil
+}
+
+// updateOperation replaces the registered route with the new version of the Spec in one step
+func updateOperation(params reconcileImplParams, rw *routeWrapper, gateway net.IP, nextHops []staticroutev1.NextHop, linkIndex int, src net.IP, table int, logger types.Logger) (*reconcile.Result, error) {
+	route, err := newRoute(rw, rw.instance.Spec.Subnet, gateway, nextHops, linkIndex, src, table)
+	if err != nil {
+		logger.Error(err, "Unable to convert the subnet into IP range and mask")
+		return parseSubnetError, nil
+	}
+	logger.Info("Updating route")
+	if err = params.options.RouteManager.UpdateRoute(params.request.Name, route); err != nil {
+		logger.Error(err, "Unable to update route")
+		return updateRouteError, err
+	}
+	params.events.event(rw.instance, corev1.EventTypeNormal, reasonRouteUpdated, fmt.Sprintf("Route to %s updated on node %s", rw.instance.Spec.Subnet, params.options.Hostname))
+	return routeUpdated, nil
+}
+
+// newRoute assembles the route of one subnet of the Spec with the node specific gateway, next hops, interface and source address
+func newRoute(rw *routeWrapper, subnet string, gateway net.IP, nextHops []staticroutev1.NextHop, linkIndex int, src net.IP, table int) (routemanager.Route, error) {
+	_, ipnet, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return routemanager.Route{}, err
+	}
+	route := routemanager.Route{
+		Dst:       *ipnet,
+		Gw:        gateway,
+		Table:     table,
+		LinkIndex: linkIndex,
+		Src:       src,
+		Scope:     routeScope(rw.instance.Spec.Scope),
+		OnLink:    rw.instance.Spec.OnLink,
+		MTU:       rw.instance.Spec.MTU,
+		AdvMSS:    rw.instance.Spec.AdvMSS,
+		InitCwnd:  rw.instance.Spec.InitCwnd,
+		InitRwnd:  rw.instance.Spec.InitRwnd,
+		Hoplimit:  rw.instance.Spec.HopLimit,
+		Type:      routeType(rw.instance.Spec.Type),
+	}
+	if rw.instance.Spec.Metric != nil {
+		route.Priority = int(*rw.instance.Spec.Metric)
+	}
+	for _, nextHop := range nextHops {
+		route.MultiPath = append(route.MultiPath, routemanager.NextHop{Gw: net.ParseIP(nextHop.Gateway), Weight: nextHopWeight(nextHop)})
+	}
+	return route, nil
+}
+
+func addOperation(params reconcileImplParams, rw *routeWrapper, gateway net.IP, nextHops []staticroutev1.NextHop, linkIndex int, src net.IP, table int, logger types.Logger) (*reconcile.Result, error) {
 	if rw.setFinalizer() {
 		logger.Info("Adding Finalizer for the StaticRoute")
 		if err := params.client.Update(context.Background(), rw.instance); err != nil {
@@ -410,22 +826,111 @@ func addOperation(params reconcileImplParams, rw *routeWrapper, gateway net.IP,
 		    This also runs if the CR was asked for deletion, but the operator did not run meanwhile.
 			In this case the route is still programmed to the kernel, so we register the route here
 			in order to successfully deregister and remove it from the kernel below */
-		_, ipnet, err := net.ParseCIDR(rw.instance.Spec.Subnet)
+		route, err := newRoute(rw, rw.instance.Spec.Subnet, gateway, nextHops, linkIndex, src, table)
 		if err != nil {
 			logger.Error(err, "Unable to convert the subnet into IP range and mask")
 			return parseSubnetError, nil
 		}
 		logger.Info("Registering route")
 
-		err = params.options.RouteManager.RegisterRoute(params.request.Name, routemanager.Route{Dst: *ipnet, Gw: gateway, Table: table})
+		err = params.options.RouteManager.RegisterRoute(params.request.Name, route)
 		if err != nil {
 			logger.Error(err, "Unable to register route")
 			return registerRouteError, err
 		}
+		params.events.event(rw.instance, corev1.EventTypeNormal, reasonRouteInstalled, fmt.Sprintf("Route to %s installed on node %s", rw.instance.Spec.Subnet, params.options.Hostname))
 	}
 	return finished, nil
 }
 
+// subnetsOperation installs the routes of a StaticRoute with multiple subnets one by one. The subnets which are forbidden
+// by the policies or fail to install are reported in the subnet statuses, they do not block the other subnets.
+func subnetsOperation(params reconcileImplParams, rw *routeWrapper, gateway net.IP, nextHops []staticroutev1.NextHop, linkIndex int, src net.IP, table int, changed bool, logger types.Logger) (*reconcile.Result, []staticroutev1.SubnetStatus, error) {
+	protected, allowed, err := policySubnets(params, logger)
+	if err != nil {
+		logger.Error(err, "Failed to fetch StaticRoutePolicies")
+		return policyGetError, nil, err
+	}
+	if rw.setFinalizer() {
+		logger.Info("Adding Finalizer for the StaticRoute")
+		if err := params.client.Update(context.Background(), rw.instance); err != nil {
+			logger.Error(err, "Failed to update StaticRoute with finalizer")
+			return setFinalizerError, nil, err
+		}
+	}
+	if changed {
+		// The subnets removed from the Spec (or the single subnet of the previous version) are not needed anymore
+		desired := map[string]bool{}
+		for _, subnet := range rw.subnets() {
+			desired[routeKey(params.request.Name, subnet)] = true
+		}
+		for _, route := range rw.managedRoutes(params.request.Name, params.options.Hostname) {
+			if desired[route.name] {
+				continue
+			}
+			if err := deRegisterOperation(params, rw, route, logger); err != nil {
+				return deRegisterError, nil, err
+			}
+		}
+	}
+
+	res := finished
+	var resErr error
+	statuses := []staticroutev1.SubnetStatus{}
+	for _, subnet := range rw.subnets() {
+		status := staticroutev1.SubnetStatus{Subnet: subnet}
+		name := routeKey(params.request.Name, subnet)
+		registered := params.options.RouteManager.IsRegistered(name)
+		if reason, perr := subnetPolicyError(subnet, protected, allowed); perr != nil {
+			logger.Info("Error: subnet is forbidden by the policy", "Subnet", subnet)
+			status.Error = perr.Error()
+			params.events.event(rw.instance, corev1.EventTypeWarning, reason, fmt.Sprintf("Subnet %s is forbidden by the policy", subnet))
+			// The policy may have changed since the route was installed
+			if registered {
+				if err := deRegisterOperation(params, rw, managedRoute{name: name, subnet: subnet}, logger); err != nil {
+					res, resErr = deRegisterError, err
+				}
+			}
+		} else if !registered || changed {
+			if err := installSubnet(params, rw, name, subnet, registered, gateway, nextHops, linkIndex, src, table, logger); err != nil {
+				status.Error = err.Error()
+				res, resErr = registerRouteError, err
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return res, statuses, resErr
+}
+
+// installSubnet registers the route of one subnet, or updates it if it is registered already.
+// If the update fails the route is re-created.
+func installSubnet(params reconcileImplParams, rw *routeWrapper, name, subnet string, registered bool, gateway net.IP, nextHops []staticroutev1.NextHop, linkIndex int, src net.IP, table int, logger types.Logger) error {
+	route, err := newRoute(rw, subnet, gateway, nextHops, linkIndex, src, table)
+	if err != nil {
+		logger.Error(err, "Unable to convert the subnet into IP range and mask", "Subnet", subnet)
+		return err
+	}
+	if registered {
+		logger.Info("Updating route", "Subnet", subnet)
+		if err = params.options.RouteManager.UpdateRoute(name, route); err == nil {
+			params.events.event(rw.instance, corev1.EventTypeNormal, reasonRouteUpdated, fmt.Sprintf("Route to %s updated on node %s", subnet, params.options.Hostname))
+			return nil
+		}
+		logger.Info("Unable to update the route in place, re-creating it", "Subnet", subnet, "Error", err.Error())
+		if err = params.options.RouteManager.DeRegisterRoute(name); err != nil && err != routemanager.ErrNotFound {
+			logger.Error(err, "Unable to deregister route", "Subnet", subnet)
+			return err
+		}
+	}
+	logger.Info("Registering route", "Subnet", subnet)
+	if err = params.options.RouteManager.RegisterRoute(name, route); err != nil {
+		logger.Error(err, "Unable to register route", "Subnet", subnet)
+		return err
+	}
+	params.events.event(rw.instance, corev1.EventTypeNormal, reasonRouteInstalled, fmt.Sprintf("Route to %s installed on node %s", subnet, params.options.Hostname))
+	return nil
+}
+
 func convertToOperator(operator metav1.LabelSelectorOperator) (selection.Operator, error) {
 	switch operator {
 	case metav1.LabelSelectorOpIn:
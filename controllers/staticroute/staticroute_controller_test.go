@@ -20,11 +20,19 @@ import (
 	"context"
 	"errors"
 	"net"
+	"reflect"
+	"slices"
 	"testing"
 	"time"
 
 	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/pkg/cidr"
 	"github.com/IBM/staticroute-operator/pkg/routemanager"
+	nlfake "github.com/IBM/staticroute-operator/pkg/routemanager/fake"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
@@ -107,7 +115,7 @@ func TestReconcileImplCRGetNotFound(t *testing.T) {
 
 func TestReconcileImplProtected(t *testing.T) {
 	params, _ := getReconcileContextForAddFlow(nil, true, false)
-	params.options.ProtectedSubnets = []*net.IPNet{&net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.IPv4Mask(0xff, 0, 0, 0)}}
+	params.options.ProtectedSubnets = cidr.NewSet(&net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.IPv4Mask(0xff, 0, 0, 0)})
 
 	res, err := reconcileImpl(*params)
 
@@ -119,6 +127,27 @@ func TestReconcileImplProtected(t *testing.T) {
 	}
 }
 
+func TestReconcileImplProtectedBlackholeReportsNoGateway(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Type = staticroutev1.RouteTypeBlackhole
+	params, mockClient := getReconcileContextForAddFlow(route, true, false)
+	params.options.ProtectedSubnets = cidr.NewSet(&net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.IPv4Mask(0xff, 0, 0, 0)})
+
+	res, _ := reconcileImpl(*params)
+
+	if res != overlapsProtected {
+		t.Error("Result must be overlapsProtected")
+	}
+	instance := &staticroutev1.StaticRoute{}
+	if err := mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Fatalf("Failed to read the CR: %s", err.Error())
+	}
+	// Only unicast routes can have a gateway, in the state as well
+	if len(instance.Status.NodeStatus) != 1 || instance.Status.NodeStatus[0].State.Gateway != "" || instance.Status.NodeStatus[0].Error == "" {
+		t.Errorf("Error must be reported without a gateway: %+v", instance.Status.NodeStatus)
+	}
+}
+
 func TestReconcileImplNotDeleted(t *testing.T) {
 	route := newStaticRouteWithValues(true, false)
 	params, _ := getReconcileContextForAddFlow(route, true, true)
@@ -146,7 +175,63 @@ func TestReconcileImplUpdated(t *testing.T) {
 			},
 		},
 	}
+	params, mockClient := getReconcileContextForAddFlow(route, true, false)
+	var updatedRoute routemanager.Route
+	params.options.RouteManager = routeManagerMock{
+		isRegistered: true,
+		updatedCallback: func(n string, r routemanager.Route) error {
+			updatedRoute = r
+			return nil
+		},
+		deRegisteredCallback: func(string) error {
+			t.Error("Route must be updated in place, not deregistered")
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != routeUpdated {
+		t.Error("Result must be routeUpdated")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if updatedRoute.Dst.String() != "10.0.0.0/16" {
+		t.Errorf("Route must be updated to the new subnet: %+v", updatedRoute)
+	}
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Errorf("Failed to read the CR: %s", err.Error())
+	}
+	if len(instance.Status.NodeStatus) != 1 || instance.Status.NodeStatus[0].State.Subnet != "10.0.0.1/16" {
+		t.Errorf("Status must report the new state: %+v", instance.Status.NodeStatus)
+	}
+}
+
+func TestReconcileImplUpdateFailsFallsBackToReCreate(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Status = staticroutev1.StaticRouteStatus{
+		NodeStatus: []staticroutev1.StaticRouteNodeStatus{
+			staticroutev1.StaticRouteNodeStatus{
+				Hostname: "hostname",
+				State: staticroutev1.StaticRouteSpec{
+					Subnet:  "10.0.0.1/16",
+					Gateway: "10.0.0.2",
+				},
+			},
+		},
+	}
 	params, _ := getReconcileContextForAddFlow(route, true, false)
+	deRegistered := false
+	params.options.RouteManager = routeManagerMock{
+		isRegistered:   true,
+		updateRouteErr: errors.New("Couldn't update route"),
+		deRegisteredCallback: func(string) error {
+			deRegistered = true
+			return nil
+		},
+	}
 
 	res, err := reconcileImpl(*params)
 
@@ -156,9 +241,12 @@ func TestReconcileImplUpdated(t *testing.T) {
 	if err != nil {
 		t.Errorf("Error must be nil: %s", err.Error())
 	}
+	if !deRegistered {
+		t.Error("Route must be deregistered to be re-created")
+	}
 }
 
-func TestReconcileImplUpdatedDoubleReconcile(t *testing.T) {
+func TestReconcileImplUpdatedNotRegistered(t *testing.T) {
 	route := newStaticRouteWithValues(true, false)
 	route.Status = staticroutev1.StaticRouteStatus{
 		NodeStatus: []staticroutev1.StaticRouteNodeStatus{
@@ -171,7 +259,13 @@ func TestReconcileImplUpdatedDoubleReconcile(t *testing.T) {
 			},
 		},
 	}
-	params, mock := getReconcileContextForDoubleReconcile(route, true)
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.RouteManager = routeManagerMock{
+		updatedCallback: func(string, routemanager.Route) error {
+			t.Error("Route which is not registered must not be updated")
+			return nil
+		},
+	}
 
 	res, err := reconcileImpl(*params)
 
@@ -181,6 +275,34 @@ func TestReconcileImplUpdatedDoubleReconcile(t *testing.T) {
 	if err != nil {
 		t.Errorf("Error must be nil: %s", err.Error())
 	}
+}
+
+func TestReconcileImplUpdatedDoubleReconcile(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Status = staticroutev1.StaticRouteStatus{
+		NodeStatus: []staticroutev1.StaticRouteNodeStatus{
+			staticroutev1.StaticRouteNodeStatus{
+				Hostname: "hostname",
+				State: staticroutev1.StaticRouteSpec{
+					Subnet:  "11.1.1.1/16",
+					Gateway: "10.0.0.1",
+				},
+			},
+		},
+	}
+	params, mock := getReconcileContextForDoubleReconcile(route, true)
+
+	res, err := reconcileImpl(*params)
+
+	if res != routeUpdated {
+		t.Error("Result must be routeUpdated")
+	}
+	if mock.statusWriteMock.(*statusWriterMock).updateCounter != 1 {
+		t.Errorf("Status must be updated once: %d", mock.statusWriteMock.(*statusWriterMock).updateCounter)
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
 
 	res, err = reconcileImpl(*params)
 	if res != finished {
@@ -306,6 +428,99 @@ func TestReconcileImplUpdateStatus(t *testing.T) {
 	}
 }
 
+func TestReconcileImplTamperedRecordedInStatus(t *testing.T) {
+	route := newStaticRouteWithValues(true, true)
+	params, mockClient := getReconcileContextForAddFlow(route, false, false)
+	tampered := metav1.Now()
+	params.tampered = &tampered
+
+	res, err := reconcileImpl(*params)
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+
+	params.tampered = &tampered
+	//nolint:errcheck
+	reconcileImpl(*params)
+
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Errorf("Failed to read the CR: %s", err.Error())
+	}
+	if instance.Status.NodeStatus[0].TamperCount != 2 {
+		t.Errorf("Tamper count must be 2: %d", instance.Status.NodeStatus[0].TamperCount)
+	}
+	if instance.Status.NodeStatus[0].TamperedAt == nil {
+		t.Error("Tamper time must be set")
+	}
+}
+
+func TestReconcileImplSummary(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Generation = 2
+	fakeClient := newFakeClient(route)
+	for _, name := range []string{"hostname", "hostname2"} {
+		if err := fakeClient.Create(context.Background(), &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}); err != nil {
+			t.Fatalf("Failed to create node: %s", err.Error())
+		}
+	}
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	mockClient := &reconcileImplClientMock{client: fakeClient}
+	params.client = mockClient
+
+	res, err := reconcileImpl(*params)
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Errorf("Failed to read the CR: %s", err.Error())
+	}
+	status := instance.Status
+	if status.ObservedGeneration != 2 || status.DesiredNodes != 2 || status.AppliedNodes != 1 || status.FailedNodes != 0 {
+		t.Errorf("Counters are wrong: %v", status)
+	}
+	if !meta.IsStatusConditionTrue(status.Conditions, staticroutev1.ConditionProgressing) || meta.IsStatusConditionTrue(status.Conditions, staticroutev1.ConditionReady) {
+		t.Errorf("Route must be progressing: %v", status.Conditions)
+	}
+}
+
+func TestReconcileImplSummaryNodesBehind(t *testing.T) {
+	route := newStaticRouteWithValues(true, true)
+	route.Generation = 3
+	route.Status.ObservedGeneration = 2
+	route.Status.NodeStatus[0].ObservedGeneration = 2
+	route.Status.NodeStatus = append(route.Status.NodeStatus, staticroutev1.StaticRouteNodeStatus{Hostname: "hostname2", ObservedGeneration: 2})
+	fakeClient := newFakeClient(route)
+	params, _ := getReconcileContextForAddFlow(route, true, false)
+	mockClient := &reconcileImplClientMock{client: fakeClient}
+	params.client = mockClient
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished || err != nil {
+		t.Fatalf("Result must be finished: %v %v", res, err)
+	}
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Fatalf("Failed to read the CR: %s", err.Error())
+	}
+	status := instance.Status
+	if len(status.NodeStatus) != 2 || status.NodeStatus[0].Hostname != "hostname" || status.NodeStatus[0].ObservedGeneration != 3 {
+		t.Errorf("Node must report the current generation: %v", status.NodeStatus)
+	}
+	if status.ObservedGeneration != 2 || meta.IsStatusConditionTrue(status.Conditions, staticroutev1.ConditionReady) {
+		t.Errorf("Summary must wait for the other node: %v", status)
+	}
+}
+
 func TestReconcileImplNodeSelectorFatalError(t *testing.T) {
 	route := newStaticRouteWithValues(true, false)
 	route.Spec.Selectors = []metav1.LabelSelectorRequirement{metav1.LabelSelectorRequirement{
@@ -540,83 +755,1193 @@ func TestReconcileImplDetermineGateway(t *testing.T) {
 	}
 }
 
-func TestReconcileImplGatewayNotDirectlyRoutable(t *testing.T) {
+func TestReconcileImplMetric(t *testing.T) {
+	var priorityParam int
+	metric := int64(100)
+
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Metric = &metric
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.RouteManager = routeManagerMock{
+		registeredCallback: func(n string, r routemanager.Route) error {
+			priorityParam = r.Priority
+			return nil
+		},
+	}
+
+	//nolint:errcheck
+	reconcileImpl(*params)
+
+	if priorityParam != 100 {
+		t.Errorf("Metric must be used as priority: %d", priorityParam)
+	}
+}
+
+func TestReconcileImplDetermineGatewayIPv6(t *testing.T) {
+	var fallbackParam, gatewayParam string
+
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Subnet = "fd00:1::/64"
+	route.Spec.Gateway = ""
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.FallbackIPForGwSelection = net.IP{10, 0, 0, 1}
+	params.options.FallbackIPv6ForGwSelection = net.ParseIP("fd00::1")
+	params.options.GetGw = func(ip net.IP) (net.IP, error) {
+		fallbackParam = ip.String()
+		return net.ParseIP("fd00::fe"), nil
+	}
+	params.options.RouteManager = routeManagerMock{
+		isRegistered: false,
+		registeredCallback: func(n string, r routemanager.Route) error {
+			gatewayParam = r.Gw.String()
+			return nil
+		},
+	}
+
+	//nolint:errcheck
+	reconcileImpl(*params)
+
+	if fallbackParam != "fd00::1" {
+		t.Errorf("Wrong fallback IP used: %s", fallbackParam)
+	}
+	if gatewayParam != "fd00::fe" {
+		t.Errorf("Wrong gateway selected: %s", gatewayParam)
+	}
+}
+
+func TestReconcileImplMissingIPv6FallbackIP(t *testing.T) {
 	route := newStaticRouteWithValues(true, true)
-	route.Spec.Gateway = "10.0.10.1"
+	route.Spec.Subnet = "fd00:1::/64"
+	route.Spec.Gateway = ""
 	params, _ := getReconcileContextForAddFlow(route, true, false)
-	params.options.GetGw = func(net.IP) (net.IP, error) {
-		return net.IP{10, 0, 0, 1}, nil
-	}
+	params.options.FallbackIPForGwSelection = net.IP{10, 0, 0, 1}
 
 	res, err := reconcileImpl(*params)
 
-	if res != gatewayNotDirectlyRoutableError {
-		t.Error("Result must be gatewayNotDirectlyRoutableError")
+	if res != missingFallbackIPError {
+		t.Error("Result must be missingFallbackIPError")
 	}
 	if err != nil {
 		t.Errorf("Error must be nil: %s", err.Error())
 	}
 }
 
-func TestReconcileImplDeletingWhileGatewayNotDirectlyRoutable(t *testing.T) {
+func TestReconcileImplGatewayFamilyMismatch(t *testing.T) {
 	route := newStaticRouteWithValues(true, true)
-	route.Spec.Gateway = "10.0.10.1"
-	params, _ := getReconcileContextForAddFlow(route, true, true)
-	params.options.GetGw = func(net.IP) (net.IP, error) {
-		return net.IP{10, 0, 0, 1}, nil
-	}
+	route.Spec.Subnet = "fd00:1::/64"
+	params, _ := getReconcileContextForAddFlow(route, true, false)
 
 	res, err := reconcileImpl(*params)
 
-	if res != deletionFinished {
-		t.Error("Result must be deletionFinished")
+	if res != invalidGatewayError {
+		t.Error("Result must be invalidGatewayError")
 	}
 	if err != nil {
 		t.Errorf("Error must be nil: %s", err.Error())
 	}
 }
 
-func getReconcileContextForAddFlow(route *staticroutev1.StaticRoute, isRegistered bool, isDeleting bool) (*reconcileImplParams, *reconcileImplClientMock) {
-	if route == nil {
-		route = newStaticRouteWithValues(true, true)
-	}
+func TestReconcileImplMultiPath(t *testing.T) {
+	var registered routemanager.Route
+	weight := 3
 
-	if isDeleting {
-		route.DeletionTimestamp = &metav1.Time{Time: time.Date(2021, time.Month(2), 21, 1, 10, 30, 0, time.UTC)}
-		route.Finalizers = append(route.Finalizers, "finalizer.static-route.ibm.com")
-	}
-	mockClient := reconcileImplClientMock{
-		client: newFakeClient(route),
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Gateway = ""
+	route.Spec.Gateways = []staticroutev1.NextHop{{Gateway: "10.0.0.1"}, {Gateway: "10.0.10.1"}, {Gateway: "10.0.0.2", Weight: &weight}}
+	params, mockClient := getReconcileContextForAddFlow(route, false, false)
+	params.options.GetGw = func(ip net.IP) (net.IP, error) {
+		if ip.Equal(net.IP{10, 0, 10, 1}) {
+			return net.IP{10, 0, 0, 1}, nil
+		}
+		return nil, nil
 	}
-	params := newReconcileImplParams(&mockClient)
-	params.options.Hostname = "hostname"
 	params.options.RouteManager = routeManagerMock{
-		isRegistered: isRegistered,
-	}
-	params.options.GetGw = func(net.IP) (net.IP, error) {
-		return nil, nil
+		registeredCallback: func(n string, r routemanager.Route) error {
+			registered = r
+			return nil
+		},
 	}
 
-	return params, &mockClient
-}
+	res, err := reconcileImpl(*params)
 
-func getReconcileContextForDoubleReconcile(route *staticroutev1.StaticRoute, isRegistered bool) (*reconcileImplParams, *reconcileImplClientMock) {
-	if route == nil {
-		route = newStaticRouteWithValues(true, true)
+	if res != finished {
+		t.Error("Result must be finished")
 	}
-	client := newFakeClient(route)
-	mockClient := reconcileImplClientMock{
-		client:          client,
-		statusWriteMock: &statusWriterMock{client: client},
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
 	}
-	params := newReconcileImplParams(&mockClient)
-	params.options.Hostname = "hostname"
-	params.options.RouteManager = routeManagerMock{
-		isRegistered: isRegistered,
+	if registered.Gw != nil || len(registered.MultiPath) != 2 {
+		t.Fatalf("Multipath route must be registered with the directly routable next hops: %v", registered)
+	}
+	if !registered.MultiPath[1].Gw.Equal(net.IP{10, 0, 0, 2}) || registered.MultiPath[1].Weight != 3 {
+		t.Errorf("Weight of the next hop is wrong: %v", registered.MultiPath[1])
+	}
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Errorf("Failed to read the CR: %s", err.Error())
 	}
+	if len(instance.Status.NodeStatus[0].State.Gateways) != 2 {
+		t.Errorf("Installed next hops must be reported in the status: %v", instance.Status.NodeStatus[0].State.Gateways)
+	}
+}
+
+func TestReconcileImplMultiPathNotDirectlyRoutable(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Gateway = ""
+	route.Spec.Gateways = []staticroutev1.NextHop{{Gateway: "10.0.10.1"}}
+	params, _ := getReconcileContextForAddFlow(route, false, false)
 	params.options.GetGw = func(net.IP) (net.IP, error) {
-		return nil, nil
+		return net.IP{10, 0, 0, 1}, nil
 	}
 
-	return params, &mockClient
+	res, err := reconcileImpl(*params)
+
+	if res != gatewayNotDirectlyRoutableError {
+		t.Error("Result must be gatewayNotDirectlyRoutableError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplMultiPathWithGateway(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Gateways = []staticroutev1.NextHop{{Gateway: "10.0.0.2"}}
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+
+	res, err := reconcileImpl(*params)
+
+	if res != invalidGatewayError {
+		t.Error("Result must be invalidGatewayError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func newStaticRouteWithCandidates() *staticroutev1.StaticRoute {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Gateway = ""
+	route.Spec.CandidateGateways = []string{"10.0.0.1", "10.0.0.2"}
+	route.Spec.Probe = &staticroutev1.GatewayProbe{Type: staticroutev1.ProbeTypeICMP, PeriodSeconds: 3600, FailureThreshold: 1}
+	return route
+}
+
+func newProberWithUnhealthy(t *testing.T, route *staticroutev1.StaticRoute, unhealthy ...string) *gatewayProber {
+	prober := newGatewayProber(func(_ string, gateway net.IP, _ int, _ time.Duration) error {
+		if slices.Contains(unhealthy, gateway.String()) {
+			return errors.New("no route to host")
+		}
+		return nil
+	})
+	t.Cleanup(func() { prober.forget(route.Name) })
+	candidates := []net.IP{}
+	for _, candidate := range route.Spec.CandidateGateways {
+		candidates = append(candidates, net.ParseIP(candidate))
+	}
+	prober.watch(route.Name, candidates, *route.Spec.Probe)
+	prober.probeAll(prober.targets[route.Name])
+	return prober
+}
+
+func TestReconcileImplCandidateGateways(t *testing.T) {
+	var registered routemanager.Route
+
+	route := newStaticRouteWithCandidates()
+	params, mockClient := getReconcileContextForAddFlow(route, false, false)
+	params.options.RouteManager = routeManagerMock{
+		registeredCallback: func(n string, r routemanager.Route) error {
+			registered = r
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if !registered.Gw.Equal(net.IP{10, 0, 0, 1}) {
+		t.Errorf("Route must be registered through the first candidate: %+v", registered)
+	}
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Errorf("Failed to read the CR: %s", err.Error())
+	}
+	nodeStatus := instance.Status.NodeStatus[0]
+	if nodeStatus.ActiveGateway != "10.0.0.1" || len(nodeStatus.GatewayProbes) != 2 {
+		t.Errorf("Active gateway and probes must be reported in the status: %+v", nodeStatus)
+	}
+	// candidateGateways and gateway are mutually exclusive in the state as well
+	if nodeStatus.State.Gateway != "" {
+		t.Errorf("Active gateway must not be reported as the gateway of the state: %+v", nodeStatus.State)
+	}
+}
+
+func TestReconcileImplCandidateGatewayFailover(t *testing.T) {
+	var updated routemanager.Route
+
+	route := newStaticRouteWithCandidates()
+	route.Status.NodeStatus = []staticroutev1.StaticRouteNodeStatus{{
+		Hostname:      "hostname",
+		State:         *route.Spec.DeepCopy(),
+		ActiveGateway: "10.0.0.1",
+	}}
+	params, mockClient := getReconcileContextForAddFlow(route, true, false)
+	params.prober = newProberWithUnhealthy(t, route, "10.0.0.1")
+	params.options.RouteManager = routeManagerMock{
+		isRegistered: true,
+		updatedCallback: func(n string, r routemanager.Route) error {
+			updated = r
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != routeUpdated {
+		t.Error("Result must be routeUpdated")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if !updated.Gw.Equal(net.IP{10, 0, 0, 2}) {
+		t.Errorf("Route must be switched to the healthy candidate: %+v", updated)
+	}
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Errorf("Failed to read the CR: %s", err.Error())
+	}
+	nodeStatus := instance.Status.NodeStatus[0]
+	if nodeStatus.ActiveGateway != "10.0.0.2" || nodeStatus.GatewayProbes[0].Healthy || nodeStatus.GatewayProbes[0].Message != "no route to host" {
+		t.Errorf("Failover must be reported in the status: %+v", nodeStatus)
+	}
+}
+
+func TestReconcileImplCandidateGatewayNotDirectlyRoutable(t *testing.T) {
+	var registered routemanager.Route
+
+	route := newStaticRouteWithCandidates()
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.GetGw = func(ip net.IP) (net.IP, error) {
+		if ip.Equal(net.IP{10, 0, 0, 1}) {
+			return net.IP{10, 0, 0, 254}, nil
+		}
+		return nil, nil
+	}
+	params.options.RouteManager = routeManagerMock{
+		registeredCallback: func(n string, r routemanager.Route) error {
+			registered = r
+			return nil
+		},
+	}
+
+	res, _ := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if !registered.Gw.Equal(net.IP{10, 0, 0, 2}) {
+		t.Errorf("Route must be registered through the directly routable candidate: %+v", registered)
+	}
+}
+
+func TestReconcileImplNoHealthyCandidateGateway(t *testing.T) {
+	route := newStaticRouteWithCandidates()
+	params, mockClient := getReconcileContextForAddFlow(route, false, false)
+	params.prober = newProberWithUnhealthy(t, route, "10.0.0.1", "10.0.0.2")
+	params.options.RouteManager = routeManagerMock{
+		registeredCallback: func(string, routemanager.Route) error {
+			t.Error("Route must not be registered without a healthy gateway")
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != noHealthyGatewayError {
+		t.Error("Result must be noHealthyGatewayError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Errorf("Failed to read the CR: %s", err.Error())
+	}
+	if len(instance.Status.NodeStatus) != 1 || instance.Status.NodeStatus[0].Error == "" || len(instance.Status.NodeStatus[0].GatewayProbes) != 2 {
+		t.Errorf("Error and probes must be reported in the status: %+v", instance.Status.NodeStatus)
+	}
+}
+
+func TestReconcileImplCandidateGatewaysWithGateway(t *testing.T) {
+	route := newStaticRouteWithCandidates()
+	route.Spec.Gateway = "10.0.0.3"
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+
+	res, err := reconcileImpl(*params)
+
+	if res != invalidGatewayError {
+		t.Error("Result must be invalidGatewayError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplBlackhole(t *testing.T) {
+	var routeParam routemanager.Route
+
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Gateway = ""
+	route.Spec.Type = staticroutev1.RouteTypeBlackhole
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.GetGw = func(net.IP) (net.IP, error) {
+		t.Error("Gateway must not be selected for blackhole routes")
+		return nil, nil
+	}
+	params.options.RouteManager = routeManagerMock{
+		registeredCallback: func(n string, r routemanager.Route) error {
+			routeParam = r
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if routeParam.Type != unix.RTN_BLACKHOLE || routeParam.Gw != nil {
+		t.Errorf("Blackhole route must be registered without gateway: %+v", routeParam)
+	}
+}
+
+func TestReconcileImplBlackholeWithGateway(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Type = staticroutev1.RouteTypeBlackhole
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+
+	res, err := reconcileImpl(*params)
+
+	if res != invalidGatewayError {
+		t.Error("Result must be invalidGatewayError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplUnreachableWithGateways(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Gateway = ""
+	route.Spec.Gateways = []staticroutev1.NextHop{{Gateway: "10.0.0.2"}}
+	route.Spec.Type = staticroutev1.RouteTypeUnreachable
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+
+	res, err := reconcileImpl(*params)
+
+	if res != invalidGatewayError {
+		t.Error("Result must be invalidGatewayError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplDeviceRoute(t *testing.T) {
+	var routeParam routemanager.Route
+	var interfaceParam string
+
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Gateway = ""
+	route.Spec.Interface = "eth1"
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.GetGw = func(net.IP) (net.IP, error) {
+		t.Error("Gateway must not be selected for device routes")
+		return nil, nil
+	}
+	params.options.GetLinkIndex = func(name string) (int, error) {
+		interfaceParam = name
+		return 3, nil
+	}
+	params.options.RouteManager = routeManagerMock{
+		registeredCallback: func(n string, r routemanager.Route) error {
+			routeParam = r
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if interfaceParam != "eth1" {
+		t.Errorf("Interface must be looked up by name: %s", interfaceParam)
+	}
+	if routeParam.LinkIndex != 3 || routeParam.Gw != nil {
+		t.Errorf("Device route must be registered without gateway: %+v", routeParam)
+	}
+}
+
+func TestReconcileImplGatewayWithInterfaceAddressIn(t *testing.T) {
+	var routeParam routemanager.Route
+	var addressInParam string
+
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.InterfaceAddressIn = "10.0.0.0/8"
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.GetLinkIndexByAddress = func(subnet *net.IPNet) (int, error) {
+		addressInParam = subnet.String()
+		return 4, nil
+	}
+	params.options.RouteManager = routeManagerMock{
+		registeredCallback: func(n string, r routemanager.Route) error {
+			routeParam = r
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if addressInParam != "10.0.0.0/8" {
+		t.Errorf("Interface must be looked up by address: %s", addressInParam)
+	}
+	if routeParam.LinkIndex != 4 || routeParam.Gw.String() != "10.0.0.1" {
+		t.Errorf("Route must be registered with gateway and interface: %+v", routeParam)
+	}
+}
+
+func TestReconcileImplInterfaceNotFound(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Gateway = ""
+	route.Spec.Interface = "eth1"
+	params, mockClient := getReconcileContextForAddFlow(route, false, false)
+	params.options.GetLinkIndex = func(string) (int, error) {
+		return 0, nil
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != interfaceNotFoundError {
+		t.Error("Result must be interfaceNotFoundError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Errorf("Failed to read the CR: %s", err.Error())
+	}
+	if len(instance.Status.NodeStatus) != 1 || instance.Status.NodeStatus[0].Error != "given interface is not found on the node, cannot setup the route" {
+		t.Errorf("Status must report the missing interface: %+v", instance.Status.NodeStatus)
+	}
+}
+
+func TestReconcileImplCantGetInterface(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Interface = "eth1"
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.GetLinkIndex = func(string) (int, error) {
+		return 0, errors.New("Can't list interfaces")
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != linkGetError {
+		t.Error("Result must be linkGetError")
+	}
+	if err == nil {
+		t.Error("Error must be not nil")
+	}
+}
+
+func TestReconcileImplMultiPathWithInterface(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Gateway = ""
+	route.Spec.Gateways = []staticroutev1.NextHop{{Gateway: "10.0.0.2"}}
+	route.Spec.Interface = "eth1"
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.GetLinkIndex = func(string) (int, error) {
+		return 3, nil
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != invalidGatewayError {
+		t.Error("Result must be invalidGatewayError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplBlackholeWithInterface(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Gateway = ""
+	route.Spec.Type = staticroutev1.RouteTypeBlackhole
+	route.Spec.Interface = "eth1"
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+
+	res, err := reconcileImpl(*params)
+
+	if res != invalidInterfaceError {
+		t.Error("Result must be invalidInterfaceError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplSrcScope(t *testing.T) {
+	var routeParam routemanager.Route
+
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Src = "10.0.0.5"
+	route.Spec.Scope = staticroutev1.ScopeLink
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.RouteManager = routeManagerMock{
+		registeredCallback: func(n string, r routemanager.Route) error {
+			routeParam = r
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if routeParam.Src.String() != "10.0.0.5" || routeParam.Scope != unix.RT_SCOPE_LINK {
+		t.Errorf("Src and scope must be registered: %+v", routeParam)
+	}
+}
+
+func TestReconcileImplRouteMetrics(t *testing.T) {
+	var routeParam routemanager.Route
+
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.MTU = 1400
+	route.Spec.AdvMSS = 1360
+	route.Spec.InitCwnd = 10
+	route.Spec.InitRwnd = 20
+	route.Spec.HopLimit = 32
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.RouteManager = routeManagerMock{
+		registeredCallback: func(n string, r routemanager.Route) error {
+			routeParam = r
+			return nil
+		},
+	}
+
+	//nolint:errcheck
+	reconcileImpl(*params)
+
+	if routeParam.MTU != 1400 || routeParam.AdvMSS != 1360 || routeParam.InitCwnd != 10 || routeParam.InitRwnd != 20 || routeParam.Hoplimit != 32 {
+		t.Errorf("Route metrics must be registered: %+v", routeParam)
+	}
+}
+
+func TestReconcileImplIPv6ScopeNotUniverse(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Subnet = "fd00:10::/64"
+	route.Spec.Gateway = "fd00::1"
+	route.Spec.Scope = staticroutev1.ScopeLink
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+
+	res, err := reconcileImpl(*params)
+
+	if res != invalidScopeError {
+		t.Error("Result must be invalidScopeError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplSrcFamilyMismatch(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Src = "fd00::5"
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+
+	res, err := reconcileImpl(*params)
+
+	if res != invalidSrcError {
+		t.Error("Result must be invalidSrcError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplSrcInterface(t *testing.T) {
+	var routeParam routemanager.Route
+	var interfaceParam string
+	var ipv6Param bool
+
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.SrcInterface = "tun0"
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.GetLinkAddress = func(name string, ipv6 bool) (net.IP, error) {
+		interfaceParam, ipv6Param = name, ipv6
+		return net.IP{10, 8, 0, 1}, nil
+	}
+	params.options.RouteManager = routeManagerMock{
+		registeredCallback: func(n string, r routemanager.Route) error {
+			routeParam = r
+			return nil
+		},
+	}
+
+	//nolint:errcheck
+	reconcileImpl(*params)
+
+	if interfaceParam != "tun0" || ipv6Param {
+		t.Errorf("IPv4 address of the source interface must be looked up: %s %t", interfaceParam, ipv6Param)
+	}
+	if routeParam.Src.String() != "10.8.0.1" {
+		t.Errorf("Address of the source interface must be registered: %+v", routeParam)
+	}
+}
+
+func TestReconcileImplSrcInterfaceNotFound(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.SrcInterface = "tun0"
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.GetLinkAddress = func(string, bool) (net.IP, error) {
+		return nil, nil
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != srcNotFoundError {
+		t.Error("Result must be srcNotFoundError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplOnLink(t *testing.T) {
+	var routeParam routemanager.Route
+
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Gateway = "192.168.100.1"
+	route.Spec.Interface = "eth1"
+	route.Spec.OnLink = true
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.GetGw = func(net.IP) (net.IP, error) {
+		t.Error("Routability of on-link gateways must not be checked")
+		return net.IP{10, 0, 0, 1}, nil
+	}
+	params.options.GetLinkIndex = func(string) (int, error) {
+		return 3, nil
+	}
+	params.options.RouteManager = routeManagerMock{
+		registeredCallback: func(n string, r routemanager.Route) error {
+			routeParam = r
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if !routeParam.OnLink || routeParam.LinkIndex != 3 || routeParam.Gw.String() != "192.168.100.1" {
+		t.Errorf("On-link route must be registered: %+v", routeParam)
+	}
+}
+
+func TestReconcileImplOnLinkWithoutInterface(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.OnLink = true
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+
+	res, err := reconcileImpl(*params)
+
+	if res != invalidGatewayError {
+		t.Error("Result must be invalidGatewayError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplGatewayNotDirectlyRoutable(t *testing.T) {
+	route := newStaticRouteWithValues(true, true)
+	route.Spec.Gateway = "10.0.10.1"
+	params, _ := getReconcileContextForAddFlow(route, true, false)
+	params.options.GetGw = func(net.IP) (net.IP, error) {
+		return net.IP{10, 0, 0, 1}, nil
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != gatewayNotDirectlyRoutableError {
+		t.Error("Result must be gatewayNotDirectlyRoutableError")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func TestReconcileImplDeletingWhileGatewayNotDirectlyRoutable(t *testing.T) {
+	route := newStaticRouteWithValues(true, true)
+	route.Spec.Gateway = "10.0.10.1"
+	params, _ := getReconcileContextForAddFlow(route, true, true)
+	params.options.GetGw = func(net.IP) (net.IP, error) {
+		return net.IP{10, 0, 0, 1}, nil
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != deletionFinished {
+		t.Error("Result must be deletionFinished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
+
+func newStaticRouteWithSubnets(subnets ...string) *staticroutev1.StaticRoute {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Subnet = ""
+	route.Spec.Subnets = subnets
+	return route
+}
+
+func TestReconcileImplSubnets(t *testing.T) {
+	route := newStaticRouteWithSubnets("10.1.0.0/16", "172.16.0.0/16", "10.2.0.0/16")
+	params, mockClient := getReconcileContextForAddFlow(route, false, false)
+	params.options.ProtectedSubnets = cidr.NewSet(&net.IPNet{IP: net.IP{172, 16, 0, 0}, Mask: net.IPv4Mask(0xff, 0xff, 0, 0)})
+	registered := map[string]string{}
+	params.options.RouteManager = routeManagerMock{
+		registeredCallback: func(n string, r routemanager.Route) error {
+			registered[n] = r.Dst.String()
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if len(registered) != 2 || registered["CR/10.1.0.0/16"] != "10.1.0.0/16" || registered["CR/10.2.0.0/16"] != "10.2.0.0/16" {
+		t.Errorf("Allowed subnets must be registered one by one: %v", registered)
+	}
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Errorf("Failed to read the CR: %s", err.Error())
+	}
+	expected := []staticroutev1.SubnetStatus{{Subnet: "10.1.0.0/16"}, {Subnet: "172.16.0.0/16", Error: "given subnet overlaps with some protected subnet"}, {Subnet: "10.2.0.0/16"}}
+	if len(instance.Status.NodeStatus) != 1 || instance.Status.NodeStatus[0].Error != "1 of 3 subnets failed" || !reflect.DeepEqual(instance.Status.NodeStatus[0].Subnets, expected) {
+		t.Errorf("Status must report the subnets one by one: %+v", instance.Status.NodeStatus)
+	}
+}
+
+func TestReconcileImplSubnetsCantRegister(t *testing.T) {
+	route := newStaticRouteWithSubnets("10.1.0.0/16", "10.2.0.0/16")
+	params, mockClient := getReconcileContextForAddFlow(route, false, false)
+	params.options.RouteManager = routeManagerMock{
+		registeredCallback: func(n string, r routemanager.Route) error {
+			if n == "CR/10.2.0.0/16" {
+				return errors.New("Couldn't register route")
+			}
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != registerRouteError {
+		t.Error("Result must be registerRouteError")
+	}
+	if err == nil {
+		t.Error("Error must be not nil")
+	}
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Errorf("Failed to read the CR: %s", err.Error())
+	}
+	expected := []staticroutev1.SubnetStatus{{Subnet: "10.1.0.0/16"}, {Subnet: "10.2.0.0/16", Error: "Couldn't register route"}}
+	if len(instance.Status.NodeStatus) != 1 || instance.Status.NodeStatus[0].Error != "1 of 2 subnets failed" || !reflect.DeepEqual(instance.Status.NodeStatus[0].Subnets, expected) {
+		t.Errorf("Status must report the failed subnet: %+v", instance.Status.NodeStatus)
+	}
+}
+
+func TestReconcileImplSubnetsRegisteredAlready(t *testing.T) {
+	route := newStaticRouteWithSubnets("10.1.0.0/16", "10.2.0.0/16")
+	params, mock := getReconcileContextForDoubleReconcile(route, false)
+	params.options.RouteManager = routeManagerMock{
+		isRegisteredCallback: func(n string) bool {
+			return n == "CR/10.1.0.0/16"
+		},
+		registeredCallback: func(n string, r routemanager.Route) error {
+			if n != "CR/10.2.0.0/16" {
+				t.Errorf("Only the missing subnet must be registered: %s", n)
+			}
+			return nil
+		},
+		updatedCallback: func(n string, r routemanager.Route) error {
+			t.Errorf("Unchanged subnet must not be updated: %s", n)
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+
+	updateCounterBefore := mock.statusWriteMock.(*statusWriterMock).updateCounter
+	params.options.RouteManager = routeManagerMock{isRegistered: true}
+	_, _ = reconcileImpl(*params)
+	if updateCounterAfter := mock.statusWriteMock.(*statusWriterMock).updateCounter; updateCounterBefore != updateCounterAfter {
+		t.Errorf("Status should not be updated for the second reconciliation. Before: %d, after: %d", updateCounterBefore, updateCounterAfter)
+	}
+}
+
+func TestReconcileImplSubnetsChanged(t *testing.T) {
+	route := newStaticRouteWithSubnets("10.2.0.0/16", "10.3.0.0/16")
+	route.Status.NodeStatus = []staticroutev1.StaticRouteNodeStatus{{
+		Hostname: "hostname",
+		State:    staticroutev1.StaticRouteSpec{Subnets: []string{"10.1.0.0/16", "10.2.0.0/16"}, Gateway: "10.0.0.1"},
+		Subnets:  []staticroutev1.SubnetStatus{{Subnet: "10.1.0.0/16"}, {Subnet: "10.2.0.0/16"}},
+	}}
+	params, mockClient := getReconcileContextForAddFlow(route, false, false)
+	var deRegistered, updated, registered []string
+	params.options.RouteManager = routeManagerMock{
+		isRegisteredCallback: func(n string) bool {
+			return n != "CR/10.3.0.0/16"
+		},
+		deRegisteredCallback: func(n string) error {
+			deRegistered = append(deRegistered, n)
+			return nil
+		},
+		updatedCallback: func(n string, r routemanager.Route) error {
+			updated = append(updated, n)
+			return nil
+		},
+		registeredCallback: func(n string, r routemanager.Route) error {
+			registered = append(registered, n)
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if !reflect.DeepEqual(deRegistered, []string{"CR/10.1.0.0/16"}) || !reflect.DeepEqual(updated, []string{"CR/10.2.0.0/16"}) || !reflect.DeepEqual(registered, []string{"CR/10.3.0.0/16"}) {
+		t.Errorf("Removed subnet must be deregistered, kept one updated, new one registered: %v, %v, %v", deRegistered, updated, registered)
+	}
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Errorf("Failed to read the CR: %s", err.Error())
+	}
+	if len(instance.Status.NodeStatus) != 1 || !reflect.DeepEqual(instance.Status.NodeStatus[0].State.Subnets, route.Spec.Subnets) || len(instance.Status.NodeStatus[0].Subnets) != 2 {
+		t.Errorf("Status must report the new state: %+v", instance.Status.NodeStatus)
+	}
+}
+
+func TestReconcileImplSubnetsUpdateFailsFallsBackToReCreate(t *testing.T) {
+	route := newStaticRouteWithSubnets("10.1.0.0/16")
+	route.Status.NodeStatus = []staticroutev1.StaticRouteNodeStatus{{
+		Hostname: "hostname",
+		State:    staticroutev1.StaticRouteSpec{Subnets: []string{"10.1.0.0/16"}, Gateway: "10.0.0.2"},
+	}}
+	params, _ := getReconcileContextForAddFlow(route, true, false)
+	var deRegistered, registered []string
+	params.options.RouteManager = routeManagerMock{
+		isRegistered:   true,
+		updateRouteErr: errors.New("Couldn't update route"),
+		deRegisteredCallback: func(n string) error {
+			deRegistered = append(deRegistered, n)
+			return nil
+		},
+		registeredCallback: func(n string, r routemanager.Route) error {
+			registered = append(registered, n)
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if !reflect.DeepEqual(deRegistered, []string{"CR/10.1.0.0/16"}) || !reflect.DeepEqual(registered, []string{"CR/10.1.0.0/16"}) {
+		t.Errorf("Route must be re-created: %v, %v", deRegistered, registered)
+	}
+}
+
+func TestReconcileImplSubnetsFromSingleSubnet(t *testing.T) {
+	route := newStaticRouteWithSubnets("10.1.0.0/16")
+	route.Status.NodeStatus = []staticroutev1.StaticRouteNodeStatus{{
+		Hostname: "hostname",
+		State:    staticroutev1.StaticRouteSpec{Subnet: "10.0.0.0/16", Gateway: "10.0.0.1"},
+	}}
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	var deRegistered []string
+	params.options.RouteManager = routeManagerMock{
+		isRegisteredCallback: func(n string) bool {
+			return n == "CR"
+		},
+		deRegisteredCallback: func(n string) error {
+			deRegistered = append(deRegistered, n)
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if !reflect.DeepEqual(deRegistered, []string{"CR"}) {
+		t.Errorf("Route of the single subnet must be deregistered: %v", deRegistered)
+	}
+}
+
+func TestReconcileImplSubnetsDeleted(t *testing.T) {
+	route := newStaticRouteWithSubnets("10.1.0.0/16", "10.2.0.0/16")
+	route.Status.NodeStatus = []staticroutev1.StaticRouteNodeStatus{{
+		Hostname: "hostname",
+		State:    staticroutev1.StaticRouteSpec{Subnets: []string{"10.1.0.0/16", "10.2.0.0/16"}, Gateway: "10.0.0.1"},
+	}}
+	params, _ := getReconcileContextForAddFlow(route, true, true)
+	var deRegistered []string
+	params.options.RouteManager = routeManagerMock{
+		deRegisteredCallback: func(n string) error {
+			deRegistered = append(deRegistered, n)
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != deletionFinished {
+		t.Error("Result must be deletionFinished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if !reflect.DeepEqual(deRegistered, []string{"CR/10.1.0.0/16", "CR/10.2.0.0/16"}) {
+		t.Errorf("Every subnet must be deregistered: %v", deRegistered)
+	}
+}
+
+func TestReconcileImplSubnetsPolicyGetError(t *testing.T) {
+	route := newStaticRouteWithSubnets("10.1.0.0/16")
+	params, mockClient := getReconcileContextForAddFlow(route, false, false)
+	mockClient.listErr = errors.New("Couldn't list")
+
+	res, err := reconcileImpl(*params)
+
+	if res != policyGetError {
+		t.Error("Result must be policyGetError")
+	}
+	if err == nil {
+		t.Error("Error must be not nil")
+	}
+}
+
+func getReconcileContextForAddFlow(route *staticroutev1.StaticRoute, isRegistered bool, isDeleting bool) (*reconcileImplParams, *reconcileImplClientMock) {
+	if route == nil {
+		route = newStaticRouteWithValues(true, true)
+	}
+
+	if isDeleting {
+		route.DeletionTimestamp = &metav1.Time{Time: time.Date(2021, time.Month(2), 21, 1, 10, 30, 0, time.UTC)}
+		route.Finalizers = append(route.Finalizers, "finalizer.static-route.ibm.com")
+	}
+	mockClient := reconcileImplClientMock{
+		client: newFakeClient(route),
+	}
+	params := newReconcileImplParams(&mockClient)
+	params.options.Hostname = "hostname"
+	params.options.RouteManager = routeManagerMock{
+		isRegistered: isRegistered,
+	}
+	params.options.GetGw = func(net.IP) (net.IP, error) {
+		return nil, nil
+	}
+
+	return params, &mockClient
+}
+
+func getReconcileContextForDoubleReconcile(route *staticroutev1.StaticRoute, isRegistered bool) (*reconcileImplParams, *reconcileImplClientMock) {
+	if route == nil {
+		route = newStaticRouteWithValues(true, true)
+	}
+	client := newFakeClient(route)
+	mockClient := reconcileImplClientMock{
+		client:          client,
+		statusWriteMock: &statusWriterMock{client: client},
+	}
+	params := newReconcileImplParams(&mockClient)
+	params.options.Hostname = "hostname"
+	params.options.RouteManager = routeManagerMock{
+		isRegistered: isRegistered,
+	}
+	params.options.GetGw = func(net.IP) (net.IP, error) {
+		return nil, nil
+	}
+
+	return params, &mockClient
+}
+
+// newKernelContext returns a reconcile context whose route manager runs against a fake kernel with a directly
+// connected network and a default gateway
+func newKernelContext(t *testing.T, route *staticroutev1.StaticRoute) (*reconcileImplParams, *nlfake.Netlink) {
+	kernel := nlfake.NewNetlink()
+	link := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: "eth0"}}
+	if err := kernel.LinkAdd(link); err != nil {
+		t.Fatal(err)
+	}
+	for _, nlRoute := range []netlink.Route{
+		{Dst: &net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(24, 32)}, LinkIndex: link.Index, Scope: netlink.SCOPE_LINK},
+		{Dst: &net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}, Gw: net.IP{10, 0, 0, 254}},
+	} {
+		if err := kernel.RouteAdd(&nlRoute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rm := routemanager.NewWithNetlink(kernel, 196, routemanager.AuditOptions{})
+	stopChan := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		//nolint:errcheck
+		rm.Run(stopChan)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		close(stopChan)
+		<-done
+	})
+
+	params, _ := getReconcileContextForAddFlow(route, false, false)
+	params.options.Table = unix.RT_TABLE_MAIN
+	params.options.RouteManager = rm
+	params.options.GetGw = func(ip net.IP) (net.IP, error) {
+		routes, err := kernel.RouteGet(ip)
+		if err != nil {
+			return nil, err
+		}
+		return routes[0].Gw, nil
+	}
+	return params, kernel
+}
+
+func ownKernelRoutes(t *testing.T, kernel *nlfake.Netlink) []netlink.Route {
+	routes, err := kernel.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Protocol: 196}, netlink.RT_FILTER_TABLE|netlink.RT_FILTER_PROTOCOL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return routes
+}
+
+func TestReconcileImplInstallsRouteInKernel(t *testing.T) {
+	params, kernel := newKernelContext(t, newStaticRouteWithValues(true, false))
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished || err != nil {
+		t.Errorf("Route must be added: %v %v", res, err)
+	}
+	routes := ownKernelRoutes(t, kernel)
+	if len(routes) != 1 || routes[0].Dst.String() != "10.0.0.0/16" || !routes[0].Gw.Equal(net.IP{10, 0, 0, 1}) || routes[0].Table != unix.RT_TABLE_MAIN {
+		t.Errorf("Route must be installed in the kernel: %v", routes)
+	}
+}
+
+func TestReconcileImplGatewayBehindRouterInKernel(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Spec.Gateway = "192.168.0.1"
+	params, kernel := newKernelContext(t, route)
+
+	res, err := reconcileImpl(*params)
+
+	if res != gatewayNotDirectlyRoutableError || err != nil {
+		t.Errorf("Gateway reachable through the default route must be rejected: %v %v", res, err)
+	}
+	if routes := ownKernelRoutes(t, kernel); len(routes) != 0 {
+		t.Errorf("Route must not be installed in the kernel: %v", routes)
+	}
+}
+
+func TestReconcileImplTableAboveMainRoundTrip(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	table := 1000
+	route.Spec.Table = &table
+	params, kernel := newKernelContext(t, route)
+	mockClient := params.client
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished || err != nil {
+		t.Fatalf("Route must be added: %v %v", res, err)
+	}
+	routes, err := kernel.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: 1000}, netlink.RT_FILTER_TABLE)
+	if err != nil || len(routes) != 1 || routes[0].Dst.String() != "10.0.0.0/16" {
+		t.Errorf("Route must be installed in table 1000: %v %v", routes, err)
+	}
+	instance := &staticroutev1.StaticRoute{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: route.Name, Namespace: route.Namespace}, instance); err != nil {
+		t.Fatalf("Failed to read the CR: %s", err.Error())
+	}
+	if len(instance.Status.NodeStatus) != 1 || instance.Status.NodeStatus[0].State.Table == nil || *instance.Status.NodeStatus[0].State.Table != 1000 {
+		t.Fatalf("Table must be reported in the status: %+v", instance.Status.NodeStatus)
+	}
+
+	res, err = reconcileImpl(*params)
+
+	if res != finished || err != nil {
+		t.Errorf("Route read back from the status must not be changed: %v %v", res, err)
+	}
+	if routes := ownKernelRoutes(t, kernel); len(routes) != 1 || routes[0].Table != 1000 {
+		t.Errorf("Route must stay in table 1000 only: %v", routes)
+	}
 }
@@ -0,0 +1,117 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"context"
+	"slices"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const staticRouteCRDName = "staticroutes.static-route.ibm.com"
+
+var crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+// storageMigrator runs once, on the operator instance elected as leader. It rewrites every StaticRoute, so the API
+// server stores them in the storage version, then removes the old versions from the stored versions of the
+// CustomResourceDefinition. The old versions can be dropped from the CustomResourceDefinition only after the
+// migration. A new leader runs it again, which is safe: rewriting an object already stored in the storage version
+// does not change it, and the stored versions are set only after all the objects are stored in the storage version.
+type storageMigrator struct {
+	reader client.Reader
+	client client.Client
+}
+
+// blank assignment to verify that storageMigrator implements manager.Runnable
+var _ manager.Runnable = &storageMigrator{}
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=get;update;patch
+
+// AddStorageMigration registers the storage version migration of the StaticRoutes to the manager. It needs the
+// conversion webhook, as the API server converts the objects through it, and the leader election of the manager.
+func AddStorageMigration(mgr manager.Manager) error {
+	return mgr.Add(&storageMigrator{reader: mgr.GetAPIReader(), client: mgr.GetClient()})
+}
+
+// NeedLeaderElection is true, one instance migrates the StaticRoutes of the whole cluster
+func (m *storageMigrator) NeedLeaderElection() bool {
+	return true
+}
+
+func (m *storageMigrator) Start(ctx context.Context) error {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	if err := m.reader.Get(ctx, client.ObjectKey{Name: staticRouteCRDName}, crd); err != nil {
+		log.Error(err, "Unable to fetch the CustomResourceDefinition for storage migration")
+		return nil
+	}
+	storage := storageVersion(crd)
+	if len(storage) == 0 {
+		log.Info("Storage version of the CustomResourceDefinition not found, StaticRoutes are not migrated")
+		return nil
+	}
+	storedVersions, _, _ := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+	if slices.Equal(storedVersions, []string{storage}) {
+		return nil
+	}
+
+	routes := &staticroutev1.StaticRouteList{}
+	if err := m.reader.List(ctx, routes); err != nil {
+		log.Error(err, "Unable to fetch StaticRoutes for storage migration")
+		return nil
+	}
+	for i := range routes.Items {
+		// A conflict means that someone else has written the object meanwhile, so it is stored in the new version
+		if err := m.client.Update(ctx, &routes.Items[i]); err != nil && !errors.IsConflict(err) && !errors.IsNotFound(err) {
+			log.Error(err, "Unable to migrate StaticRoute to the storage version", "Request.Name", routes.Items[i].Name)
+			return nil
+		}
+	}
+
+	patch := client.MergeFrom(crd.DeepCopy())
+	if err := unstructured.SetNestedStringSlice(crd.Object, []string{storage}, "status", "storedVersions"); err != nil {
+		log.Error(err, "Unable to set stored versions")
+		return nil
+	}
+	if err := m.client.Status().Patch(ctx, crd, patch); err != nil {
+		log.Error(err, "Unable to update stored versions of the CustomResourceDefinition")
+		return nil
+	}
+	log.Info("StaticRoutes are migrated to the storage version", "Version", storage, "Count", len(routes.Items))
+	return nil
+}
+
+// storageVersion returns the version of the CustomResourceDefinition the API server stores the objects in. It is v1
+// unless the v2 storage patch is enabled together with the conversion webhook, see config/crd.
+func storageVersion(crd *unstructured.Unstructured) string {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, item := range versions {
+		version, _ := item.(map[string]interface{})
+		if storage, _ := version["storage"].(bool); storage {
+			name, _ := version["name"].(string)
+			return name
+		}
+	}
+	return ""
+}
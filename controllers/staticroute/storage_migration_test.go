@@ -0,0 +1,209 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newStaticRouteCRD(storage string, storedVersions ...interface{}) *unstructured.Unstructured {
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": staticRouteCRDName},
+		"spec": map[string]interface{}{"versions": []interface{}{
+			map[string]interface{}{"name": "v1", "storage": storage == "v1"},
+			map[string]interface{}{"name": "v2", "storage": storage == "v2"},
+		}},
+		"status": map[string]interface{}{"storedVersions": storedVersions},
+	}}
+	crd.SetGroupVersionKind(crdGVK)
+	return crd
+}
+
+func newMigrationClient(crd *unstructured.Unstructured, funcs interceptor.Funcs) client.WithWatch {
+	route := newStaticRouteWithValues(true, false)
+	s := runtime.NewScheme()
+	s.AddKnownTypes(staticroutev1.GroupVersion, route, &staticroutev1.StaticRouteList{})
+	return fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(crd, route).
+		WithStatusSubresource(crd).
+		WithInterceptorFuncs(funcs).
+		Build()
+}
+
+func getStoredVersions(t *testing.T, c client.Client) []string {
+	crd := newStaticRouteCRD("")
+	if err := c.Get(context.Background(), client.ObjectKey{Name: staticRouteCRDName}, crd); err != nil {
+		t.Fatal(err)
+	}
+	storedVersions, _, _ := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+	return storedVersions
+}
+
+func TestStorageMigrator(t *testing.T) {
+	updated := []string{}
+	c := newMigrationClient(newStaticRouteCRD("v2", "v1", "v2"), interceptor.Funcs{
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			updated = append(updated, obj.GetName())
+			return c.Update(ctx, obj, opts...)
+		},
+	})
+	migrator := storageMigrator{reader: c, client: c}
+
+	if err := migrator.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(updated) != 1 || updated[0] != "CR" {
+		t.Errorf("StaticRoute must be rewritten: %v", updated)
+	}
+	if storedVersions := getStoredVersions(t, c); len(storedVersions) != 1 || storedVersions[0] != "v2" {
+		t.Errorf("Stored versions must be updated: %v", storedVersions)
+	}
+}
+
+func TestStorageMigratorAlreadyMigrated(t *testing.T) {
+	c := newMigrationClient(newStaticRouteCRD("v2", "v2"), interceptor.Funcs{
+		Update: func(context.Context, client.WithWatch, client.Object, ...client.UpdateOption) error {
+			t.Error("StaticRoute must not be rewritten")
+			return nil
+		},
+	})
+	migrator := storageMigrator{reader: c, client: c}
+
+	if err := migrator.Start(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStorageMigratorV1Storage(t *testing.T) {
+	c := newMigrationClient(newStaticRouteCRD("v1", "v1"), interceptor.Funcs{
+		Update: func(context.Context, client.WithWatch, client.Object, ...client.UpdateOption) error {
+			t.Error("StaticRoute must not be rewritten")
+			return nil
+		},
+	})
+	migrator := storageMigrator{reader: c, client: c}
+
+	if err := migrator.Start(context.Background()); err != nil {
+		t.Error(err)
+	}
+
+	if storedVersions := getStoredVersions(t, c); len(storedVersions) != 1 || storedVersions[0] != "v1" {
+		t.Errorf("Stored versions must stay v1 without the v2 storage patch: %v", storedVersions)
+	}
+}
+
+func TestStorageMigratorNoStorageVersion(t *testing.T) {
+	c := newMigrationClient(newStaticRouteCRD("", "v1", "v2"), interceptor.Funcs{
+		Update: func(context.Context, client.WithWatch, client.Object, ...client.UpdateOption) error {
+			t.Error("StaticRoute must not be rewritten")
+			return nil
+		},
+	})
+	migrator := storageMigrator{reader: c, client: c}
+
+	if err := migrator.Start(context.Background()); err != nil {
+		t.Error(err)
+	}
+
+	if storedVersions := getStoredVersions(t, c); len(storedVersions) != 2 {
+		t.Errorf("Stored versions must not be updated: %v", storedVersions)
+	}
+}
+
+func TestStorageMigratorUpdateFails(t *testing.T) {
+	c := newMigrationClient(newStaticRouteCRD("v2", "v1", "v2"), interceptor.Funcs{
+		Update: func(context.Context, client.WithWatch, client.Object, ...client.UpdateOption) error {
+			return errors.New("update failed")
+		},
+	})
+	migrator := storageMigrator{reader: c, client: c}
+
+	if err := migrator.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if storedVersions := getStoredVersions(t, c); len(storedVersions) != 2 {
+		t.Errorf("Stored versions must not be updated: %v", storedVersions)
+	}
+}
+
+func TestStorageMigratorCRDGetFails(t *testing.T) {
+	c := newMigrationClient(newStaticRouteCRD("v2", "v1", "v2"), interceptor.Funcs{
+		Get: func(context.Context, client.WithWatch, client.ObjectKey, client.Object, ...client.GetOption) error {
+			return errors.New("get failed")
+		},
+		Update: func(context.Context, client.WithWatch, client.Object, ...client.UpdateOption) error {
+			t.Error("StaticRoute must not be rewritten")
+			return nil
+		},
+	})
+	migrator := storageMigrator{reader: c, client: c}
+
+	if err := migrator.Start(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStorageMigratorConflict(t *testing.T) {
+	c := newMigrationClient(newStaticRouteCRD("v2", "v1", "v2"), interceptor.Funcs{
+		Update: func(context.Context, client.WithWatch, client.Object, ...client.UpdateOption) error {
+			return kerrors.NewConflict(schema.GroupResource{Resource: "staticroutes"}, "CR", errors.New("modified"))
+		},
+	})
+	migrator := storageMigrator{reader: c, client: c}
+
+	if err := migrator.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if storedVersions := getStoredVersions(t, c); len(storedVersions) != 1 || storedVersions[0] != "v2" {
+		t.Errorf("StaticRoute rewritten by another node must be migrated: %v", storedVersions)
+	}
+}
+
+func TestStorageMigratorRunsTwice(t *testing.T) {
+	c := newMigrationClient(newStaticRouteCRD("v2", "v1", "v2"), interceptor.Funcs{})
+	for i := 0; i < 2; i++ {
+		migrator := storageMigrator{reader: c, client: c}
+		if err := migrator.Start(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if storedVersions := getStoredVersions(t, c); len(storedVersions) != 1 || storedVersions[0] != "v2" {
+		t.Errorf("Stored versions must be updated: %v", storedVersions)
+	}
+}
+
+func TestStorageMigratorNeedLeaderElection(t *testing.T) {
+	if !(&storageMigrator{}).NeedLeaderElection() {
+		t.Error("Migration must run on the leader only")
+	}
+}
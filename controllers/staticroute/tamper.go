@@ -0,0 +1,136 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/pkg/routemanager"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// maxTamperReactionBackoff is the upper limit of the delay before re-creating a repeatedly tampered route
+const maxTamperReactionBackoff = 5 * time.Minute
+
+// tamperWatcher is notified by the RouteManager when a managed route is deleted by an external entity.
+// It deregisters the damaged route and re-enqueues the owning StaticRoute after a backoff, so the
+// next reconciliation registers the route again. The drifts found by the audit of the RouteManager
+// are reported on the owning StaticRoute.
+type tamperWatcher struct {
+	routeManager routemanager.RouteManager
+	events       chan event.GenericEvent
+	backoff      *flowcontrol.Backoff
+	sleep        func(time.Duration)
+	mutex        sync.Mutex
+	tampered     map[string]metav1.Time
+	drifted      map[string]string
+}
+
+// blank assignments to verify that tamperWatcher implements routemanager.RouteWatcher and routemanager.DriftWatcher
+var _ routemanager.RouteWatcher = &tamperWatcher{}
+var _ routemanager.DriftWatcher = &tamperWatcher{}
+
+func newTamperWatcher(routeManager routemanager.RouteManager, backoff time.Duration) *tamperWatcher {
+	return &tamperWatcher{
+		routeManager: routeManager,
+		events:       make(chan event.GenericEvent),
+		backoff:      flowcontrol.NewBackOff(backoff, maxTamperReactionBackoff),
+		sleep:        time.Sleep,
+		tampered:     make(map[string]metav1.Time),
+		drifted:      make(map[string]string),
+	}
+}
+
+// RouteDeleted is called from the event loop of the RouteManager, so the reaction runs in its own go-routine.
+// The routes of StaticRoutes with multiple subnets are registered under a compound name, the tamper status is
+// tracked for the owning StaticRoute.
+func (w *tamperWatcher) RouteDeleted(name string, route routemanager.Route) {
+	log.Info("Managed route was deleted by an external entity", "Request.Name", routeOwner(name), "Subnet", route.Dst.String())
+	w.mutex.Lock()
+	w.tampered[routeOwner(name)] = metav1.Now()
+	w.mutex.Unlock()
+
+	w.backoff.Next(name, w.backoff.Clock.Now())
+	go w.react(name, w.backoff.Get(name))
+}
+
+func (w *tamperWatcher) react(name string, delay time.Duration) {
+	w.sleep(delay)
+	if err := w.routeManager.DeRegisterRoute(name); err != nil && err != routemanager.ErrNotFound {
+		log.Error(err, "Unable to deregister the tampered route", "Request.Name", routeOwner(name))
+	}
+	w.events <- event.GenericEvent{Object: &staticroutev1.StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: routeOwner(name)}}}
+}
+
+// popTampered returns the time of the last tamper event of the route (if any) and forgets it
+func (w *tamperWatcher) popTampered(name string) *metav1.Time {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	tampered, found := w.tampered[name]
+	if !found {
+		return nil
+	}
+	delete(w.tampered, name)
+	return &tampered
+}
+
+// RouteDrifted is called from the event loop of the RouteManager as well. The RouteManager already repaired the
+// drift if its policy allows it, so the owning StaticRoute is only re-enqueued to report the drift.
+func (w *tamperWatcher) RouteDrifted(name string, drift routemanager.Drift) {
+	log.Info("Managed route drifted from the kernel", "Request.Name", routeOwner(name), "Subnet", drift.Route.Dst.String(), "Type", drift.Type, "Repaired", drift.Repaired)
+	w.mutex.Lock()
+	w.drifted[routeOwner(name)] = describeDrift(drift)
+	w.mutex.Unlock()
+
+	go func() {
+		w.events <- event.GenericEvent{Object: &staticroutev1.StaticRoute{ObjectMeta: metav1.ObjectMeta{Name: routeOwner(name)}}}
+	}()
+}
+
+// popDrifted returns the description of the last drift of the route (if any) and forgets it
+func (w *tamperWatcher) popDrifted(name string) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	drifted := w.drifted[name]
+	delete(w.drifted, name)
+	return drifted
+}
+
+// describeDrift returns a human readable description of the drift and its repair
+func describeDrift(drift routemanager.Drift) string {
+	var description string
+	switch drift.Type {
+	case routemanager.DriftMissing:
+		description = fmt.Sprintf("Route to %s is missing from table %d", drift.Route.Dst.String(), drift.Route.Table)
+	case routemanager.DriftModified:
+		description = fmt.Sprintf("Route to %s was modified in table %d", drift.Route.Dst.String(), drift.Route.Table)
+	default:
+		description = fmt.Sprintf("Route to %s is shadowed by a route with priority %d in table %d", drift.Route.Dst.String(), drift.Kernel.Priority, drift.Route.Table)
+	}
+	switch {
+	case drift.Repaired:
+		return description + ", repaired"
+	case drift.Err != nil:
+		return description + ", repair failed: " + drift.Err.Error()
+	}
+	return description
+}
@@ -0,0 +1,153 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticroute
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/IBM/staticroute-operator/pkg/routemanager"
+)
+
+var gTamperedRoute = routemanager.Route{Dst: net.IPNet{IP: net.IP{192, 168, 1, 0}, Mask: net.CIDRMask(24, 32)}, Gw: net.IP{10, 0, 0, 1}, Table: 254}
+
+func newTestableTamperWatcher(deRegistered chan string, deRegisterErr error) (*tamperWatcher, chan time.Duration) {
+	slept := make(chan time.Duration, 10)
+	w := newTamperWatcher(routeManagerMock{
+		deRegisteredCallback: func(n string) error {
+			deRegistered <- n
+			return deRegisterErr
+		},
+	}, time.Second)
+	w.sleep = func(d time.Duration) {
+		slept <- d
+	}
+	return w, slept
+}
+
+func TestTamperWatcherReenqueues(t *testing.T) {
+	deRegistered := make(chan string, 1)
+	w, slept := newTestableTamperWatcher(deRegistered, nil)
+
+	w.RouteDeleted("CR", gTamperedRoute)
+
+	if delay := <-slept; delay != time.Second {
+		t.Errorf("First reaction must wait for the initial backoff, waited: %s", delay)
+	}
+	if name := <-deRegistered; name != "CR" {
+		t.Errorf("Tampered route must be deregistered, deregistered: %s", name)
+	}
+	if event := <-w.events; event.Object.GetName() != "CR" {
+		t.Errorf("Owner StaticRoute must be re-enqueued, enqueued: %s", event.Object.GetName())
+	}
+}
+
+func TestTamperWatcherReenqueuesOwnerOfSubnet(t *testing.T) {
+	deRegistered := make(chan string, 1)
+	w, _ := newTestableTamperWatcher(deRegistered, nil)
+
+	w.RouteDeleted("CR/10.0.0.0/16", gTamperedRoute)
+
+	if name := <-deRegistered; name != "CR/10.0.0.0/16" {
+		t.Errorf("Tampered route of the subnet must be deregistered, deregistered: %s", name)
+	}
+	if event := <-w.events; event.Object.GetName() != "CR" {
+		t.Errorf("Owner StaticRoute must be re-enqueued, enqueued: %s", event.Object.GetName())
+	}
+	if w.popTampered("CR") == nil {
+		t.Error("Owner StaticRoute must be reported as tampered")
+	}
+}
+
+func TestTamperWatcherReenqueuesIfDeRegisterFails(t *testing.T) {
+	deRegistered := make(chan string, 1)
+	w, _ := newTestableTamperWatcher(deRegistered, errors.New("bla"))
+
+	w.RouteDeleted("CR", gTamperedRoute)
+
+	<-deRegistered
+	if event := <-w.events; event.Object.GetName() != "CR" {
+		t.Errorf("Owner StaticRoute must be re-enqueued, enqueued: %s", event.Object.GetName())
+	}
+}
+
+func TestTamperWatcherBackoffIncreases(t *testing.T) {
+	deRegistered := make(chan string, 2)
+	w, slept := newTestableTamperWatcher(deRegistered, nil)
+
+	w.RouteDeleted("CR", gTamperedRoute)
+	<-w.events
+	w.RouteDeleted("CR", gTamperedRoute)
+	<-w.events
+
+	first, second := <-slept, <-slept
+	if second <= first {
+		t.Errorf("Backoff must increase for repeated tampering: %s, %s", first, second)
+	}
+}
+
+func TestTamperWatcherPopTampered(t *testing.T) {
+	deRegistered := make(chan string, 1)
+	w, _ := newTestableTamperWatcher(deRegistered, nil)
+
+	if w.popTampered("CR") != nil {
+		t.Error("Route is not tampered yet")
+	}
+	w.RouteDeleted("CR", gTamperedRoute)
+	<-w.events
+	if w.popTampered("CR") == nil {
+		t.Error("Route must be reported as tampered")
+	}
+	if w.popTampered("CR") != nil {
+		t.Error("Tamper must be reported only once")
+	}
+}
+
+func TestTamperWatcherRouteDrifted(t *testing.T) {
+	w, _ := newTestableTamperWatcher(nil, nil)
+
+	w.RouteDrifted("CR/10.0.0.0/16", routemanager.Drift{Type: routemanager.DriftModified, Route: gTamperedRoute, Repaired: true})
+
+	if event := <-w.events; event.Object.GetName() != "CR" {
+		t.Errorf("Owner StaticRoute must be re-enqueued, enqueued: %s", event.Object.GetName())
+	}
+	if drifted := w.popDrifted("CR"); drifted != "Route to 192.168.1.0/24 was modified in table 254, repaired" {
+		t.Errorf("Drift must be reported: %s", drifted)
+	}
+	if w.popDrifted("CR") != "" || w.popTampered("CR") != nil {
+		t.Error("Drift must be reported once and not as tamper")
+	}
+}
+
+func TestDescribeDrift(t *testing.T) {
+	shadowing := gTamperedRoute
+	shadowing.Priority = 10
+	var testData = []struct {
+		drift    routemanager.Drift
+		expected string
+	}{
+		{routemanager.Drift{Type: routemanager.DriftMissing, Route: gTamperedRoute}, "Route to 192.168.1.0/24 is missing from table 254"},
+		{routemanager.Drift{Type: routemanager.DriftShadowed, Route: gTamperedRoute, Kernel: &shadowing, Err: errors.New("bla")}, "Route to 192.168.1.0/24 is shadowed by a route with priority 10 in table 254, repair failed: bla"},
+	}
+	for _, td := range testData {
+		if description := describeDrift(td.drift); description != td.expected {
+			t.Errorf("Description mismatch: expected %s, actual %s", td.expected, description)
+		}
+	}
+}
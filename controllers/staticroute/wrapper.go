@@ -17,10 +17,17 @@
 package staticroute
 
 import (
+	"fmt"
 	"net"
 	"reflect"
+	"slices"
+	"strings"
 
 	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/pkg/cidr"
+	"golang.org/x/sys/unix"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -37,41 +44,222 @@ func (rw *routeWrapper) setFinalizer() bool {
 	return true
 }
 
-func (rw *routeWrapper) isProtected(protecteds []*net.IPNet) bool {
-	_, subnetNet, err := net.ParseCIDR(rw.instance.Spec.Subnet)
+func (rw *routeWrapper) isProtected(protecteds *cidr.Set) bool {
+	return isProtectedSubnet(rw.instance.Spec.Subnet, protecteds)
+}
+
+func isProtectedSubnet(subnet string, protecteds *cidr.Set) bool {
+	_, subnetNet, err := net.ParseCIDR(subnet)
 	if err != nil {
 		return false
 	}
-	inc := func(ip net.IP) {
-		for i := len(ip) - 1; i >= 0; i-- {
-			ip[i]++
-			if ip[i] > 0 {
-				break
+	return protecteds.Overlaps(subnetNet)
+}
+
+// isAllowed returns true if the subnet is inside one of the allowed subnets, nil allows every subnet
+func (rw *routeWrapper) isAllowed(alloweds *cidr.Set) bool {
+	return isAllowedSubnet(rw.instance.Spec.Subnet, alloweds)
+}
+
+func isAllowedSubnet(subnet string, alloweds *cidr.Set) bool {
+	if alloweds == nil {
+		return true
+	}
+	_, subnetNet, err := net.ParseCIDR(subnet)
+	return err == nil && alloweds.Containing(subnetNet) != nil
+}
+
+// Returns true if the subnet in the Spec is an IPv6 one, the subnets of the list share the IP family
+func (rw *routeWrapper) isIPv6() bool {
+	_, subnetNet, err := net.ParseCIDR(rw.subnets()[0])
+	return err == nil && subnetNet.IP.To4() == nil
+}
+
+// Returns the subnets of the Spec, a single one unless the list of subnets is used
+func (rw *routeWrapper) subnets() []string {
+	return rw.instance.Spec.AllSubnets()
+}
+
+// Returns true if the Spec uses the list of subnets, which are registered one by one under a compound name
+func (rw *routeWrapper) hasSubnets() bool {
+	return len(rw.instance.Spec.Subnets) != 0
+}
+
+// Returns the subnets of the Spec in a human readable form for the events
+func (rw *routeWrapper) subnetText() string {
+	return strings.Join(rw.subnets(), ", ")
+}
+
+// managedRoute is a route of a StaticRoute in the route manager
+type managedRoute struct {
+	name   string
+	subnet string
+}
+
+// routeKey returns the name of the route of one subnet of a StaticRoute with multiple subnets in the route manager.
+// Names of StaticRoutes can not contain a slash, so the owner can be recovered by routeOwner.
+func routeKey(name, subnet string) string {
+	return name + "/" + subnet
+}
+
+// routeOwner returns the name of the StaticRoute owning the route registered in the route manager
+func routeOwner(key string) string {
+	name, _, _ := strings.Cut(key, "/")
+	return name
+}
+
+// managedRoutes returns the routes which may be registered for the StaticRoute on this node: the ones of the
+// Spec and the ones of the state reported in the node status.
+func (rw *routeWrapper) managedRoutes(name, hostname string) []managedRoute {
+	routes := []managedRoute{}
+	seen := map[string]bool{}
+	add := func(spec staticroutev1.StaticRouteSpec) {
+		for _, subnet := range spec.AllSubnets() {
+			key := name
+			if len(spec.Subnets) != 0 {
+				key = routeKey(name, subnet)
+			}
+			if !seen[key] {
+				seen[key] = true
+				routes = append(routes, managedRoute{name: key, subnet: subnet})
 			}
 		}
 	}
-	for _, protected := range protecteds {
-		for ip := protected.IP.Mask(protected.Mask); protected.Contains(ip); inc(ip) {
-			if subnetNet.Contains(ip) {
-				return true
-			}
+	add(rw.instance.Spec)
+	for _, val := range rw.instance.Status.NodeStatus {
+		if val.Hostname == hostname {
+			add(val.State)
 		}
 	}
+	return routes
+}
 
-	return false
+// Returns true if the route forwards the packets through gateways
+func (rw *routeWrapper) isUnicast() bool {
+	return routeType(rw.instance.Spec.Type) == 0
+}
+
+// Returns true if the output interface is selected by the Spec
+func (rw *routeWrapper) hasInterface() bool {
+	return len(rw.instance.Spec.Interface) != 0 || len(rw.instance.Spec.InterfaceAddressIn) != 0
+}
+
+// Returns true if a gateway has to be selected for the route, device routes and the special route types have none
+func (rw *routeWrapper) needsGateway() bool {
+	return rw.isUnicast() && (len(rw.instance.Spec.Gateway) != 0 || !rw.hasInterface())
 }
 
-func (rw *routeWrapper) isChanged(hostname, gateway string, selectors []metav1.LabelSelectorRequirement) bool {
+// routeType converts the type in the Spec to the kernel route type, unicast is 0 like in the route manager
+func routeType(t string) int {
+	switch t {
+	case staticroutev1.RouteTypeBlackhole:
+		return unix.RTN_BLACKHOLE
+	case staticroutev1.RouteTypeUnreachable:
+		return unix.RTN_UNREACHABLE
+	case staticroutev1.RouteTypeProhibit:
+		return unix.RTN_PROHIBIT
+	case staticroutev1.RouteTypeThrow:
+		return unix.RTN_THROW
+	}
+	return 0
+}
+
+// routeScope converts the scope in the Spec to the kernel route scope, 0 leaves it to the route manager
+func routeScope(scope string) int {
+	switch scope {
+	case staticroutev1.ScopeSite:
+		return unix.RT_SCOPE_SITE
+	case staticroutev1.ScopeLink:
+		return unix.RT_SCOPE_LINK
+	case staticroutev1.ScopeHost:
+		return unix.RT_SCOPE_HOST
+	}
+	return 0
+}
+
+func (rw *routeWrapper) isChanged(hostname, gateway string, nextHops []staticroutev1.NextHop, selectors []metav1.LabelSelectorRequirement) bool {
+	activeGateway := ""
+	if rw.hasCandidateGateways() {
+		activeGateway = gateway
+	}
 	for _, s := range rw.instance.Status.NodeStatus {
 		if s.Hostname != hostname {
 			continue
-		} else if s.State.Subnet != rw.instance.Spec.Subnet || s.State.Gateway != gateway || !reflect.DeepEqual(s.State.Table, rw.instance.Spec.Table) || !reflect.DeepEqual(s.State.Selectors, selectors) {
+		} else if !stateMatchesSpec(s.State, rw.instance.Spec, rw.stateGateway(gateway), nextHops, selectors) || s.ActiveGateway != activeGateway {
 			return true
 		}
 	}
 	return false
 }
 
+// stateMatchesSpec compares the applied state of a node with the spec field by field, the gateway, the next hops
+// and the selectors are the values resolved on the node
+func stateMatchesSpec(state, spec staticroutev1.StaticRouteSpec, gateway string, nextHops []staticroutev1.NextHop, selectors []metav1.LabelSelectorRequirement) bool {
+	return state.Subnet == spec.Subnet &&
+		slices.Equal(state.Subnets, spec.Subnets) &&
+		state.Gateway == gateway &&
+		nextHopsEqual(state.Gateways, nextHops) &&
+		reflect.DeepEqual(state.Table, spec.Table) &&
+		reflect.DeepEqual(state.Metric, spec.Metric) &&
+		routeType(state.Type) == routeType(spec.Type) &&
+		state.Interface == spec.Interface &&
+		state.InterfaceAddressIn == spec.InterfaceAddressIn &&
+		state.Src == spec.Src &&
+		state.SrcInterface == spec.SrcInterface &&
+		state.Scope == spec.Scope &&
+		state.OnLink == spec.OnLink &&
+		routeMetricsEqual(state, spec) &&
+		slices.Equal(state.CandidateGateways, spec.CandidateGateways) &&
+		reflect.DeepEqual(state.Probe, spec.Probe) &&
+		reflect.DeepEqual(state.Selectors, selectors)
+}
+
+// routeMetricsEqual compares the metrics (mtu, advmss, etc.) of the routes
+func routeMetricsEqual(a, b staticroutev1.StaticRouteSpec) bool {
+	return a.MTU == b.MTU && a.AdvMSS == b.AdvMSS && a.InitCwnd == b.InitCwnd && a.InitRwnd == b.InitRwnd && a.HopLimit == b.HopLimit
+}
+
+func nextHopsEqual(a, b []staticroutev1.NextHop) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Gateway != b[i].Gateway || nextHopWeight(a[i]) != nextHopWeight(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func nextHopWeight(nextHop staticroutev1.NextHop) int {
+	if nextHop.Weight == nil {
+		return 1
+	}
+	return *nextHop.Weight
+}
+
+// stateGateway returns the gateway reported in the state of the node status. The state must satisfy the rules of
+// the Spec, so only unicast routes without candidate gateways report one, the active candidate is in ActiveGateway.
+func (rw *routeWrapper) stateGateway(gateway string) string {
+	if !rw.isUnicast() || rw.hasCandidateGateways() {
+		return ""
+	}
+	return gateway
+}
+
+// Returns true if the gateway of the route is selected from the candidate gateways by their health
+func (rw *routeWrapper) hasCandidateGateways() bool {
+	return len(rw.instance.Spec.CandidateGateways) != 0
+}
+
+// Returns the string representation of the gateway, empty if it is not set (multipath routes)
+func gatewayString(gateway net.IP) string {
+	if gateway == nil {
+		return ""
+	}
+	return gateway.String()
+}
+
 // Returns nil like the underlaying net.ParseIP()
 func (rw *routeWrapper) getGateway() net.IP {
 	gateway := rw.instance.Spec.Gateway
@@ -81,40 +269,149 @@ func (rw *routeWrapper) getGateway() net.IP {
 	return net.ParseIP(gateway)
 }
 
-func (rw *routeWrapper) statusMatch(hostname string, gateway net.IP, err error) bool {
+func (rw *routeWrapper) statusMatch(hostname string, gateway net.IP, nextHops []staticroutev1.NextHop, err error) bool {
 	errText := ""
 	if err != nil {
 		errText = err.Error()
 	}
 	for _, val := range rw.instance.Status.NodeStatus {
-		if val.Hostname == hostname && val.State.Subnet == rw.instance.Spec.Subnet && val.State.Gateway == gateway.String() && val.Error == errText {
+		if val.Hostname == hostname && val.State.Subnet == rw.instance.Spec.Subnet && val.State.Gateway == rw.stateGateway(gatewayString(gateway)) && nextHopsEqual(val.State.Gateways, nextHops) && val.Error == errText {
 			return true
 		}
 	}
 	return false
 }
 
-func (rw *routeWrapper) addToStatus(hostname string, gateway net.IP, err error) bool {
+func (rw *routeWrapper) addToStatus(hostname string, gateway net.IP, nextHops []staticroutev1.NextHop, err error) bool {
 	// Update the status if necessary
 	for _, val := range rw.instance.Status.NodeStatus {
 		if val.Hostname == hostname {
 			return false
 		}
 	}
-	spec := rw.instance.Spec
-	spec.Gateway = gateway.String()
+	spec := *rw.instance.Spec.DeepCopy()
+	spec.Gateway = rw.stateGateway(gatewayString(gateway))
+	// Only the installed next hops are reported
+	spec.Gateways = nextHops
 	errorString := ""
 	if err != nil {
 		errorString = err.Error()
 	}
 	rw.instance.Status.NodeStatus = append(rw.instance.Status.NodeStatus, staticroutev1.StaticRouteNodeStatus{
-		Hostname: hostname,
-		State:    spec,
-		Error:    errorString,
+		Hostname:           hostname,
+		State:              spec,
+		Error:              errorString,
+		ObservedGeneration: rw.instance.GetGeneration(),
 	})
 	return true
 }
 
+// subnetStatusMatch returns true if the node status reports the same results of the subnets
+func (rw *routeWrapper) subnetStatusMatch(hostname string, subnets []staticroutev1.SubnetStatus) bool {
+	for _, val := range rw.instance.Status.NodeStatus {
+		if val.Hostname == hostname {
+			return slices.Equal(val.Subnets, subnets)
+		}
+	}
+	return false
+}
+
+// setSubnetStatus overwrites the results of the subnets in the node status, returns false if the node is not in the status
+func (rw *routeWrapper) setSubnetStatus(hostname string, subnets []staticroutev1.SubnetStatus) bool {
+	for i := range rw.instance.Status.NodeStatus {
+		if rw.instance.Status.NodeStatus[i].Hostname == hostname {
+			rw.instance.Status.NodeStatus[i].Subnets = subnets
+			return true
+		}
+	}
+	return false
+}
+
+// probeStatusMatch returns true if the node status reports the same active gateway and health of the candidate gateways
+func (rw *routeWrapper) probeStatusMatch(hostname, activeGateway string, probes []staticroutev1.GatewayProbeStatus) bool {
+	for _, val := range rw.instance.Status.NodeStatus {
+		if val.Hostname == hostname {
+			return val.ActiveGateway == activeGateway && slices.EqualFunc(val.GatewayProbes, probes, probeStatusEqual)
+		}
+	}
+	return false
+}
+
+// probeStatusEqual compares the health of two gateways, the times are compared by value as the status is read back
+// from the API server in a different location
+func probeStatusEqual(a, b staticroutev1.GatewayProbeStatus) bool {
+	return a.Gateway == b.Gateway && a.Healthy == b.Healthy && a.Message == b.Message && a.Since.Equal(b.Since)
+}
+
+// setProbeStatus overwrites the active gateway and the health of the candidate gateways in the node status,
+// returns false if the node is not in the status
+func (rw *routeWrapper) setProbeStatus(hostname, activeGateway string, probes []staticroutev1.GatewayProbeStatus) bool {
+	for i := range rw.instance.Status.NodeStatus {
+		if rw.instance.Status.NodeStatus[i].Hostname == hostname {
+			rw.instance.Status.NodeStatus[i].ActiveGateway = activeGateway
+			rw.instance.Status.NodeStatus[i].GatewayProbes = probes
+			return true
+		}
+	}
+	return false
+}
+
+// activeGateway returns the candidate gateway the route is installed through on the node, empty if there is none
+func (rw *routeWrapper) activeGateway(hostname string) string {
+	for _, val := range rw.instance.Status.NodeStatus {
+		if val.Hostname == hostname {
+			return val.ActiveGateway
+		}
+	}
+	return ""
+}
+
+// failedSubnets counts the subnets reported with an error
+func failedSubnets(subnets []staticroutev1.SubnetStatus) int {
+	failed := 0
+	for _, subnet := range subnets {
+		if subnet.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+// tamperStatus returns the tamper related fields of the node status
+func (rw *routeWrapper) tamperStatus(hostname string) (*metav1.Time, int) {
+	for _, val := range rw.instance.Status.NodeStatus {
+		if val.Hostname == hostname {
+			return val.TamperedAt, val.TamperCount
+		}
+	}
+	return nil, 0
+}
+
+// setTamperStatus overwrites the tamper related fields of the node status, returns false if the node is not in the status
+func (rw *routeWrapper) setTamperStatus(hostname string, tamperedAt *metav1.Time, tamperCount int) bool {
+	for i := range rw.instance.Status.NodeStatus {
+		if rw.instance.Status.NodeStatus[i].Hostname == hostname {
+			rw.instance.Status.NodeStatus[i].TamperedAt = tamperedAt
+			rw.instance.Status.NodeStatus[i].TamperCount = tamperCount
+			return true
+		}
+	}
+	return false
+}
+
+// setObservedGeneration records in the node status that the node reconciled the current generation of the spec,
+// returns true if the node status was behind
+func (rw *routeWrapper) setObservedGeneration(hostname string) bool {
+	generation := rw.instance.GetGeneration()
+	for i := range rw.instance.Status.NodeStatus {
+		if rw.instance.Status.NodeStatus[i].Hostname == hostname && rw.instance.Status.NodeStatus[i].ObservedGeneration != generation {
+			rw.instance.Status.NodeStatus[i].ObservedGeneration = generation
+			return true
+		}
+	}
+	return false
+}
+
 func (rw *routeWrapper) alreadyInStatus(hostname string) bool {
 	for _, val := range rw.instance.Status.NodeStatus {
 		if val.Hostname == hostname {
@@ -139,7 +436,51 @@ func (rw *routeWrapper) removeFromStatus(hostname string) (existed bool) {
 		statusArr = append(statusArr, *valCopy)
 	}
 
-	rw.instance.Status = staticroutev1.StaticRouteStatus{NodeStatus: statusArr}
+	rw.instance.Status.NodeStatus = statusArr
 
 	return
 }
+
+// setSummary computes the node counters and the conditions from the node statuses, returns true if they changed.
+// Only the nodes which reported the current generation of the spec are counted, the others are pending, and the
+// observed generation is raised only when every reporting node is at it. So the summary is the same whichever node
+// computes it.
+func (rw *routeWrapper) setSummary(desiredNodes int) bool {
+	status := &rw.instance.Status
+	old := status.DeepCopy()
+	generation := rw.instance.GetGeneration()
+
+	status.DesiredNodes = desiredNodes
+	status.AppliedNodes, status.FailedNodes = 0, 0
+	outdated := 0
+	for _, val := range status.NodeStatus {
+		if val.ObservedGeneration != generation {
+			outdated++
+		} else if val.Error == "" {
+			status.AppliedNodes++
+		} else {
+			status.FailedNodes++
+		}
+	}
+	if outdated == 0 {
+		status.ObservedGeneration = generation
+	}
+
+	message := fmt.Sprintf("%d of %d nodes applied the route, %d failed", status.AppliedNodes, status.DesiredNodes, status.FailedNodes)
+	ready := metav1.Condition{Type: staticroutev1.ConditionReady, Status: metav1.ConditionTrue, Reason: "RouteApplied", Message: message, ObservedGeneration: generation}
+	progressing := metav1.Condition{Type: staticroutev1.ConditionProgressing, Status: metav1.ConditionFalse, Reason: "AllNodesReported", Message: message, ObservedGeneration: generation}
+	degraded := metav1.Condition{Type: staticroutev1.ConditionDegraded, Status: metav1.ConditionFalse, Reason: "NoFailures", Message: message, ObservedGeneration: generation}
+	if status.AppliedNodes+status.FailedNodes < status.DesiredNodes || outdated != 0 {
+		ready.Status, ready.Reason = metav1.ConditionFalse, "NodesPending"
+		progressing.Status, progressing.Reason = metav1.ConditionTrue, "NodesPending"
+	}
+	if status.FailedNodes > 0 {
+		ready.Status, ready.Reason = metav1.ConditionFalse, "NodesFailed"
+		degraded.Status, degraded.Reason = metav1.ConditionTrue, "NodesFailed"
+	}
+	meta.SetStatusCondition(&status.Conditions, ready)
+	meta.SetStatusCondition(&status.Conditions, progressing)
+	meta.SetStatusCondition(&status.Conditions, degraded)
+
+	return !equality.Semantic.DeepEqual(old, status)
+}
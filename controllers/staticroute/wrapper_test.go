@@ -18,10 +18,17 @@ package staticroute
 
 import (
 	"errors"
+	"fmt"
 	"net"
+	"reflect"
 	"testing"
+	"time"
 
 	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/controllers/node"
+	"github.com/IBM/staticroute-operator/pkg/cidr"
+	"golang.org/x/sys/unix"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -58,12 +65,48 @@ func TestIsProtected(t *testing.T) {
 			},
 			true,
 		},
+		{
+			[]*net.IPNet{&net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.IPv4Mask(0xff, 0, 0, 0)}},
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet: "0.0.0.0/0",
+				},
+			},
+			true,
+		},
+		{
+			[]*net.IPNet{&net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.IPv4Mask(0xff, 0, 0, 0)}},
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet: "11.0.0.0/8",
+				},
+			},
+			false,
+		},
+		{
+			[]*net.IPNet{&net.IPNet{IP: net.ParseIP("fd00::"), Mask: net.CIDRMask(8, 128)}},
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet: "fd00:1::/64",
+				},
+			},
+			true,
+		},
+		{
+			[]*net.IPNet{&net.IPNet{IP: net.IP{0, 0, 0, 0}, Mask: net.IPv4Mask(0, 0, 0, 0)}},
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet: "fd00:1::/64",
+				},
+			},
+			false,
+		},
 	}
 
 	for i, td := range testData {
 		rw := routeWrapper{instance: td.route}
 
-		res := rw.isProtected(td.protecteds)
+		res := rw.isProtected(cidr.NewSet(td.protecteds...))
 
 		if res != td.result {
 			t.Errorf("Result must be %t, it is %t at %d", td.result, res, i)
@@ -72,9 +115,12 @@ func TestIsProtected(t *testing.T) {
 }
 
 func TestIsChanged(t *testing.T) {
+	weight := 2
+	metric := int64(100)
 	var testData = []struct {
 		hostname  string
 		gateway   string
+		nextHops  []staticroutev1.NextHop
 		selectors []metav1.LabelSelectorRequirement
 		route     *staticroutev1.StaticRoute
 		result    bool
@@ -83,6 +129,7 @@ func TestIsChanged(t *testing.T) {
 			"hostname",
 			"gateway",
 			nil,
+			nil,
 			&staticroutev1.StaticRoute{
 				Spec: staticroutev1.StaticRouteSpec{
 					Subnet: "subnet",
@@ -94,6 +141,7 @@ func TestIsChanged(t *testing.T) {
 			"hostname",
 			"gateway",
 			nil,
+			nil,
 			&staticroutev1.StaticRoute{
 				Spec: staticroutev1.StaticRouteSpec{
 					Subnet: "subnet",
@@ -116,6 +164,7 @@ func TestIsChanged(t *testing.T) {
 			"hostname",
 			"gateway2",
 			nil,
+			nil,
 			&staticroutev1.StaticRoute{
 				Spec: staticroutev1.StaticRouteSpec{
 					Subnet: "subnet",
@@ -138,6 +187,7 @@ func TestIsChanged(t *testing.T) {
 			"hostname",
 			"gateway",
 			nil,
+			nil,
 			&staticroutev1.StaticRoute{
 				Spec: staticroutev1.StaticRouteSpec{
 					Subnet: "subnet2",
@@ -160,6 +210,7 @@ func TestIsChanged(t *testing.T) {
 			"hostname",
 			"gateway2",
 			nil,
+			nil,
 			&staticroutev1.StaticRoute{
 				Spec: staticroutev1.StaticRouteSpec{
 					Subnet: "subnet2",
@@ -182,6 +233,7 @@ func TestIsChanged(t *testing.T) {
 			"hostname",
 			"gateway2",
 			nil,
+			nil,
 			&staticroutev1.StaticRoute{
 				Spec: staticroutev1.StaticRouteSpec{
 					Subnet: "subnet2",
@@ -211,6 +263,7 @@ func TestIsChanged(t *testing.T) {
 			"hostname",
 			"gateway",
 			nil,
+			nil,
 			&staticroutev1.StaticRoute{
 				Spec: staticroutev1.StaticRouteSpec{
 					Subnet: "subnet",
@@ -239,8 +292,9 @@ func TestIsChanged(t *testing.T) {
 		{
 			"hostname",
 			"gateway",
+			nil,
 			[]metav1.LabelSelectorRequirement{metav1.LabelSelectorRequirement{
-				Key:      HostNameLabel,
+				Key:      node.HostNameLabel,
 				Operator: metav1.LabelSelectorOpIn,
 				Values:   []string{"hostname"},
 			}},
@@ -262,12 +316,276 @@ func TestIsChanged(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"hostname",
+			"",
+			[]staticroutev1.NextHop{{Gateway: "gateway"}, {Gateway: "gateway2"}},
+			nil,
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet: "subnet",
+				},
+				Status: staticroutev1.StaticRouteStatus{
+					NodeStatus: []staticroutev1.StaticRouteNodeStatus{
+						staticroutev1.StaticRouteNodeStatus{
+							Hostname: "hostname",
+							State: staticroutev1.StaticRouteSpec{
+								Subnet:   "subnet",
+								Gateways: []staticroutev1.NextHop{{Gateway: "gateway"}, {Gateway: "gateway2"}},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"hostname",
+			"",
+			[]staticroutev1.NextHop{{Gateway: "gateway"}, {Gateway: "gateway2", Weight: &weight}},
+			nil,
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet: "subnet",
+				},
+				Status: staticroutev1.StaticRouteStatus{
+					NodeStatus: []staticroutev1.StaticRouteNodeStatus{
+						staticroutev1.StaticRouteNodeStatus{
+							Hostname: "hostname",
+							State: staticroutev1.StaticRouteSpec{
+								Subnet:   "subnet",
+								Gateways: []staticroutev1.NextHop{{Gateway: "gateway"}, {Gateway: "gateway2"}},
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+		{
+			"hostname",
+			"",
+			[]staticroutev1.NextHop{{Gateway: "gateway"}},
+			nil,
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet: "subnet",
+				},
+				Status: staticroutev1.StaticRouteStatus{
+					NodeStatus: []staticroutev1.StaticRouteNodeStatus{
+						staticroutev1.StaticRouteNodeStatus{
+							Hostname: "hostname",
+							State: staticroutev1.StaticRouteSpec{
+								Subnet:   "subnet",
+								Gateways: []staticroutev1.NextHop{{Gateway: "gateway"}, {Gateway: "gateway2"}},
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+		{
+			"hostname",
+			"gateway",
+			nil,
+			nil,
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet: "subnet",
+					Metric: &metric,
+				},
+				Status: staticroutev1.StaticRouteStatus{
+					NodeStatus: []staticroutev1.StaticRouteNodeStatus{
+						staticroutev1.StaticRouteNodeStatus{
+							Hostname: "hostname",
+							State: staticroutev1.StaticRouteSpec{
+								Subnet:  "subnet",
+								Gateway: "gateway",
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+		{
+			"hostname",
+			"",
+			nil,
+			nil,
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet: "subnet",
+					Type:   staticroutev1.RouteTypeBlackhole,
+				},
+				Status: staticroutev1.StaticRouteStatus{
+					NodeStatus: []staticroutev1.StaticRouteNodeStatus{
+						staticroutev1.StaticRouteNodeStatus{
+							Hostname: "hostname",
+							State: staticroutev1.StaticRouteSpec{
+								Subnet: "subnet",
+								Type:   staticroutev1.RouteTypeUnreachable,
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+		{
+			"hostname",
+			"gateway",
+			nil,
+			nil,
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet: "subnet",
+					Type:   staticroutev1.RouteTypeUnicast,
+				},
+				Status: staticroutev1.StaticRouteStatus{
+					NodeStatus: []staticroutev1.StaticRouteNodeStatus{
+						staticroutev1.StaticRouteNodeStatus{
+							Hostname: "hostname",
+							State: staticroutev1.StaticRouteSpec{
+								Subnet:  "subnet",
+								Gateway: "gateway",
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"hostname",
+			"gateway",
+			nil,
+			nil,
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet:    "subnet",
+					Interface: "eth1",
+				},
+				Status: staticroutev1.StaticRouteStatus{
+					NodeStatus: []staticroutev1.StaticRouteNodeStatus{
+						staticroutev1.StaticRouteNodeStatus{
+							Hostname: "hostname",
+							State: staticroutev1.StaticRouteSpec{
+								Subnet:    "subnet",
+								Gateway:   "gateway",
+								Interface: "eth0",
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+		{
+			"hostname",
+			"gateway",
+			nil,
+			nil,
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet: "subnet",
+					Src:    "10.0.0.5",
+				},
+				Status: staticroutev1.StaticRouteStatus{
+					NodeStatus: []staticroutev1.StaticRouteNodeStatus{
+						staticroutev1.StaticRouteNodeStatus{
+							Hostname: "hostname",
+							State: staticroutev1.StaticRouteSpec{
+								Subnet:  "subnet",
+								Gateway: "gateway",
+								Src:     "10.0.0.6",
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+		{
+			"hostname",
+			"gateway",
+			nil,
+			nil,
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet: "subnet",
+					MTU:    1400,
+				},
+				Status: staticroutev1.StaticRouteStatus{
+					NodeStatus: []staticroutev1.StaticRouteNodeStatus{
+						staticroutev1.StaticRouteNodeStatus{
+							Hostname: "hostname",
+							State: staticroutev1.StaticRouteSpec{
+								Subnet:  "subnet",
+								Gateway: "gateway",
+								MTU:     1500,
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+		{
+			"hostname",
+			"gateway",
+			nil,
+			nil,
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnet: "subnet",
+					OnLink: true,
+				},
+				Status: staticroutev1.StaticRouteStatus{
+					NodeStatus: []staticroutev1.StaticRouteNodeStatus{
+						staticroutev1.StaticRouteNodeStatus{
+							Hostname: "hostname",
+							State: staticroutev1.StaticRouteSpec{
+								Subnet:  "subnet",
+								Gateway: "gateway",
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
+		{
+			"hostname",
+			"gateway",
+			nil,
+			nil,
+			&staticroutev1.StaticRoute{
+				Spec: staticroutev1.StaticRouteSpec{
+					Subnets: []string{"subnet1", "subnet2"},
+					Gateway: "gateway",
+				},
+				Status: staticroutev1.StaticRouteStatus{
+					NodeStatus: []staticroutev1.StaticRouteNodeStatus{
+						staticroutev1.StaticRouteNodeStatus{
+							Hostname: "hostname",
+							State: staticroutev1.StaticRouteSpec{
+								Subnets: []string{"subnet1"},
+								Gateway: "gateway",
+							},
+						},
+					},
+				},
+			},
+			true,
+		},
 	}
 
 	for i, td := range testData {
 		rw := routeWrapper{instance: td.route}
 
-		res := rw.isChanged(td.hostname, td.gateway, td.selectors)
+		res := rw.isChanged(td.hostname, td.gateway, td.nextHops, td.selectors)
 
 		if res != td.result {
 			t.Errorf("Result must be %t, it is %t at %d", td.result, res, i)
@@ -275,6 +593,63 @@ func TestIsChanged(t *testing.T) {
 	}
 }
 
+func TestRouteType(t *testing.T) {
+	var testData = []struct {
+		routeType string
+		result    int
+	}{
+		{"", 0},
+		{staticroutev1.RouteTypeUnicast, 0},
+		{staticroutev1.RouteTypeBlackhole, unix.RTN_BLACKHOLE},
+		{staticroutev1.RouteTypeUnreachable, unix.RTN_UNREACHABLE},
+		{staticroutev1.RouteTypeProhibit, unix.RTN_PROHIBIT},
+		{staticroutev1.RouteTypeThrow, unix.RTN_THROW},
+	}
+	for _, td := range testData {
+		if res := routeType(td.routeType); res != td.result {
+			t.Errorf("Result must be %d, it is %d for %q", td.result, res, td.routeType)
+		}
+	}
+}
+
+func TestRouteMetricsEqual(t *testing.T) {
+	spec := staticroutev1.StaticRouteSpec{MTU: 1400, AdvMSS: 1360, InitCwnd: 10, InitRwnd: 20, HopLimit: 32}
+	for i, modify := range []func(*staticroutev1.StaticRouteSpec){
+		func(s *staticroutev1.StaticRouteSpec) { s.MTU = 0 },
+		func(s *staticroutev1.StaticRouteSpec) { s.AdvMSS = 1460 },
+		func(s *staticroutev1.StaticRouteSpec) { s.InitCwnd = 1 },
+		func(s *staticroutev1.StaticRouteSpec) { s.InitRwnd = 1 },
+		func(s *staticroutev1.StaticRouteSpec) { s.HopLimit = 64 },
+	} {
+		other := spec
+		modify(&other)
+		if routeMetricsEqual(spec, other) {
+			t.Errorf("Metrics must differ at %d", i)
+		}
+	}
+	if !routeMetricsEqual(spec, spec) {
+		t.Error("Metrics must be equal")
+	}
+}
+
+func TestRouteScope(t *testing.T) {
+	var testData = []struct {
+		scope  string
+		result int
+	}{
+		{"", 0},
+		{staticroutev1.ScopeUniverse, 0},
+		{staticroutev1.ScopeSite, unix.RT_SCOPE_SITE},
+		{staticroutev1.ScopeLink, unix.RT_SCOPE_LINK},
+		{staticroutev1.ScopeHost, unix.RT_SCOPE_HOST},
+	}
+	for _, td := range testData {
+		if res := routeScope(td.scope); res != td.result {
+			t.Errorf("Result must be %d, it is %d for %q", td.result, res, td.scope)
+		}
+	}
+}
+
 func TestRouteWrapperSetFinalizer(t *testing.T) {
 	route := newStaticRouteWithValues(true, false)
 	rw := routeWrapper{instance: route}
@@ -339,7 +714,7 @@ func TestRouteWrapperAddToStatus(t *testing.T) {
 	route := newStaticRouteWithValues(false, false)
 	rw := routeWrapper{instance: route}
 
-	added := rw.addToStatus("hostname", net.IP{10, 0, 0, 1}, errors.New("failure"))
+	added := rw.addToStatus("hostname", net.IP{10, 0, 0, 1}, nil, errors.New("failure"))
 
 	if !added {
 		t.Error("Status must be added")
@@ -365,7 +740,7 @@ func TestRouteWrapperAddToStatusNotAdded(t *testing.T) {
 	}
 	rw := routeWrapper{instance: route}
 
-	added := rw.addToStatus("hostname", net.IP{10, 0, 0, 1}, nil)
+	added := rw.addToStatus("hostname", net.IP{10, 0, 0, 1}, nil, nil)
 
 	if added {
 		t.Error("Status must be not added")
@@ -409,3 +784,225 @@ func TestRouteWrapperRemoveFromStatusRemoved(t *testing.T) {
 		t.Errorf("Statuses must be empty: %v", route.Status.NodeStatus)
 	}
 }
+
+func TestRouteWrapperTamperStatus(t *testing.T) {
+	route := newStaticRouteWithValues(true, true)
+	rw := routeWrapper{instance: route}
+	tampered := metav1.Now()
+
+	if rw.setTamperStatus("hostname2", &tampered, 1) {
+		t.Error("Tamper status must not be set for unknown node")
+	}
+	if !rw.setTamperStatus("hostname", &tampered, 1) {
+		t.Error("Tamper status must be set")
+	}
+	if tamperedAt, tamperCount := rw.tamperStatus("hostname"); tamperedAt != &tampered || tamperCount != 1 {
+		t.Errorf("Tamper status must be returned: %v, %d", tamperedAt, tamperCount)
+	}
+	if tamperedAt, tamperCount := rw.tamperStatus("hostname2"); tamperedAt != nil || tamperCount != 0 {
+		t.Errorf("Tamper status must be empty for unknown node: %v, %d", tamperedAt, tamperCount)
+	}
+}
+
+func TestRouteWrapperSubnetStatus(t *testing.T) {
+	route := newStaticRouteWithValues(true, true)
+	rw := routeWrapper{instance: route}
+	subnets := []staticroutev1.SubnetStatus{{Subnet: "10.1.0.0/16"}, {Subnet: "10.2.0.0/16", Error: "failed"}}
+
+	if !rw.subnetStatusMatch("hostname", nil) || rw.subnetStatusMatch("hostname", subnets) {
+		t.Error("Subnet status must be empty")
+	}
+	if rw.setSubnetStatus("hostname2", subnets) {
+		t.Error("Subnet status must not be set for unknown node")
+	}
+	if !rw.setSubnetStatus("hostname", subnets) || !rw.subnetStatusMatch("hostname", subnets) {
+		t.Errorf("Subnet status must be set: %+v", route.Status.NodeStatus[0].Subnets)
+	}
+	if rw.subnetStatusMatch("hostname2", subnets) {
+		t.Error("Subnet status must not match for unknown node")
+	}
+	if failed := failedSubnets(subnets); failed != 1 {
+		t.Errorf("One subnet must be failed: %d", failed)
+	}
+}
+
+func TestRouteWrapperProbeStatus(t *testing.T) {
+	route := newStaticRouteWithValues(true, true)
+	rw := routeWrapper{instance: route}
+	since := metav1.NewTime(time.Date(2026, time.Month(3), 1, 10, 0, 0, 0, time.UTC))
+	probes := []staticroutev1.GatewayProbeStatus{{Gateway: "10.0.0.1", Message: "timeout", Since: &since}, {Gateway: "10.0.0.2", Healthy: true}}
+
+	if !rw.probeStatusMatch("hostname", "", nil) || rw.probeStatusMatch("hostname", "10.0.0.2", probes) {
+		t.Error("Probe status must be empty")
+	}
+	if rw.setProbeStatus("hostname2", "10.0.0.2", probes) {
+		t.Error("Probe status must not be set for unknown node")
+	}
+	if !rw.setProbeStatus("hostname", "10.0.0.2", probes) || rw.activeGateway("hostname") != "10.0.0.2" {
+		t.Errorf("Probe status must be set: %+v", route.Status.NodeStatus[0])
+	}
+	local := metav1.NewTime(since.Local())
+	if !rw.probeStatusMatch("hostname", "10.0.0.2", []staticroutev1.GatewayProbeStatus{{Gateway: "10.0.0.1", Message: "timeout", Since: &local}, probes[1]}) {
+		t.Error("Probe status must match regardless of the location of the time")
+	}
+	if rw.probeStatusMatch("hostname", "10.0.0.1", probes) || rw.activeGateway("hostname2") != "" {
+		t.Error("Probe status must not match for a different active gateway")
+	}
+}
+
+func TestRouteKey(t *testing.T) {
+	key := routeKey("CR", "10.0.0.0/16")
+
+	if key != "CR/10.0.0.0/16" {
+		t.Errorf("Key must contain the name and the subnet: %s", key)
+	}
+	if owner := routeOwner(key); owner != "CR" {
+		t.Errorf("Owner must be CR: %s", owner)
+	}
+	if owner := routeOwner("CR"); owner != "CR" {
+		t.Errorf("Owner of a single subnet route must be CR: %s", owner)
+	}
+}
+
+func TestRouteWrapperManagedRoutes(t *testing.T) {
+	var testData = []struct {
+		spec   staticroutev1.StaticRouteSpec
+		state  staticroutev1.StaticRouteSpec
+		routes []managedRoute
+	}{
+		{
+			staticroutev1.StaticRouteSpec{Subnet: "10.0.0.0/16"},
+			staticroutev1.StaticRouteSpec{Subnet: "10.0.0.0/16"},
+			[]managedRoute{{"CR", "10.0.0.0/16"}},
+		},
+		{
+			staticroutev1.StaticRouteSpec{Subnets: []string{"10.1.0.0/16", "10.2.0.0/16"}},
+			staticroutev1.StaticRouteSpec{Subnets: []string{"10.2.0.0/16", "10.3.0.0/16"}},
+			[]managedRoute{{"CR/10.1.0.0/16", "10.1.0.0/16"}, {"CR/10.2.0.0/16", "10.2.0.0/16"}, {"CR/10.3.0.0/16", "10.3.0.0/16"}},
+		},
+		{
+			staticroutev1.StaticRouteSpec{Subnets: []string{"10.1.0.0/16"}},
+			staticroutev1.StaticRouteSpec{Subnet: "10.0.0.0/16"},
+			[]managedRoute{{"CR/10.1.0.0/16", "10.1.0.0/16"}, {"CR", "10.0.0.0/16"}},
+		},
+	}
+	for i, td := range testData {
+		route := newStaticRouteWithValues(false, false)
+		route.Spec = td.spec
+		route.Status.NodeStatus = []staticroutev1.StaticRouteNodeStatus{{Hostname: "hostname", State: td.state}, {Hostname: "other", State: staticroutev1.StaticRouteSpec{Subnet: "10.9.0.0/16"}}}
+		rw := routeWrapper{instance: route}
+
+		routes := rw.managedRoutes("CR", "hostname")
+
+		if !reflect.DeepEqual(routes, td.routes) {
+			t.Errorf("Routes must be %v, they are %v at %d", td.routes, routes, i)
+		}
+	}
+}
+
+func TestRouteWrapperAddToStatusMultiPath(t *testing.T) {
+	route := newStaticRouteWithValues(false, false)
+	route.Spec.Gateways = []staticroutev1.NextHop{{Gateway: "10.0.0.1"}, {Gateway: "10.0.0.2"}}
+	rw := routeWrapper{instance: route}
+
+	added := rw.addToStatus("hostname", nil, []staticroutev1.NextHop{{Gateway: "10.0.0.2"}}, nil)
+
+	if !added {
+		t.Error("Status must be added")
+	} else if route.Status.NodeStatus[0].State.Gateway != "" {
+		t.Errorf("Gateway must be empty for multipath routes: %s", route.Status.NodeStatus[0].State.Gateway)
+	} else if len(route.Status.NodeStatus[0].State.Gateways) != 1 || route.Status.NodeStatus[0].State.Gateways[0].Gateway != "10.0.0.2" {
+		t.Errorf("Only the installed next hops must be reported: %v", route.Status.NodeStatus[0].State.Gateways)
+	} else if len(route.Spec.Gateways) != 2 {
+		t.Errorf("Spec must not be modified: %v", route.Spec.Gateways)
+	}
+}
+
+func TestRouteWrapperRemoveFromStatusKeepsSummary(t *testing.T) {
+	route := newStaticRouteWithValues(true, true)
+	route.Status.DesiredNodes = 3
+	route.Status.Conditions = []metav1.Condition{{Type: staticroutev1.ConditionReady, Status: metav1.ConditionTrue}}
+	rw := routeWrapper{instance: route}
+
+	rw.removeFromStatus("hostname")
+
+	if route.Status.DesiredNodes != 3 || len(route.Status.Conditions) != 1 {
+		t.Errorf("Summary must be kept: %v", route.Status)
+	}
+}
+
+func TestRouteWrapperSetSummary(t *testing.T) {
+	var testData = []struct {
+		name        string
+		errors      []string
+		desired     int
+		ready       metav1.ConditionStatus
+		progressing metav1.ConditionStatus
+		degraded    metav1.ConditionStatus
+	}{
+		{"all applied", []string{"", ""}, 2, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse},
+		{"no nodes", nil, 0, metav1.ConditionTrue, metav1.ConditionFalse, metav1.ConditionFalse},
+		{"pending", []string{""}, 2, metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionFalse},
+		{"failed", []string{"", "error"}, 2, metav1.ConditionFalse, metav1.ConditionFalse, metav1.ConditionTrue},
+		{"pending and failed", []string{"error"}, 2, metav1.ConditionFalse, metav1.ConditionTrue, metav1.ConditionTrue},
+	}
+	for _, td := range testData {
+		route := newStaticRouteWithValues(true, false)
+		route.Generation = 4
+		for i, e := range td.errors {
+			route.Status.NodeStatus = append(route.Status.NodeStatus, staticroutev1.StaticRouteNodeStatus{Hostname: fmt.Sprintf("node%d", i), Error: e, ObservedGeneration: 4})
+		}
+		rw := routeWrapper{instance: route}
+
+		if !rw.setSummary(td.desired) {
+			t.Errorf("Summary must be changed at %s", td.name)
+		}
+		if rw.setSummary(td.desired) {
+			t.Errorf("Summary must not be changed twice at %s", td.name)
+		}
+
+		status := route.Status
+		if status.ObservedGeneration != 4 || status.DesiredNodes != td.desired || status.AppliedNodes+status.FailedNodes != len(td.errors) {
+			t.Errorf("Counters are wrong at %s: %v", td.name, status)
+		}
+		for conditionType, expected := range map[string]metav1.ConditionStatus{
+			staticroutev1.ConditionReady:       td.ready,
+			staticroutev1.ConditionProgressing: td.progressing,
+			staticroutev1.ConditionDegraded:    td.degraded,
+		} {
+			condition := meta.FindStatusCondition(status.Conditions, conditionType)
+			if condition == nil || condition.Status != expected || condition.ObservedGeneration != 4 {
+				t.Errorf("Condition %s must be %s at %s: %v", conditionType, expected, td.name, condition)
+			}
+		}
+	}
+}
+
+func TestRouteWrapperSetSummaryOutdatedNode(t *testing.T) {
+	route := newStaticRouteWithValues(true, false)
+	route.Generation = 5
+	route.Status.ObservedGeneration = 4
+	route.Status.NodeStatus = []staticroutev1.StaticRouteNodeStatus{
+		{Hostname: "node0", ObservedGeneration: 5},
+		{Hostname: "node1", ObservedGeneration: 4},
+	}
+	rw := routeWrapper{instance: route}
+
+	rw.setSummary(2)
+
+	if route.Status.ObservedGeneration != 4 || route.Status.AppliedNodes != 1 {
+		t.Errorf("Generation must not be raised before every node is at it: %d %d", route.Status.ObservedGeneration, route.Status.AppliedNodes)
+	}
+	if !meta.IsStatusConditionFalse(route.Status.Conditions, staticroutev1.ConditionReady) || !meta.IsStatusConditionTrue(route.Status.Conditions, staticroutev1.ConditionProgressing) {
+		t.Errorf("Route must not be ready while a node is behind: %v", route.Status.Conditions)
+	}
+
+	if !rw.setObservedGeneration("node1") || rw.setObservedGeneration("node1") {
+		t.Error("Generation of the node must be raised once")
+	}
+	rw.setSummary(2)
+
+	if route.Status.ObservedGeneration != 5 || route.Status.AppliedNodes != 2 || !meta.IsStatusConditionTrue(route.Status.Conditions, staticroutev1.ConditionReady) {
+		t.Errorf("Route must be ready at the generation of every node: %v", route.Status)
+	}
+}
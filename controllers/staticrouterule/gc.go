@@ -0,0 +1,73 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticrouterule
+
+import (
+	"context"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/pkg/rulemanager"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// garbageCollector runs once at startup. It re-adopts the rules left in the kernel by a previous run of the operator
+// which still belong to a StaticRouteRule, and removes the ones whose StaticRouteRule was deleted meanwhile.
+type garbageCollector struct {
+	reader  client.Reader
+	options ManagerOptions
+}
+
+// blank assignment to verify that garbageCollector implements manager.Runnable
+var _ manager.Runnable = &garbageCollector{}
+
+// NeedLeaderElection is false, as every node has to clean up its own rules
+func (gc *garbageCollector) NeedLeaderElection() bool {
+	return false
+}
+
+func (gc *garbageCollector) Start(ctx context.Context) error {
+	rules := &staticroutev1.StaticRouteRuleList{}
+	if err := gc.reader.List(ctx, rules); err != nil {
+		log.Error(err, "Unable to fetch StaticRouteRules for garbage collection")
+		return nil
+	}
+	if err := gc.options.RuleManager.CollectGarbage(gc.adopter(rules)); err != nil {
+		log.Error(err, "Unable to remove orphaned rules")
+	}
+	return nil
+}
+
+// adopter returns the name of the StaticRouteRule which reports the given rule as installed on this node
+func (gc *garbageCollector) adopter(rules *staticroutev1.StaticRouteRuleList) func(rulemanager.Rule) (string, bool) {
+	return func(rule rulemanager.Rule) (string, bool) {
+		for i := range rules.Items {
+			for _, status := range rules.Items[i].Status.NodeStatus {
+				if status.Hostname != gc.options.Hostname || status.Error != "" {
+					continue
+				}
+				rw := ruleWrapper{instance: &staticroutev1.StaticRouteRule{Spec: status.State}}
+				if state, err := rw.toRule(); err == nil && state.Equal(rule) {
+					log.Info("Adopting rule", "Request.Name", rules.Items[i].Name, "Priority", rule.Priority)
+					return rules.Items[i].Name, true
+				}
+			}
+		}
+		log.Info("Removing orphaned rule", "Priority", rule.Priority, "Table", rule.Table)
+		return "", false
+	}
+}
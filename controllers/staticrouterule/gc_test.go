@@ -0,0 +1,111 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticrouterule
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/pkg/rulemanager"
+)
+
+func TestGarbageCollectorAdopter(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(true)
+	failed := newStaticRouteRuleWithValues(false)
+	failed.Name = "failed"
+	failed.Spec.Priority = 1001
+	failed.Status.NodeStatus = []staticroutev1.StaticRouteRuleNodeStatus{{Hostname: "hostname", State: failed.Spec, Error: "failed"}}
+	other := newStaticRouteRuleWithValues(false)
+	other.Name = "other"
+	other.Spec.Priority = 1002
+	other.Status.NodeStatus = []staticroutev1.StaticRouteRuleNodeStatus{{Hostname: "other", State: other.Spec}}
+	gc := garbageCollector{options: ManagerOptions{Hostname: "hostname"}}
+	adopt := gc.adopter(&staticroutev1.StaticRouteRuleList{Items: []staticroutev1.StaticRouteRule{*rule, *failed, *other}})
+	_, src, _ := net.ParseCIDR("10.0.0.0/8")
+
+	var testData = []struct {
+		priority int
+		table    int
+		mark     uint32
+		adopted  bool
+	}{
+		{1000, 100, 100, true},
+		{1000, 101, 100, false},
+		{1000, 100, 0, false},
+		{1001, 100, 100, false},
+		{1002, 100, 100, false},
+	}
+	for i, td := range testData {
+		name, adopted := adopt(rulemanager.Rule{Src: src, Mark: td.mark, Priority: td.priority, Table: td.table})
+
+		if adopted != td.adopted || (adopted && name != "CR") {
+			t.Errorf("Result must be %t, it is %t (%s) at %d", td.adopted, adopted, name, i)
+		}
+	}
+}
+
+func TestGarbageCollectorStart(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(true)
+	called := false
+	gc := garbageCollector{
+		reader: newFakeClient(rule),
+		options: ManagerOptions{
+			Hostname: "hostname",
+			RuleManager: ruleManagerMock{
+				collectGarbageCallback: func(adopt func(rulemanager.Rule) (string, bool)) error {
+					called = true
+					_, src, _ := net.ParseCIDR("10.0.0.0/8")
+					if name, ok := adopt(rulemanager.Rule{Src: src, Mark: 100, Priority: 1000, Table: 100}); !ok || name != "CR" {
+						t.Error("Rule of the existing CR must be adopted")
+					}
+					return errors.New("failed")
+				},
+			},
+		},
+	}
+
+	if err := gc.Start(context.Background()); err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if !called {
+		t.Error("CollectGarbage must be called")
+	}
+	if gc.NeedLeaderElection() {
+		t.Error("Garbage collection must run on every node")
+	}
+}
+
+func TestGarbageCollectorStartListFails(t *testing.T) {
+	gc := garbageCollector{
+		reader: reconcileImplClientMock{listErr: errors.New("failed")},
+		options: ManagerOptions{
+			RuleManager: ruleManagerMock{
+				collectGarbageCallback: func(func(rulemanager.Rule) (string, bool)) error {
+					t.Error("CollectGarbage must not be called without the StaticRouteRules")
+					return nil
+				},
+			},
+		},
+	}
+
+	if err := gc.Start(context.Background()); err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+}
@@ -0,0 +1,178 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticrouterule
+
+import (
+	"context"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/pkg/rulemanager"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type reconcileImplClientMock struct {
+	client          reconcileImplClient
+	statusWriteMock client.StatusWriter
+	getErr          error
+	updateErr       error
+	listErr         error
+}
+
+func (m reconcileImplClientMock) Get(ctx context.Context, key client.ObjectKey, obj client.Object, options ...client.GetOption) error {
+	if m.getErr != nil {
+		return m.getErr
+	}
+	return m.client.Get(ctx, key, obj, options...)
+}
+
+func (m reconcileImplClientMock) Update(ctx context.Context, obj client.Object, options ...client.UpdateOption) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	return m.client.Update(ctx, obj, options...)
+}
+
+func (m reconcileImplClientMock) List(ctx context.Context, list client.ObjectList, options ...client.ListOption) error {
+	if m.listErr != nil {
+		return m.listErr
+	}
+	return m.client.List(ctx, list, options...)
+}
+
+func (m reconcileImplClientMock) Status() client.StatusWriter {
+	if m.statusWriteMock != nil {
+		return m.statusWriteMock
+	}
+	return m.client.Status()
+}
+
+type statusWriterMock struct {
+	updateCounter int
+	updateErr     error
+}
+
+func (m *statusWriterMock) Create(context.Context, client.Object, client.Object, ...client.SubResourceCreateOption) error {
+	return nil
+}
+
+func (m *statusWriterMock) Update(context.Context, client.Object, ...client.SubResourceUpdateOption) error {
+	m.updateCounter = m.updateCounter + 1
+	return m.updateErr
+}
+
+func (m *statusWriterMock) Patch(context.Context, client.Object, client.Patch, ...client.SubResourcePatchOption) error {
+	return nil
+}
+
+type ruleManagerMock struct {
+	isRegistered           bool
+	registeredCallback     func(string, rulemanager.Rule) error
+	deRegisteredCallback   func(string) error
+	collectGarbageCallback func(func(rulemanager.Rule) (string, bool)) error
+	registerRuleErr        error
+	deRegisterRuleErr      error
+}
+
+func (m ruleManagerMock) IsRegistered(string) bool {
+	return m.isRegistered
+}
+
+func (m ruleManagerMock) RegisterRule(n string, r rulemanager.Rule) error {
+	if m.registeredCallback != nil {
+		return m.registeredCallback(n, r)
+	}
+	return m.registerRuleErr
+}
+
+func (m ruleManagerMock) DeRegisterRule(n string) error {
+	if m.deRegisteredCallback != nil {
+		return m.deRegisteredCallback(n)
+	}
+	return m.deRegisterRuleErr
+}
+
+func (m ruleManagerMock) CollectGarbage(adopt func(rulemanager.Rule) (string, bool)) error {
+	if m.collectGarbageCallback != nil {
+		return m.collectGarbageCallback(adopt)
+	}
+	return nil
+}
+
+func (m ruleManagerMock) RegisterWatcher(rulemanager.RuleWatcher) {
+}
+
+func (m ruleManagerMock) DeRegisterWatcher(rulemanager.RuleWatcher) {
+}
+
+func (m ruleManagerMock) Run(chan struct{}) error {
+	return nil
+}
+
+func newFakeClient(objs ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	s.AddKnownTypes(staticroutev1.GroupVersion, &staticroutev1.StaticRouteRule{}, &staticroutev1.StaticRouteRuleList{})
+	s.AddKnownTypes(corev1.SchemeGroupVersion, &corev1.Node{}, &corev1.NodeList{})
+	return fake.NewClientBuilder().
+		WithScheme(s).
+		WithStatusSubresource(&staticroutev1.StaticRouteRule{}).
+		WithObjects(objs...).
+		Build()
+}
+
+func newStaticRouteRuleWithValues(withStatus bool) *staticroutev1.StaticRouteRule {
+	mark := int64(100)
+	rule := &staticroutev1.StaticRouteRule{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "StaticRouteRule",
+			APIVersion: "static-route.ibm.com/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "CR",
+		},
+		Spec: staticroutev1.StaticRouteRuleSpec{
+			From:     "10.0.0.0/8",
+			FwMark:   &mark,
+			Priority: 1000,
+			Table:    100,
+		},
+	}
+	if withStatus {
+		rule.Status.NodeStatus = []staticroutev1.StaticRouteRuleNodeStatus{
+			{Hostname: "hostname", State: *rule.Spec.DeepCopy()},
+		}
+	}
+	return rule
+}
+
+// getReconcileContext returns the parameters to reconcile the given rule on the node called hostname
+func getReconcileContext(rule *staticroutev1.StaticRouteRule, isRegistered bool, objs ...client.Object) (*reconcileImplParams, *reconcileImplClientMock) {
+	mockClient := &reconcileImplClientMock{client: newFakeClient(append(objs, rule)...)}
+	return &reconcileImplParams{
+		request: reconcile.Request{NamespacedName: types.NamespacedName{Name: "CR"}},
+		client:  mockClient,
+		options: ManagerOptions{
+			Hostname:    "hostname",
+			RuleManager: ruleManagerMock{isRegistered: isRegistered},
+		},
+	}, mockClient
+}
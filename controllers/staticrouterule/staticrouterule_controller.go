@@ -0,0 +1,315 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticrouterule
+
+import (
+	"context"
+	"time"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/controllers/node"
+	"github.com/IBM/staticroute-operator/pkg/rulemanager"
+	"github.com/IBM/staticroute-operator/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("controller_staticrouterule")
+
+// ManagerOptions contains rule management related node properties
+type ManagerOptions struct {
+	RuleManager rulemanager.RuleManager
+	Hostname    string
+	// TamperReactionBackoff is the initial delay before re-creating a rule deleted by an external entity
+	TamperReactionBackoff time.Duration
+}
+
+// StaticRouteRuleReconciler reconciles a StaticRouteRule object
+type StaticRouteRuleReconciler struct {
+	// This client, initialized using mgr.Client() above, is a split client
+	// that reads objects from the cache and writes to the apiserver
+	client  client.Client
+	scheme  *runtime.Scheme
+	options ManagerOptions
+	watcher *ruleWatcher
+}
+
+// Add creates a new StaticRouteRule Controller and adds it to the Manager. The Manager will set fields on the Controller
+// and Start it when the Manager is Started.
+func Add(mgr manager.Manager, options ManagerOptions) error {
+	watcher := newRuleWatcher(options.RuleManager, options.TamperReactionBackoff)
+	options.RuleManager.RegisterWatcher(watcher)
+	if err := mgr.Add(&garbageCollector{reader: mgr.GetAPIReader(), options: options}); err != nil {
+		return err
+	}
+	return (&StaticRouteRuleReconciler{
+		client:  mgr.GetClient(),
+		scheme:  mgr.GetScheme(),
+		options: options,
+		watcher: watcher}).
+		SetupWithManager(mgr)
+}
+
+// blank assignment to verify that StaticRouteRuleReconciler implements reconcile.Reconciler
+var _ reconcile.Reconciler = &StaticRouteRuleReconciler{}
+
+// Reconcile reads that state of the cluster for a StaticRouteRule object and makes changes based on the state read
+// and what is in the StaticRouteRule.Spec
+func (r *StaticRouteRuleReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	params := reconcileImplParams{
+		request: request,
+		client:  r.client.(reconcileImplClient),
+		options: r.options,
+	}
+	result, err := reconcileImpl(params)
+	return *result, err
+}
+
+type reconcileImplClient interface {
+	Get(context.Context, client.ObjectKey, client.Object, ...client.GetOption) error
+	Update(context.Context, client.Object, ...client.UpdateOption) error
+	List(context.Context, client.ObjectList, ...client.ListOption) error
+	Status() client.StatusWriter
+}
+
+type reconcileImplParams struct {
+	request reconcile.Request
+	client  reconcileImplClient
+	options ManagerOptions
+}
+
+var (
+	crNotFound       = &reconcile.Result{}
+	nodeNotFound     = &reconcile.Result{}
+	alreadyDeleted   = &reconcile.Result{}
+	deletionFinished = &reconcile.Result{}
+	updateFinished   = &reconcile.Result{Requeue: true}
+	finished         = &reconcile.Result{}
+
+	crGetError           = &reconcile.Result{}
+	wrongSelectorErr     = &reconcile.Result{}
+	nodeGetError         = &reconcile.Result{}
+	deRegisterError      = &reconcile.Result{}
+	delStatusUpdateError = &reconcile.Result{}
+	emptyFinalizerError  = &reconcile.Result{}
+	setFinalizerError    = &reconcile.Result{}
+	invalidRuleError     = &reconcile.Result{}
+	registerRuleError    = &reconcile.Result{}
+	addStatusUpdateError = &reconcile.Result{}
+)
+
+func reconcileImpl(params reconcileImplParams) (res *reconcile.Result, err error) {
+	reqLogger := log.WithValues("Node", params.options.Hostname, "Request.Name", params.request.Name)
+	reqLogger.Info("Reconciling StaticRouteRule")
+
+	reportStatus := true
+
+	// Fetch the StaticRouteRule instance
+	instance := &staticroutev1.StaticRouteRule{}
+	if err = params.client.Get(context.Background(), params.request.NamespacedName, instance); err != nil {
+		if kerrors.IsNotFound(err) {
+			reqLogger.Info("Object not found. Probably deleted meanwhile")
+			return crNotFound, nil
+		}
+		return crGetError, err
+	}
+
+	rw := ruleWrapper{instance: instance}
+	// ruleErr is reported in the status if the spec can not be converted into a rule
+	var ruleErr error
+
+	defer func() {
+		if !reportStatus {
+			return
+		}
+		serr := err
+		if res == invalidRuleError {
+			serr = ruleErr
+		}
+		if !instance.DeletionTimestamp.IsZero() {
+			// The node is removed from the status by the delete operation
+			rw.removeFromStatus(params.options.Hostname)
+			res, err = deleteOperation(params, &rw, reqLogger)
+			return
+		}
+		if !rw.statusMatch(params.options.Hostname, serr) {
+			_ = rw.removeFromStatus(params.options.Hostname)
+			rw.addToStatus(params.options.Hostname, serr)
+			reqLogger.Info("Update the StaticRouteRule status", "staticrouterule", rw.instance.Status)
+			if cerr := params.client.Status().Update(context.Background(), rw.instance); cerr != nil {
+				reqLogger.Error(cerr, "failed to update the staticrouterule")
+				res = addStatusUpdateError
+				err = cerr
+			}
+		}
+	}()
+
+	// Check staticrouterule node selector
+	selectorNoLongerMatches := false
+	if len(rw.instance.Spec.Selectors) > 0 {
+		reqLogger.Info("Node selector found", "Selector", rw.instance.Spec.Selectors)
+		if res, err = validateNodeBySelector(params, &rw); res != nil {
+			if res == nodeNotFound {
+				reportStatus = false
+			}
+			if res != nodeNotFound || !rw.alreadyInStatus(params.options.Hostname) {
+				return
+			}
+			reqLogger.Info("Node labels likely changed and no longer applies to this CR")
+			selectorNoLongerMatches = true
+		}
+	}
+
+	rule, ruleErr := rw.toRule()
+	if ruleErr != nil {
+		reqLogger.Error(ruleErr, "Invalid rule found in Spec")
+		// The rule of the previous spec must not stay installed
+		if params.options.RuleManager.IsRegistered(params.request.Name) {
+			reqLogger.Info("Deregistering rule of the previous Spec")
+			if err = params.options.RuleManager.DeRegisterRule(params.request.Name); err != nil && err != rulemanager.ErrNotFound {
+				reqLogger.Error(err, "Unable to deregister rule")
+				return deRegisterError, err
+			}
+		}
+		return invalidRuleError, nil
+	}
+
+	isChanged := rw.isChanged(params.options.Hostname)
+	reqLogger.Info("The resource is", "changed", isChanged)
+	if instance.GetDeletionTimestamp() != nil ||
+		isChanged ||
+		selectorNoLongerMatches {
+		reportStatus = false
+		if !rw.removeFromStatus(params.options.Hostname) {
+			return alreadyDeleted, nil
+		}
+		res, err = deleteOperation(params, &rw, reqLogger)
+
+		if isChanged {
+			return updateFinished, err
+		}
+		return
+	}
+
+	return addOperation(params, &rw, rule, reqLogger)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *StaticRouteRuleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Watch for changes to primary resource StaticRouteRule
+	builder := ctrl.NewControllerManagedBy(mgr).Named("staticrouterule-controller").
+		For(&staticroutev1.StaticRouteRule{}).
+		Watches(&staticroutev1.StaticRouteRule{}, &handler.EnqueueRequestForObject{})
+	if r.watcher != nil {
+		// Rules deleted by external entities are re-enqueued by the watcher
+		builder = builder.WatchesRawSource(source.Channel(r.watcher.events, &handler.EnqueueRequestForObject{}))
+	}
+	if err := builder.Complete(r); err != nil {
+		return err
+	}
+
+	// Watch if the self node labels are changed, so reconcile every rule
+	return node.WatchLabels(mgr, "staticrouterule-controller", r.options.Hostname, r.allRules, r)
+}
+
+// allRules returns a reconcile request for every StaticRouteRule
+func (r *StaticRouteRuleReconciler) allRules(ctx context.Context, _ client.Object) []reconcile.Request {
+	rules := &staticroutev1.StaticRouteRuleList{}
+	if err := r.client.List(ctx, rules); err != nil {
+		log.Error(err, "Failed to List StaticRouteRule CRs")
+		return nil
+	}
+
+	var result []reconcile.Request
+	for _, rule := range rules.Items {
+		result = append(result, reconcile.Request{NamespacedName: k8stypes.NamespacedName{Name: rule.GetName()}})
+	}
+	return result
+}
+
+func validateNodeBySelector(params reconcileImplParams, rw *ruleWrapper) (*reconcile.Result, error) {
+	allSelector := append([]metav1.LabelSelectorRequirement{}, rw.instance.Spec.Selectors...)
+	allSelector = append(allSelector, node.HostNameRequirement(params.options.Hostname))
+	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchExpressions: allSelector})
+	if err != nil {
+		log.Info("There is something wrong with the node selector", "Value", allSelector, "Error", err.Error())
+		return wrongSelectorErr, nil
+	}
+	nodes := &corev1.NodeList{}
+	if err := params.client.List(context.Background(), nodes, &client.ListOptions{LabelSelector: selector}); err != nil {
+		log.Error(err, "Failed to fetch nodes")
+		return nodeGetError, err
+	} else if len(nodes.Items) == 0 {
+		log.Info("Node not found with the given selectors", "Value", allSelector)
+		return nodeNotFound, nil
+	}
+	return nil, nil
+}
+
+func deleteOperation(params reconcileImplParams, rw *ruleWrapper, logger types.Logger) (*reconcile.Result, error) {
+	logger.Info("Deregistering rule")
+	if err := params.options.RuleManager.DeRegisterRule(params.request.Name); err != nil && err != rulemanager.ErrNotFound {
+		logger.Error(err, "Unable to deregister rule")
+		return deRegisterError, err
+	}
+
+	logger.Info("Deleted status for StaticRouteRule", "status", rw.instance.Status)
+	if err := params.client.Status().Update(context.Background(), rw.instance); err != nil {
+		logger.Error(err, "Unable to update status of CR")
+		return delStatusUpdateError, err
+	}
+
+	// We were the last one
+	if len(rw.instance.Status.NodeStatus) == 0 {
+		logger.Info("Removing finalizer for StaticRouteRule")
+		rw.instance.SetFinalizers(nil)
+		if err := params.client.Update(context.Background(), rw.instance); err != nil {
+			logger.Error(err, "Unable to delete finalizers")
+			return emptyFinalizerError, err
+		}
+	}
+	return deletionFinished, nil
+}
+
+func addOperation(params reconcileImplParams, rw *ruleWrapper, rule rulemanager.Rule, logger types.Logger) (*reconcile.Result, error) {
+	if rw.setFinalizer() {
+		logger.Info("Adding Finalizer for the StaticRouteRule")
+		if err := params.client.Update(context.Background(), rw.instance); err != nil {
+			logger.Error(err, "Failed to update StaticRouteRule with finalizer")
+			return setFinalizerError, err
+		}
+	}
+	if !params.options.RuleManager.IsRegistered(params.request.Name) {
+		// Like at the routes, this also runs if the CR was asked for deletion while the operator did not run
+		logger.Info("Registering rule")
+		if err := params.options.RuleManager.RegisterRule(params.request.Name, rule); err != nil {
+			logger.Error(err, "Unable to register rule")
+			return registerRuleError, err
+		}
+	}
+	return finished, nil
+}
@@ -0,0 +1,423 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticrouterule
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/controllers/node"
+	nlfake "github.com/IBM/staticroute-operator/pkg/routemanager/fake"
+	"github.com/IBM/staticroute-operator/pkg/rulemanager"
+	"github.com/vishvananda/netlink"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func getRule(t *testing.T, mockClient *reconcileImplClientMock) *staticroutev1.StaticRouteRule {
+	instance := &staticroutev1.StaticRouteRule{}
+	if err := mockClient.Get(context.Background(), types.NamespacedName{Name: "CR"}, instance); err != nil {
+		t.Fatalf("Failed to read the CR: %s", err.Error())
+	}
+	return instance
+}
+
+func TestReconcileImplRegistersRule(t *testing.T) {
+	params, mockClient := getReconcileContext(newStaticRouteRuleWithValues(false), false)
+	var registered rulemanager.Rule
+	params.options.RuleManager = ruleManagerMock{
+		registeredCallback: func(n string, r rulemanager.Rule) error {
+			registered = r
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished {
+		t.Error("Result must be finished")
+	}
+	if err != nil {
+		t.Errorf("Error must be nil: %s", err.Error())
+	}
+	if registered.Src.String() != "10.0.0.0/8" || registered.Mark != 100 || registered.Priority != 1000 || registered.Table != 100 {
+		t.Errorf("Rule must be converted from the Spec: %+v", registered)
+	}
+	instance := getRule(t, mockClient)
+	if len(instance.GetFinalizers()) != 1 {
+		t.Error("Finalizer must be set")
+	}
+	if len(instance.Status.NodeStatus) != 1 || instance.Status.NodeStatus[0].Hostname != "hostname" || instance.Status.NodeStatus[0].Error != "" {
+		t.Errorf("Node must be reported in the status: %+v", instance.Status)
+	}
+}
+
+func TestReconcileImplCRGetNotFound(t *testing.T) {
+	params, mockClient := getReconcileContext(newStaticRouteRuleWithValues(false), false)
+	params.request.Name = "missing"
+
+	res, err := reconcileImpl(*params)
+
+	if res != crNotFound || err != nil {
+		t.Errorf("Result must be crNotFound without error: %v", err)
+	}
+	if len(getRule(t, mockClient).Status.NodeStatus) != 0 {
+		t.Error("Status must not be touched")
+	}
+}
+
+func TestReconcileImplCRGetFatalError(t *testing.T) {
+	params, mockClient := getReconcileContext(newStaticRouteRuleWithValues(false), false)
+	mockClient.getErr = errors.New("get failed")
+
+	res, err := reconcileImpl(*params)
+
+	if res != crGetError || err == nil {
+		t.Error("Result must be crGetError with error")
+	}
+}
+
+func TestReconcileImplInvalidRule(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(false)
+	rule.Spec.To = "fd00::/8"
+	params, mockClient := getReconcileContext(rule, false)
+
+	res, err := reconcileImpl(*params)
+
+	if res != invalidRuleError || err != nil {
+		t.Errorf("Result must be invalidRuleError without error: %v", err)
+	}
+	instance := getRule(t, mockClient)
+	if len(instance.Status.NodeStatus) != 1 || instance.Status.NodeStatus[0].Error != "from and to must be the same IP family" {
+		t.Errorf("Error must be reported in the status: %+v", instance.Status)
+	}
+}
+
+// rulesOfTable returns the rules of the fake kernel which look up the given table
+func rulesOfTable(t *testing.T, kernel *nlfake.Netlink, table int) []netlink.Rule {
+	rules, err := kernel.RuleList(netlink.FAMILY_ALL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result []netlink.Rule
+	for _, rule := range rules {
+		if rule.Table == table {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+func TestReconcileImplEditedToInvalidRule(t *testing.T) {
+	kernel := nlfake.NewNetlink()
+	rm := rulemanager.NewWithNetlink(kernel, 196)
+	stopChan := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		//nolint:errcheck
+		rm.Run(stopChan)
+		close(done)
+	}()
+	defer func() {
+		close(stopChan)
+		<-done
+	}()
+	params, mockClient := getReconcileContext(newStaticRouteRuleWithValues(false), false)
+	params.options.RuleManager = rm
+
+	if res, err := reconcileImpl(*params); res != finished || err != nil {
+		t.Fatalf("Rule must be registered: %v", err)
+	}
+	if rules := rulesOfTable(t, kernel, 100); len(rules) != 1 {
+		t.Fatalf("Rule must be installed: %v", rules)
+	}
+	instance := getRule(t, mockClient)
+	instance.Spec.To = "fd00::/8"
+	if err := mockClient.Update(context.Background(), instance); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != invalidRuleError || err != nil {
+		t.Errorf("Result must be invalidRuleError without error: %v", err)
+	}
+	if rm.IsRegistered("CR") {
+		t.Error("Rule of the previous Spec must be deregistered")
+	}
+	if rules := rulesOfTable(t, kernel, 100); len(rules) != 0 {
+		t.Errorf("Rule of the previous Spec must be removed from the kernel: %v", rules)
+	}
+	instance = getRule(t, mockClient)
+	if len(instance.Status.NodeStatus) != 1 || instance.Status.NodeStatus[0].Error != "from and to must be the same IP family" {
+		t.Errorf("Error must be reported in the status: %+v", instance.Status)
+	}
+}
+
+func TestReconcileImplEditedToInvalidRuleCantDeregister(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(true)
+	rule.Spec.To = "fd00::/8"
+	params, mockClient := getReconcileContext(rule, true)
+	params.options.RuleManager = ruleManagerMock{isRegistered: true, deRegisterRuleErr: errors.New("deregister failed")}
+
+	res, err := reconcileImpl(*params)
+
+	if res != deRegisterError || err == nil {
+		t.Error("Result must be deRegisterError with error")
+	}
+	instance := getRule(t, mockClient)
+	if len(instance.Status.NodeStatus) != 1 || instance.Status.NodeStatus[0].Error != "deregister failed" {
+		t.Errorf("Error must be reported in the status: %+v", instance.Status)
+	}
+}
+
+func TestReconcileImplCantRegister(t *testing.T) {
+	params, mockClient := getReconcileContext(newStaticRouteRuleWithValues(false), false)
+	params.options.RuleManager = ruleManagerMock{registerRuleErr: errors.New("register failed")}
+
+	res, err := reconcileImpl(*params)
+
+	if res != registerRuleError || err == nil {
+		t.Error("Result must be registerRuleError with error")
+	}
+	instance := getRule(t, mockClient)
+	if len(instance.Status.NodeStatus) != 1 || instance.Status.NodeStatus[0].Error != "register failed" {
+		t.Errorf("Error must be reported in the status: %+v", instance.Status)
+	}
+}
+
+func TestReconcileImplCantSetFinalizer(t *testing.T) {
+	params, mockClient := getReconcileContext(newStaticRouteRuleWithValues(false), false)
+	mockClient.updateErr = errors.New("update failed")
+
+	res, err := reconcileImpl(*params)
+
+	if res != setFinalizerError || err == nil {
+		t.Error("Result must be setFinalizerError with error")
+	}
+}
+
+func TestReconcileImplCantAddStatus(t *testing.T) {
+	params, mockClient := getReconcileContext(newStaticRouteRuleWithValues(false), true)
+	mockClient.statusWriteMock = &statusWriterMock{updateErr: errors.New("status failed")}
+
+	res, err := reconcileImpl(*params)
+
+	if res != addStatusUpdateError || err == nil {
+		t.Error("Result must be addStatusUpdateError with error")
+	}
+}
+
+func TestReconcileImplUnchangedDoesNotUpdateStatus(t *testing.T) {
+	params, mockClient := getReconcileContext(newStaticRouteRuleWithValues(true), true)
+	statusWriter := &statusWriterMock{}
+	mockClient.statusWriteMock = statusWriter
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished || err != nil {
+		t.Errorf("Result must be finished without error: %v", err)
+	}
+	if statusWriter.updateCounter != 0 {
+		t.Error("Status must not be updated")
+	}
+}
+
+func TestReconcileImplUpdated(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(true)
+	rule.Spec.Priority = 1001
+	params, mockClient := getReconcileContext(rule, true)
+	deRegistered := false
+	params.options.RuleManager = ruleManagerMock{
+		isRegistered: true,
+		deRegisteredCallback: func(string) error {
+			deRegistered = true
+			return nil
+		},
+	}
+
+	res, err := reconcileImpl(*params)
+
+	if res != updateFinished || err != nil {
+		t.Errorf("Result must be updateFinished without error: %v", err)
+	}
+	if !deRegistered {
+		t.Error("Old rule must be deregistered")
+	}
+	if len(getRule(t, mockClient).Status.NodeStatus) != 0 {
+		t.Error("Node must be removed from the status")
+	}
+}
+
+func TestReconcileImplDeleted(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(true)
+	rule.SetFinalizers([]string{"finalizer.static-route.ibm.com"})
+	rule.SetDeletionTimestamp(&metav1.Time{Time: metav1.Now().Time})
+	params, mockClient := getReconcileContext(rule, true)
+
+	res, err := reconcileImpl(*params)
+
+	if res != deletionFinished || err != nil {
+		t.Errorf("Result must be deletionFinished without error: %v", err)
+	}
+	instance := &staticroutev1.StaticRouteRule{}
+	if err = mockClient.Get(context.Background(), types.NamespacedName{Name: "CR"}, instance); err == nil {
+		t.Error("CR must be deleted after the finalizer is removed")
+	}
+}
+
+func TestReconcileImplDeletedButCantDeregister(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(true)
+	rule.SetFinalizers([]string{"finalizer.static-route.ibm.com"})
+	rule.SetDeletionTimestamp(&metav1.Time{Time: metav1.Now().Time})
+	params, _ := getReconcileContext(rule, true)
+	params.options.RuleManager = ruleManagerMock{isRegistered: true, deRegisterRuleErr: errors.New("deregister failed")}
+
+	res, err := reconcileImpl(*params)
+
+	if res != deRegisterError || err == nil {
+		t.Error("Result must be deRegisterError with error")
+	}
+}
+
+func TestReconcileImplDeletedIfRuleNotFound(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(true)
+	rule.SetFinalizers([]string{"finalizer.static-route.ibm.com"})
+	rule.SetDeletionTimestamp(&metav1.Time{Time: metav1.Now().Time})
+	params, _ := getReconcileContext(rule, true)
+	params.options.RuleManager = ruleManagerMock{deRegisterRuleErr: rulemanager.ErrNotFound}
+
+	res, err := reconcileImpl(*params)
+
+	if res != deletionFinished || err != nil {
+		t.Errorf("Result must be deletionFinished without error: %v", err)
+	}
+}
+
+func TestReconcileImplDeletedButCantDeleteStatus(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(true)
+	rule.SetFinalizers([]string{"finalizer.static-route.ibm.com"})
+	rule.SetDeletionTimestamp(&metav1.Time{Time: metav1.Now().Time})
+	params, mockClient := getReconcileContext(rule, true)
+	mockClient.statusWriteMock = &statusWriterMock{updateErr: errors.New("status failed")}
+
+	res, err := reconcileImpl(*params)
+
+	if res != delStatusUpdateError || err == nil {
+		t.Error("Result must be delStatusUpdateError with error")
+	}
+}
+
+func TestReconcileImplDeletedButCantEmptyFinalizers(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(true)
+	rule.SetFinalizers([]string{"finalizer.static-route.ibm.com"})
+	rule.SetDeletionTimestamp(&metav1.Time{Time: metav1.Now().Time})
+	params, mockClient := getReconcileContext(rule, true)
+	mockClient.updateErr = errors.New("update failed")
+
+	res, err := reconcileImpl(*params)
+
+	if res != emptyFinalizerError || err == nil {
+		t.Error("Result must be emptyFinalizerError with error")
+	}
+}
+
+func TestReconcileImplDeletingInvalidRule(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(false)
+	rule.Spec.From = "10.0.0.0"
+	rule.SetFinalizers([]string{"finalizer.static-route.ibm.com"})
+	rule.SetDeletionTimestamp(&metav1.Time{Time: metav1.Now().Time})
+	params, _ := getReconcileContext(rule, false)
+
+	res, err := reconcileImpl(*params)
+
+	if res != deletionFinished || err != nil {
+		t.Errorf("Invalid rule must be deleted without error: %v", err)
+	}
+}
+
+func TestReconcileImplNodeSelectorNotFound(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(false)
+	rule.Spec.Selectors = []metav1.LabelSelectorRequirement{{Key: "key", Operator: metav1.LabelSelectorOpIn, Values: []string{"value"}}}
+	params, mockClient := getReconcileContext(rule, false)
+
+	res, err := reconcileImpl(*params)
+
+	if res != nodeNotFound || err != nil {
+		t.Errorf("Result must be nodeNotFound without error: %v", err)
+	}
+	if len(getRule(t, mockClient).Status.NodeStatus) != 0 {
+		t.Error("Node must not be reported in the status")
+	}
+}
+
+func TestReconcileImplNodeSelectorNoLongerMatches(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(true)
+	rule.Spec.Selectors = []metav1.LabelSelectorRequirement{{Key: "key", Operator: metav1.LabelSelectorOpIn, Values: []string{"value"}}}
+	rule.Status.NodeStatus[0].State.Selectors = rule.Spec.Selectors
+	params, mockClient := getReconcileContext(rule, true)
+
+	res, err := reconcileImpl(*params)
+
+	if res != deletionFinished || err != nil {
+		t.Errorf("Result must be deletionFinished without error: %v", err)
+	}
+	if len(getRule(t, mockClient).Status.NodeStatus) != 0 {
+		t.Error("Node must be removed from the status")
+	}
+}
+
+func TestReconcileImplNodeSelectorMatches(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(false)
+	rule.Spec.Selectors = []metav1.LabelSelectorRequirement{{Key: "key", Operator: metav1.LabelSelectorOpIn, Values: []string{"value"}}}
+	hostNode := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "hostname", Labels: map[string]string{"key": "value", node.HostNameLabel: "hostname"}}}
+	params, _ := getReconcileContext(rule, false, hostNode)
+
+	res, err := reconcileImpl(*params)
+
+	if res != finished || err != nil {
+		t.Errorf("Result must be finished without error: %v", err)
+	}
+}
+
+func TestReconcileImplNodeSelectorInvalid(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(false)
+	rule.Spec.Selectors = []metav1.LabelSelectorRequirement{{Key: "key", Operator: "invalid"}}
+	params, _ := getReconcileContext(rule, false)
+
+	res, err := reconcileImpl(*params)
+
+	if res != wrongSelectorErr || err != nil {
+		t.Errorf("Result must be wrongSelectorErr without error: %v", err)
+	}
+}
+
+func TestReconcileImplNodeSelectorFatalError(t *testing.T) {
+	rule := newStaticRouteRuleWithValues(false)
+	rule.Spec.Selectors = []metav1.LabelSelectorRequirement{{Key: "key", Operator: metav1.LabelSelectorOpExists}}
+	params, mockClient := getReconcileContext(rule, false)
+	mockClient.listErr = errors.New("list failed")
+
+	res, err := reconcileImpl(*params)
+
+	if res != nodeGetError || err == nil {
+		t.Error("Result must be nodeGetError with error")
+	}
+}
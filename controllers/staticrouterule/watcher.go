@@ -0,0 +1,67 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticrouterule
+
+import (
+	"time"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/pkg/rulemanager"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// maxTamperReactionBackoff is the upper limit of the delay before re-creating a repeatedly deleted rule
+const maxTamperReactionBackoff = 5 * time.Minute
+
+// ruleWatcher is notified by the RuleManager when a managed rule is deleted by an external entity.
+// It deregisters the rule and re-enqueues the owning StaticRouteRule after a backoff, so the
+// next reconciliation registers the rule again.
+type ruleWatcher struct {
+	ruleManager rulemanager.RuleManager
+	events      chan event.GenericEvent
+	backoff     *flowcontrol.Backoff
+	sleep       func(time.Duration)
+}
+
+// blank assignment to verify that ruleWatcher implements rulemanager.RuleWatcher
+var _ rulemanager.RuleWatcher = &ruleWatcher{}
+
+func newRuleWatcher(ruleManager rulemanager.RuleManager, backoff time.Duration) *ruleWatcher {
+	return &ruleWatcher{
+		ruleManager: ruleManager,
+		events:      make(chan event.GenericEvent),
+		backoff:     flowcontrol.NewBackOff(backoff, maxTamperReactionBackoff),
+		sleep:       time.Sleep,
+	}
+}
+
+// RuleDeleted is called from the event loop of the RuleManager, so the reaction runs in its own go-routine
+func (w *ruleWatcher) RuleDeleted(name string, rule rulemanager.Rule) {
+	log.Info("Managed rule was deleted by an external entity", "Request.Name", name, "Priority", rule.Priority, "Table", rule.Table)
+	w.backoff.Next(name, w.backoff.Clock.Now())
+	go w.react(name, w.backoff.Get(name))
+}
+
+func (w *ruleWatcher) react(name string, delay time.Duration) {
+	w.sleep(delay)
+	if err := w.ruleManager.DeRegisterRule(name); err != nil && err != rulemanager.ErrNotFound {
+		log.Error(err, "Unable to deregister the deleted rule", "Request.Name", name)
+	}
+	w.events <- event.GenericEvent{Object: &staticroutev1.StaticRouteRule{ObjectMeta: metav1.ObjectMeta{Name: name}}}
+}
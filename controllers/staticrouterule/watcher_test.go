@@ -0,0 +1,85 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticrouterule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IBM/staticroute-operator/pkg/rulemanager"
+)
+
+var gDeletedRule = rulemanager.Rule{Priority: 1000, Table: 100}
+
+func newTestableRuleWatcher(deRegistered chan string, deRegisterErr error) (*ruleWatcher, chan time.Duration) {
+	slept := make(chan time.Duration, 10)
+	w := newRuleWatcher(ruleManagerMock{
+		deRegisteredCallback: func(n string) error {
+			deRegistered <- n
+			return deRegisterErr
+		},
+	}, time.Second)
+	w.sleep = func(d time.Duration) {
+		slept <- d
+	}
+	return w, slept
+}
+
+func TestRuleWatcherReenqueues(t *testing.T) {
+	deRegistered := make(chan string, 1)
+	w, slept := newTestableRuleWatcher(deRegistered, nil)
+
+	w.RuleDeleted("CR", gDeletedRule)
+
+	if delay := <-slept; delay != time.Second {
+		t.Errorf("First reaction must wait for the initial backoff, waited: %s", delay)
+	}
+	if name := <-deRegistered; name != "CR" {
+		t.Errorf("Deleted rule must be deregistered, deregistered: %s", name)
+	}
+	if event := <-w.events; event.Object.GetName() != "CR" {
+		t.Errorf("Owner StaticRouteRule must be re-enqueued, enqueued: %s", event.Object.GetName())
+	}
+}
+
+func TestRuleWatcherReenqueuesIfDeRegisterFails(t *testing.T) {
+	deRegistered := make(chan string, 1)
+	w, _ := newTestableRuleWatcher(deRegistered, errors.New("bla"))
+
+	w.RuleDeleted("CR", gDeletedRule)
+
+	<-deRegistered
+	if event := <-w.events; event.Object.GetName() != "CR" {
+		t.Errorf("Owner StaticRouteRule must be re-enqueued, enqueued: %s", event.Object.GetName())
+	}
+}
+
+func TestRuleWatcherBackoffIncreases(t *testing.T) {
+	deRegistered := make(chan string, 2)
+	w, slept := newTestableRuleWatcher(deRegistered, nil)
+
+	w.RuleDeleted("CR", gDeletedRule)
+	<-w.events
+	w.RuleDeleted("CR", gDeletedRule)
+	<-w.events
+
+	first, second := <-slept, <-slept
+	if second <= first {
+		t.Errorf("Backoff must increase for repeated deletions: %s, %s", first, second)
+	}
+}
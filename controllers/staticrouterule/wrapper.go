@@ -0,0 +1,143 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticrouterule
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	"github.com/IBM/staticroute-operator/pkg/rulemanager"
+	"github.com/vishvananda/netlink"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+type ruleWrapper struct {
+	instance *staticroutev1.StaticRouteRule
+}
+
+// setFinalizer will add this attribute to the CR
+func (rw *ruleWrapper) setFinalizer() bool {
+	if len(rw.instance.GetFinalizers()) != 0 {
+		return false
+	}
+	rw.instance.SetFinalizers([]string{"finalizer.static-route.ibm.com"})
+	return true
+}
+
+// toRule converts the Spec into a rule of the RuleManager
+func (rw *ruleWrapper) toRule() (rulemanager.Rule, error) {
+	spec := rw.instance.Spec
+	rule := rulemanager.Rule{
+		IifName:  spec.Iif,
+		OifName:  spec.Oif,
+		Priority: spec.Priority,
+		Table:    spec.Table,
+	}
+	var err error
+	if rule.Src, err = parseSubnet(spec.From); err != nil {
+		return rule, fmt.Errorf("invalid from: %s", err.Error())
+	}
+	if rule.Dst, err = parseSubnet(spec.To); err != nil {
+		return rule, fmt.Errorf("invalid to: %s", err.Error())
+	}
+	if rule.Src != nil && rule.Dst != nil && (rule.Src.IP.To4() == nil) != (rule.Dst.IP.To4() == nil) {
+		return rule, errors.New("from and to must be the same IP family")
+	}
+	if spec.Family == "IPv6" {
+		rule.Family = netlink.FAMILY_V6
+	}
+	if spec.FwMark != nil {
+		rule.Mark = uint32(*spec.FwMark)
+	}
+	if spec.FwMask != nil {
+		mask := uint32(*spec.FwMask)
+		rule.Mask = &mask
+	}
+	return rule, nil
+}
+
+// parseSubnet returns nil if the subnet is not set
+func parseSubnet(subnet string) (*net.IPNet, error) {
+	if len(subnet) == 0 {
+		return nil, nil
+	}
+	_, ipnet, err := net.ParseCIDR(subnet)
+	return ipnet, err
+}
+
+// isChanged returns true if the Spec differs from the one which was applied on the node
+func (rw *ruleWrapper) isChanged(hostname string) bool {
+	for _, s := range rw.instance.Status.NodeStatus {
+		if s.Hostname == hostname && !equality.Semantic.DeepEqual(s.State, rw.instance.Spec) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rw *ruleWrapper) statusMatch(hostname string, err error) bool {
+	errText := ""
+	if err != nil {
+		errText = err.Error()
+	}
+	for _, val := range rw.instance.Status.NodeStatus {
+		if val.Hostname == hostname && equality.Semantic.DeepEqual(val.State, rw.instance.Spec) && val.Error == errText {
+			return true
+		}
+	}
+	return false
+}
+
+func (rw *ruleWrapper) addToStatus(hostname string, err error) bool {
+	if rw.alreadyInStatus(hostname) {
+		return false
+	}
+	errorString := ""
+	if err != nil {
+		errorString = err.Error()
+	}
+	rw.instance.Status.NodeStatus = append(rw.instance.Status.NodeStatus, staticroutev1.StaticRouteRuleNodeStatus{
+		Hostname: hostname,
+		State:    *rw.instance.Spec.DeepCopy(),
+		Error:    errorString,
+	})
+	return true
+}
+
+func (rw *ruleWrapper) alreadyInStatus(hostname string) bool {
+	for _, val := range rw.instance.Status.NodeStatus {
+		if val.Hostname == hostname {
+			return true
+		}
+	}
+	return false
+}
+
+func (rw *ruleWrapper) removeFromStatus(hostname string) (existed bool) {
+	statusArr := []staticroutev1.StaticRouteRuleNodeStatus{}
+	for _, val := range rw.instance.Status.NodeStatus {
+		if val.Hostname == hostname {
+			existed = true
+			continue
+		}
+		statusArr = append(statusArr, *val.DeepCopy())
+	}
+	rw.instance.Status.NodeStatus = statusArr
+	return
+}
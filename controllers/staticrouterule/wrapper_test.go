@@ -0,0 +1,96 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package staticrouterule
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+)
+
+func TestToRule(t *testing.T) {
+	rw := ruleWrapper{instance: newStaticRouteRuleWithValues(false)}
+	mask := int64(0xff)
+	rw.instance.Spec.FwMask = &mask
+	rw.instance.Spec.Iif = "eth0"
+	rw.instance.Spec.Oif = "eth1"
+
+	rule, err := rw.toRule()
+
+	if err != nil {
+		t.Fatalf("Rule must be valid: %s", err.Error())
+	}
+	if rule.Src.String() != "10.0.0.0/8" || rule.Dst != nil || rule.Mark != 100 || rule.Mask == nil || *rule.Mask != 0xff ||
+		rule.IifName != "eth0" || rule.OifName != "eth1" || rule.Priority != 1000 || rule.Table != 100 {
+		t.Errorf("Rule must be converted from the Spec: %+v", rule)
+	}
+}
+
+func TestToRuleFamily(t *testing.T) {
+	rw := ruleWrapper{instance: newStaticRouteRuleWithValues(false)}
+	rw.instance.Spec.From = ""
+	rw.instance.Spec.Family = "IPv6"
+
+	rule, err := rw.toRule()
+
+	if err != nil || rule.Family != netlink.FAMILY_V6 {
+		t.Errorf("Family must be converted: %+v %v", rule, err)
+	}
+}
+
+func TestToRuleInvalid(t *testing.T) {
+	var testData = []struct {
+		from, to string
+		err      string
+	}{
+		{"10.0.0.0", "", "invalid from: invalid CIDR address: 10.0.0.0"},
+		{"", "fd00::", "invalid to: invalid CIDR address: fd00::"},
+		{"10.0.0.0/8", "fd00::/8", "from and to must be the same IP family"},
+	}
+	for _, td := range testData {
+		rw := ruleWrapper{instance: newStaticRouteRuleWithValues(false)}
+		rw.instance.Spec.From, rw.instance.Spec.To = td.from, td.to
+		if _, err := rw.toRule(); err == nil || err.Error() != td.err {
+			t.Errorf("Error of %s -> %s must be '%s': %v", td.from, td.to, td.err, err)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	rw := ruleWrapper{instance: newStaticRouteRuleWithValues(false)}
+
+	if rw.statusMatch("hostname", nil) || rw.alreadyInStatus("hostname") {
+		t.Error("Node must not be in the status")
+	}
+	if !rw.addToStatus("hostname", errors.New("bla")) || rw.addToStatus("hostname", nil) {
+		t.Error("Node must be added only once")
+	}
+	if !rw.statusMatch("hostname", errors.New("bla")) || rw.statusMatch("hostname", nil) {
+		t.Error("Status must match only with the same error")
+	}
+	if rw.isChanged("hostname") {
+		t.Error("Spec is not changed")
+	}
+	rw.instance.Spec.Table = 101
+	if !rw.isChanged("hostname") || rw.statusMatch("hostname", errors.New("bla")) {
+		t.Error("Spec is changed")
+	}
+	if !rw.removeFromStatus("hostname") || rw.removeFromStatus("hostname") {
+		t.Error("Node must be removed only once")
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 
@@ -38,13 +39,21 @@ import (
 
 	"github.com/IBM/staticroute-operator/controllers/node"
 	"github.com/IBM/staticroute-operator/controllers/staticroute"
+	"github.com/IBM/staticroute-operator/controllers/staticrouterule"
+	"github.com/IBM/staticroute-operator/pkg/cidr"
+	"github.com/IBM/staticroute-operator/pkg/gatewayprobe"
 	"github.com/IBM/staticroute-operator/pkg/routemanager"
+	"github.com/IBM/staticroute-operator/pkg/rulemanager"
 	"github.com/IBM/staticroute-operator/pkg/types"
 	"github.com/IBM/staticroute-operator/version"
 	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 
 	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	staticroutev2 "github.com/IBM/staticroute-operator/api/v2"
+	corev1 "k8s.io/api/core/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	clientConfig "sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/config"
@@ -57,8 +66,13 @@ import (
 
 // Change below variables to serve metrics on different host or port.
 var (
-	defaultRouteTable = 254
-	defaultFallbackIP = net.IP{10, 0, 0, 1}
+	defaultRouteTable            = 254
+	defaultFallbackIP            = net.IP{10, 0, 0, 1}
+	defaultTamperReactionBackoff = 5 * time.Second
+	defaultRouteProtocol         = 196
+	defaultMetricsBindAddress    = ":8383"
+	defaultRouteAuditPolicy      = routemanager.RepairRestore
+	leaderElectionID             = "static-route-operator-storage-migration"
 )
 var log = logf.Log.WithName("cmd")
 
@@ -67,6 +81,7 @@ var scheme = kRuntime.NewScheme()
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(staticroutev1.AddToScheme(scheme))
+	utilruntime.Must(staticroutev2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -107,21 +122,29 @@ func main() {
 		osEnv:       os.Environ,
 		getConfig:   clientConfig.GetConfig,
 		newManager:  manager.New,
-		addToScheme: staticroutev1.AddToScheme,
+		addToScheme: addToScheme,
 		newKubernetesConfig: func(config *rest.Config) (discoverable, error) {
 			clientSet, err := kubernetes.NewForConfig(config)
 			return clientSet, err
 		},
-		newRouterManager:         routemanager.New,
-		addStaticRouteController: staticroute.Add,
-		addNodeController:        node.Add,
-		getGw: func(ip net.IP) (net.IP, error) {
-			route, err := netlink.RouteGet(ip)
-			if err != nil {
-				return nil, err
-			}
-			return route[0].Gw, nil
+		newNetlink:                   newNetlink,
+		newRouterManager:             routemanager.NewWithNetlink,
+		addStaticRouteController:     staticroute.Add,
+		newRuleManager:               rulemanager.NewWithNetlink,
+		addStaticRouteRuleController: staticrouterule.Add,
+		addNodeController:            node.Add,
+		addStaticRouteWebhook: func(mgr manager.Manager, validator *staticroutev1.StaticRouteValidator) error {
+			return validator.SetupWebhookWithManager(mgr)
 		},
+		addStorageMigration:   staticroute.AddStorageMigration,
+		getGw:                 getGw,
+		getLinkIndex:          getLinkIndex,
+		getLinkIndexByAddress: getLinkIndexByAddress,
+		getLinkAddress:        getLinkAddress,
+		probeGateway: func(nl routemanager.Netlink, method string, gateway net.IP, port int, timeout time.Duration) error {
+			return gatewayprobe.NewProber(nl).Probe(method, gateway, port, timeout)
+		},
+		getNodeSubnets: getNodeSubnets,
 		setupSignalHandler: func() context.Context {
 			return signals.SetupSignalHandler()
 		},
@@ -129,18 +152,28 @@ func main() {
 }
 
 type mainImplParams struct {
-	logger                   types.Logger
-	getEnv                   func(string) string
-	osEnv                    func() []string
-	getConfig                func() (*rest.Config, error)
-	newManager               func(*rest.Config, manager.Options) (manager.Manager, error)
-	addToScheme              func(s *kRuntime.Scheme) error
-	newKubernetesConfig      func(*rest.Config) (discoverable, error)
-	newRouterManager         func() routemanager.RouteManager
-	addStaticRouteController func(manager.Manager, staticroute.ManagerOptions) error
-	addNodeController        func(manager.Manager) error
-	getGw                    func(net.IP) (net.IP, error)
-	setupSignalHandler       func() context.Context
+	logger                       types.Logger
+	getEnv                       func(string) string
+	osEnv                        func() []string
+	getConfig                    func() (*rest.Config, error)
+	newManager                   func(*rest.Config, manager.Options) (manager.Manager, error)
+	addToScheme                  func(s *kRuntime.Scheme) error
+	newKubernetesConfig          func(*rest.Config) (discoverable, error)
+	newNetlink                   func(string) routemanager.Netlink
+	newRouterManager             func(routemanager.Netlink, int, routemanager.AuditOptions) routemanager.RouteManager
+	addStaticRouteController     func(manager.Manager, staticroute.ManagerOptions) error
+	newRuleManager               func(routemanager.Netlink, int) rulemanager.RuleManager
+	addStaticRouteRuleController func(manager.Manager, staticrouterule.ManagerOptions) error
+	addNodeController            func(manager.Manager) error
+	addStaticRouteWebhook        func(manager.Manager, *staticroutev1.StaticRouteValidator) error
+	addStorageMigration          func(manager.Manager) error
+	getGw                        func(routemanager.Netlink, net.IP) (net.IP, error)
+	getLinkIndex                 func(routemanager.Netlink, string) (int, error)
+	getLinkIndexByAddress        func(routemanager.Netlink, *net.IPNet) (int, error)
+	getLinkAddress               func(routemanager.Netlink, string, bool) (net.IP, error)
+	probeGateway                 func(routemanager.Netlink, string, net.IP, int, time.Duration) error
+	getNodeSubnets               func(routemanager.Netlink) ([]*net.IPNet, error)
+	setupSignalHandler           func() context.Context
 }
 
 type discoverable interface {
@@ -154,14 +187,27 @@ func mainImpl(params mainImplParams) {
 		panic(err)
 	}
 
-	// Create a new Cmd to provide shared dependencies and start components
+	// "0" disables the metrics endpoint
+	metricsBindAddress := defaultMetricsBindAddress
+	if metricsBindAddressEnv := params.getEnv("METRICS_BIND_ADDRESS"); len(metricsBindAddressEnv) != 0 {
+		metricsBindAddress = metricsBindAddressEnv
+	}
+
+	// The webhooks need a serving certificate so they are opt-in
+	webhooksEnabled := params.getEnv("ENABLE_WEBHOOKS") == "true"
+
+	// Create a new Cmd to provide shared dependencies and start components.
+	// Only the storage migration runs on the leader, the controllers manage the routes of their own node.
 	mgr, err := params.newManager(cfg, manager.Options{
 		MapperProvider: apiutil.NewDynamicRESTMapper,
 		Metrics: metricsserver.Options{
-			BindAddress: "0",
+			BindAddress: metricsBindAddress,
 		},
+		LeaderElection:   webhooksEnabled,
+		LeaderElectionID: leaderElectionID,
 		Controller: config.Controller{
 			SkipNameValidation: ptr.To(true),
+			NeedLeaderElection: ptr.To(false),
 		},
 	})
 	if err != nil {
@@ -180,6 +226,9 @@ func mainImpl(params mainImplParams) {
 		panic("Missing environment variable: NODE_HOSTNAME")
 	}
 
+	// The routes and rules are installed, the gateways and links are looked up and probed in the same network namespace
+	nl := params.newNetlink(params.getEnv("ROUTE_NETNS"))
+
 	params.logger.Info(fmt.Sprintf("Node Hostname: %s", hostname))
 	params.logger.Info("Registering Components.")
 
@@ -201,16 +250,51 @@ func mainImpl(params mainImplParams) {
 	params.logger.Info("Table selected", "value", table)
 
 	fallbackIP := defaultFallbackIP
+	var fallbackIPv6 net.IP
 	fallbackIPEnv := params.getEnv("FALLBACK_IP_FOR_GW_SELECTION")
 	if len(fallbackIPEnv) != 0 {
-		fallbackIP = net.ParseIP(fallbackIPEnv)
-		if fallbackIP == nil || strings.Contains(fallbackIPEnv, ":") {
-			panic("Environment variable parse error: FALLBACK_IP_FOR_GW_SELECTION.")
-		}
+		fallbackIP, fallbackIPv6 = parseFallbackIPs(fallbackIPEnv, fallbackIP)
+	}
+	params.logger.Info("Fallback IP for gateway selection:", "value", fallbackIP, "IPv6", fallbackIPv6)
+
+	tamperReactionBackoff := defaultTamperReactionBackoff
+	tamperReactionBackoffEnv := params.getEnv("TAMPER_REACTION_BACKOFF")
+	if len(tamperReactionBackoffEnv) != 0 {
+		tamperReactionBackoff = parseTamperReactionBackoff(tamperReactionBackoffEnv)
+	}
+	params.logger.Info("Tamper reaction backoff selected", "value", tamperReactionBackoff)
+
+	routeProtocol := defaultRouteProtocol
+	routeProtocolEnv := params.getEnv("ROUTE_PROTOCOL")
+	if len(routeProtocolEnv) != 0 {
+		routeProtocol = parseRouteProtocol(routeProtocolEnv)
+	}
+	params.logger.Info("Route protocol selected", "value", routeProtocol)
+
+	// The periodic audit of the routes is opt-in, the drifts are repaired by default
+	routeAudit := routemanager.AuditOptions{Policy: defaultRouteAuditPolicy}
+	if routeAuditIntervalEnv := params.getEnv("ROUTE_AUDIT_INTERVAL"); len(routeAuditIntervalEnv) != 0 {
+		routeAudit.Interval = parseRouteAuditInterval(routeAuditIntervalEnv)
+	}
+	if routeAuditPolicyEnv := params.getEnv("ROUTE_AUDIT_POLICY"); len(routeAuditPolicyEnv) != 0 {
+		routeAudit.Policy = parseRouteAuditPolicy(routeAuditPolicyEnv)
 	}
-	params.logger.Info("Fallback IP for gateway selection:", "value", fallbackIP)
+	params.logger.Info("Route audit selected", "interval", routeAudit.Interval, "policy", routeAudit.Policy)
 
-	protectedSubnets := collectProtectedSubnets(params.osEnv())
+	configuredSubnetList := collectProtectedSubnets(params.osEnv())
+	protectedSubnetList := configuredSubnetList
+	// Auto-detection needs access to the network of the node, so it is opt-in
+	if params.getEnv("AUTO_PROTECT_SUBNETS") == "true" {
+		protectedSubnetList = append(append([]*net.IPNet{}, configuredSubnetList...), detectProtectedSubnets(params, nl, mgr, hostname)...)
+	}
+	protectedSubnets := cidr.NewSet(protectedSubnetList...)
+
+	// StaticRoutePolicy CRD is optional, the StaticRoutes are checked only against the protected subnets without it
+	policies := false
+	for _, resource := range resources.APIResources {
+		policies = policies || resource.Kind == "StaticRoutePolicy"
+	}
+	params.logger.Info("StaticRoutePolicies enabled", "value", policies)
 
 	crdFound := false
 	for _, resource := range resources.APIResources {
@@ -219,7 +303,7 @@ func mainImpl(params mainImplParams) {
 		}
 
 		// Create RouteManager
-		routeManager := params.newRouterManager()
+		routeManager := params.newRouterManager(nl, routeProtocol, routeAudit)
 		stopChan := make(chan struct{})
 		go func() {
 			panic(routeManager.Run(stopChan))
@@ -227,12 +311,29 @@ func mainImpl(params mainImplParams) {
 
 		// Start static route controller
 		if err := params.addStaticRouteController(mgr, staticroute.ManagerOptions{
-			Hostname:                 hostname,
-			Table:                    table,
-			ProtectedSubnets:         protectedSubnets,
-			FallbackIPForGwSelection: fallbackIP,
-			RouteManager:             routeManager,
-			GetGw:                    params.getGw,
+			Hostname:                   hostname,
+			Table:                      table,
+			ProtectedSubnets:           protectedSubnets,
+			Policies:                   policies,
+			FallbackIPForGwSelection:   fallbackIP,
+			FallbackIPv6ForGwSelection: fallbackIPv6,
+			RouteManager:               routeManager,
+			GetGw: func(ip net.IP) (net.IP, error) {
+				return params.getGw(nl, ip)
+			},
+			GetLinkIndex: func(name string) (int, error) {
+				return params.getLinkIndex(nl, name)
+			},
+			GetLinkIndexByAddress: func(subnet *net.IPNet) (int, error) {
+				return params.getLinkIndexByAddress(nl, subnet)
+			},
+			GetLinkAddress: func(name string, ipv6 bool) (net.IP, error) {
+				return params.getLinkAddress(nl, name, ipv6)
+			},
+			TamperReactionBackoff: tamperReactionBackoff,
+			ProbeGateway: func(method string, gateway net.IP, port int, timeout time.Duration) error {
+				return params.probeGateway(nl, method, gateway, port, timeout)
+			},
 		}); err != nil {
 			panic(err)
 		}
@@ -244,11 +345,55 @@ func mainImpl(params mainImplParams) {
 		panic(err)
 	}
 
+	// StaticRouteRule CRD is optional, policy routing rules are managed only if it is installed
+	for _, resource := range resources.APIResources {
+		if resource.Kind != "StaticRouteRule" {
+			continue
+		}
+
+		// Create RuleManager, the rules carry the same protocol ID as the routes
+		ruleManager := params.newRuleManager(nl, routeProtocol)
+		stopChan := make(chan struct{})
+		go func() {
+			panic(ruleManager.Run(stopChan))
+		}()
+
+		// Start static route rule controller
+		if err := params.addStaticRouteRuleController(mgr, staticrouterule.ManagerOptions{
+			Hostname:              hostname,
+			RuleManager:           ruleManager,
+			TamperReactionBackoff: tamperReactionBackoff,
+		}); err != nil {
+			panic(err)
+		}
+		break
+	}
+
 	// Start node controller
 	if err := params.addNodeController(mgr); err != nil {
 		panic(err)
 	}
 
+	// Start validating and conversion webhooks
+	if webhooksEnabled {
+		params.logger.Info("Registering validating and conversion webhooks.")
+		// The webhook validates the StaticRoutes of the whole cluster, so the subnets detected on this node
+		// must not be enforced there, only the configured ones
+		if err := params.addStaticRouteWebhook(mgr, &staticroutev1.StaticRouteValidator{
+			Client:           mgr.GetClient(),
+			ProtectedSubnets: cidr.NewSet(configuredSubnetList...),
+			DefaultTable:     table,
+			Policies:         policies,
+		}); err != nil {
+			panic(err)
+		}
+		// The StaticRoutes can be migrated to the storage version only through the conversion webhook, the leader
+		// elected among the operator instances migrates them
+		if err := params.addStorageMigration(mgr); err != nil {
+			panic(err)
+		}
+	}
+
 	params.logger.Info("Starting the Cmd.")
 	// Start the Cmd
 
@@ -258,6 +403,15 @@ func mainImpl(params mainImplParams) {
 	}
 }
 
+// addToScheme registers all versions of the API, v1 is used by the controllers and the other versions are converted
+// to it by the conversion webhook
+func addToScheme(s *kRuntime.Scheme) error {
+	if err := staticroutev1.AddToScheme(s); err != nil {
+		return err
+	}
+	return staticroutev2.AddToScheme(s)
+}
+
 func parseTargetTable(targetTableEnv string) int {
 	if customTable, err := strconv.Atoi(targetTableEnv); err != nil {
 		panic(fmt.Sprintf("Unable to parse custom table 'TARGET_TABLE=%s' %s", targetTableEnv, err.Error()))
@@ -268,6 +422,193 @@ func parseTargetTable(targetTableEnv string) int {
 	}
 }
 
+// parseRouteProtocol accepts the protocol IDs above the ones reserved by the kernel (RTPROT_STATIC),
+// as all the routes with the given ID are removed at startup if they are not managed by the operator.
+func parseRouteProtocol(routeProtocolEnv string) int {
+	if protocol, err := strconv.Atoi(routeProtocolEnv); err != nil {
+		panic(fmt.Sprintf("Unable to parse route protocol 'ROUTE_PROTOCOL=%s' %s", routeProtocolEnv, err.Error()))
+	} else if protocol <= unix.RTPROT_STATIC || protocol > 255 {
+		panic(fmt.Sprintf("Route protocol must be between %d and 255 'ROUTE_PROTOCOL=%s'", unix.RTPROT_STATIC+1, routeProtocolEnv))
+	} else {
+		return protocol
+	}
+}
+
+func parseTamperReactionBackoff(tamperReactionBackoffEnv string) time.Duration {
+	if backoff, err := time.ParseDuration(tamperReactionBackoffEnv); err != nil {
+		panic(fmt.Sprintf("Unable to parse tamper reaction backoff 'TAMPER_REACTION_BACKOFF=%s' %s", tamperReactionBackoffEnv, err.Error()))
+	} else if backoff <= 0 {
+		panic(fmt.Sprintf("Tamper reaction backoff must be positive 'TAMPER_REACTION_BACKOFF=%s'", tamperReactionBackoffEnv))
+	} else {
+		return backoff
+	}
+}
+
+// parseRouteAuditInterval accepts 0 as well, which disables the periodic audit
+func parseRouteAuditInterval(routeAuditIntervalEnv string) time.Duration {
+	if interval, err := time.ParseDuration(routeAuditIntervalEnv); err != nil {
+		panic(fmt.Sprintf("Unable to parse route audit interval 'ROUTE_AUDIT_INTERVAL=%s' %s", routeAuditIntervalEnv, err.Error()))
+	} else if interval < 0 {
+		panic(fmt.Sprintf("Route audit interval must not be negative 'ROUTE_AUDIT_INTERVAL=%s'", routeAuditIntervalEnv))
+	} else {
+		return interval
+	}
+}
+
+// newNetlink returns the Netlink of the network namespace bind mounted to the path, or of the process if it is empty
+func newNetlink(path string) routemanager.Netlink {
+	if len(path) == 0 {
+		return routemanager.NewNetlink()
+	}
+	nl, err := routemanager.NewNetlinkAtPath(path)
+	if err != nil {
+		panic(fmt.Sprintf("Network namespace can not be opened 'ROUTE_NETNS=%s': %s", path, err.Error()))
+	}
+	log.Info("Network namespace selected", "path", path)
+	return nl
+}
+
+// getGw returns the gateway of the route selected by the kernel towards the IP
+func getGw(nl routemanager.Netlink, ip net.IP) (net.IP, error) {
+	route, err := nl.RouteGet(ip)
+	if err != nil {
+		return nil, err
+	}
+	return route[0].Gw, nil
+}
+
+// getLinkIndex returns the index of the named link, or 0 if it does not exist
+func getLinkIndex(nl routemanager.Netlink, name string) (int, error) {
+	link, err := nl.LinkByName(name)
+	if _, notFound := err.(netlink.LinkNotFoundError); notFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return link.Attrs().Index, nil
+}
+
+// getLinkIndexByAddress returns the index of the link having an address in the subnet, or 0 if there is none
+func getLinkIndexByAddress(nl routemanager.Netlink, subnet *net.IPNet) (int, error) {
+	addrs, err := nl.AddrList(nil, netlink.FAMILY_ALL)
+	if err != nil {
+		return 0, err
+	}
+	for _, addr := range addrs {
+		if subnet.Contains(addr.IP) {
+			return addr.LinkIndex, nil
+		}
+	}
+	return 0, nil
+}
+
+// getLinkAddress returns the first global address of the named link in the family, or nil if there is none
+func getLinkAddress(nl routemanager.Netlink, name string, ipv6 bool) (net.IP, error) {
+	link, err := nl.LinkByName(name)
+	if _, notFound := err.(netlink.LinkNotFoundError); notFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	family := netlink.FAMILY_V4
+	if ipv6 {
+		family = netlink.FAMILY_V6
+	}
+	addrs, err := nl.AddrList(link, family)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if addr.Scope == unix.RT_SCOPE_UNIVERSE {
+			return addr.IP, nil
+		}
+	}
+	return nil, nil
+}
+
+// getNodeSubnets returns the subnets of the global addresses of the node
+func getNodeSubnets(nl routemanager.Netlink) ([]*net.IPNet, error) {
+	addrs, err := nl.AddrList(nil, netlink.FAMILY_ALL)
+	if err != nil {
+		return nil, err
+	}
+	subnets := []*net.IPNet{}
+	for _, addr := range addrs {
+		// Loopback and link local addresses are not routed anyway
+		if addr.Scope != unix.RT_SCOPE_UNIVERSE {
+			continue
+		}
+		subnets = append(subnets, &net.IPNet{IP: addr.IP.Mask(addr.Mask), Mask: addr.Mask})
+	}
+	return subnets, nil
+}
+
+func parseRouteAuditPolicy(routeAuditPolicyEnv string) routemanager.RepairPolicy {
+	switch policy := routemanager.RepairPolicy(routeAuditPolicyEnv); policy {
+	case routemanager.RepairNone, routemanager.RepairRestore, routemanager.RepairEnforce:
+		return policy
+	default:
+		panic(fmt.Sprintf("Route audit policy must be one of none, restore or enforce 'ROUTE_AUDIT_POLICY=%s'", routeAuditPolicyEnv))
+	}
+}
+
+// parseFallbackIPs accepts at most one IPv4 and one IPv6 address separated by comma.
+// If no IPv4 address is given, the default one is kept.
+func parseFallbackIPs(fallbackIPEnv string, defaultIP net.IP) (fallbackIP, fallbackIPv6 net.IP) {
+	fallbackIP = defaultIP
+	ipv4Set := false
+	for _, value := range strings.Split(fallbackIPEnv, ",") {
+		ip := net.ParseIP(strings.Trim(value, " "))
+		switch {
+		case ip == nil:
+			panic("Environment variable parse error: FALLBACK_IP_FOR_GW_SELECTION.")
+		case ip.To4() != nil && !ipv4Set:
+			fallbackIP = ip
+			ipv4Set = true
+		case ip.To4() == nil && fallbackIPv6 == nil:
+			fallbackIPv6 = ip
+		default:
+			panic("Environment variable FALLBACK_IP_FOR_GW_SELECTION must contain at most one IP address per family.")
+		}
+	}
+	return
+}
+
+// detectProtectedSubnets returns the networks of the node: the subnets of its addresses, its pod CIDRs and the
+// service CIDRs given in the SERVICE_CIDR environment variable
+func detectProtectedSubnets(params mainImplParams, nl routemanager.Netlink, mgr manager.Manager, hostname string) []*net.IPNet {
+	subnets, err := params.getNodeSubnets(nl)
+	if err != nil {
+		panic(err)
+	}
+	node := &corev1.Node{}
+	if err := mgr.GetAPIReader().Get(context.Background(), client.ObjectKey{Name: hostname}, node); err != nil {
+		panic(err)
+	}
+	podCIDRs := node.Spec.PodCIDRs
+	if len(podCIDRs) == 0 && len(node.Spec.PodCIDR) != 0 {
+		podCIDRs = []string{node.Spec.PodCIDR}
+	}
+	for _, podCIDR := range podCIDRs {
+		_, subnet, err := net.ParseCIDR(podCIDR)
+		if err != nil {
+			panic(fmt.Sprintf("Unable to parse pod CIDR of the node '%s' %s", podCIDR, err.Error()))
+		}
+		subnets = append(subnets, subnet)
+	}
+	if serviceCIDREnv := params.getEnv("SERVICE_CIDR"); len(serviceCIDREnv) != 0 {
+		for _, serviceCIDR := range strings.Split(serviceCIDREnv, ",") {
+			_, subnet, err := net.ParseCIDR(strings.TrimSpace(serviceCIDR))
+			if err != nil {
+				panic(fmt.Sprintf("Unable to parse service CIDR 'SERVICE_CIDR=%s' %s", serviceCIDREnv, err.Error()))
+			}
+			subnets = append(subnets, subnet)
+		}
+	}
+	params.logger.Info("Protected subnets detected", "subnets", subnets)
+	return subnets
+}
+
 func collectProtectedSubnets(envVars []string) []*net.IPNet {
 	protectedSubnets := []*net.IPNet{}
 	for _, e := range envVars {
@@ -18,17 +18,27 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"runtime/debug"
 	"testing"
+	"time"
 
 	goruntime "runtime"
 
+	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
+	staticroutev2 "github.com/IBM/staticroute-operator/api/v2"
 	"github.com/IBM/staticroute-operator/controllers/staticroute"
+	"github.com/IBM/staticroute-operator/controllers/staticrouterule"
 	"github.com/IBM/staticroute-operator/pkg/routemanager"
+	nlfake "github.com/IBM/staticroute-operator/pkg/routemanager/fake"
+	"github.com/IBM/staticroute-operator/pkg/rulemanager"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -79,6 +89,21 @@ func TestMainImplTargetTableOk(t *testing.T) {
 	}
 }
 
+func TestMainImplInterfaceLookup(t *testing.T) {
+	var options staticroute.ManagerOptions
+	params, _ := getContextForHappyFlow()
+	params.addStaticRouteController = func(mgr manager.Manager, o staticroute.ManagerOptions) error {
+		options = o
+		return nil
+	}
+
+	mainImpl(*params)
+
+	if options.GetLinkIndex == nil || options.GetLinkIndexByAddress == nil || options.GetLinkAddress == nil {
+		t.Error("Interface lookup functions must be passed to the controller")
+	}
+}
+
 func TestMainImplProtectedSubnetsOk(t *testing.T) {
 	var actualSubnets []*net.IPNet
 	defer catchError(t)()
@@ -88,10 +113,11 @@ func TestMainImplProtectedSubnetsOk(t *testing.T) {
 		"NODE_HOSTNAME=",
 		"PROTECTED_SUBNET_CALICO=10.0.0.0/8,20.0.0.0/8",
 		"PROTECTED_SUBNET_HOST=192.168.0.0/24",
+		"PROTECTED_SUBNET_IPV6=fd00::/8",
 		"TARGET_TABLE=",
 	})
 	params.addStaticRouteController = func(mgr manager.Manager, options staticroute.ManagerOptions) error {
-		actualSubnets = options.ProtectedSubnets
+		actualSubnets = options.ProtectedSubnets.Subnets()
 		return nil
 	}
 
@@ -101,12 +127,104 @@ func TestMainImplProtectedSubnetsOk(t *testing.T) {
 		&net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.IPv4Mask(0xff, 0, 0, 0)},
 		&net.IPNet{IP: net.IP{20, 0, 0, 0}, Mask: net.IPv4Mask(0xff, 0, 0, 0)},
 		&net.IPNet{IP: net.IP{192, 168, 0, 0}, Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)},
+		&net.IPNet{IP: net.ParseIP("fd00::"), Mask: net.CIDRMask(8, 128)},
 	}
 	if fmt.Sprintf("%v", expectedSubnets) != fmt.Sprintf("%v", actualSubnets) {
 		t.Errorf("Protected subnets are not match %v != %v", expectedSubnets, actualSubnets)
 	}
 }
 
+func TestMainImplAutoProtectSubnets(t *testing.T) {
+	var actualSubnets []*net.IPNet
+	defer catchError(t)()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(withEnvMock(params.getEnv, "AUTO_PROTECT_SUBNETS", "true"), "SERVICE_CIDR", "172.21.0.0/16, fd02::/112")
+	params.osEnv = osEnvMock([]string{"PROTECTED_SUBNET_HOST=192.168.0.0/24"})
+	params.getNodeSubnets = func(routemanager.Netlink) ([]*net.IPNet, error) {
+		return []*net.IPNet{{IP: net.IP{10, 1, 2, 0}, Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)}}, nil
+	}
+	client := newFakeClient()
+	node := &corev1.Node{}
+	if err := client.Get(context.Background(), types.NamespacedName{Name: "hostname"}, node); err != nil {
+		t.Fatalf("Failed to read the node: %s", err.Error())
+	}
+	node.Spec.PodCIDRs = []string{"172.30.0.0/24"}
+	if err := client.Update(context.Background(), node); err != nil {
+		t.Fatalf("Failed to update the node: %s", err.Error())
+	}
+	params.newManager = func(*rest.Config, manager.Options) (manager.Manager, error) {
+		return mockManager{client: client}, nil
+	}
+	params.addStaticRouteController = func(mgr manager.Manager, options staticroute.ManagerOptions) error {
+		actualSubnets = options.ProtectedSubnets.Subnets()
+		return nil
+	}
+
+	mainImpl(*params)
+
+	expected := "[192.168.0.0/24 10.1.2.0/24 172.30.0.0/24 172.21.0.0/16 fd02::/112]"
+	if fmt.Sprintf("%v", actualSubnets) != expected {
+		t.Errorf("Protected subnets are not match %s != %v", expected, actualSubnets)
+	}
+}
+
+func TestMainImplAutoProtectSubnetsNotPassedToWebhook(t *testing.T) {
+	var actualSubnets []*net.IPNet
+	defer catchError(t)()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(withEnvMock(params.getEnv, "AUTO_PROTECT_SUBNETS", "true"), "ENABLE_WEBHOOKS", "true")
+	params.osEnv = osEnvMock([]string{"PROTECTED_SUBNET_HOST=192.168.0.0/24"})
+	params.getNodeSubnets = func(routemanager.Netlink) ([]*net.IPNet, error) {
+		return []*net.IPNet{{IP: net.IP{10, 1, 2, 0}, Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)}}, nil
+	}
+	params.addStaticRouteWebhook = func(mgr manager.Manager, validator *staticroutev1.StaticRouteValidator) error {
+		actualSubnets = validator.ProtectedSubnets.Subnets()
+		return nil
+	}
+
+	mainImpl(*params)
+
+	expected := "[192.168.0.0/24]"
+	if fmt.Sprintf("%v", actualSubnets) != expected {
+		t.Errorf("Protected subnets of the webhook are not match %s != %v", expected, actualSubnets)
+	}
+}
+
+func TestMainImplAutoProtectSubnetsDisabled(t *testing.T) {
+	defer catchError(t)()
+	params, callbacks := getContextForHappyFlow()
+	params.getEnv = withEnvMock(params.getEnv, "SERVICE_CIDR", "invalid")
+
+	mainImpl(*params)
+
+	if callbacks.getNodeSubnetsCalled {
+		t.Error("Node subnets must not be detected")
+	}
+}
+
+func TestMainImplAutoProtectSubnetsInvalidServiceCIDR(t *testing.T) {
+	defer validateRecovery(t, "Unable to parse service CIDR 'SERVICE_CIDR=172.21.0.0' invalid CIDR address: 172.21.0.0")()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(withEnvMock(params.getEnv, "AUTO_PROTECT_SUBNETS", "true"), "SERVICE_CIDR", "172.21.0.0")
+
+	mainImpl(*params)
+
+	t.Error("Error didn't appear")
+}
+
+func TestMainImplAutoProtectSubnetsNetlinkError(t *testing.T) {
+	defer validateRecovery(t, "netlink failed")()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(params.getEnv, "AUTO_PROTECT_SUBNETS", "true")
+	params.getNodeSubnets = func(routemanager.Netlink) ([]*net.IPNet, error) {
+		return nil, errors.New("netlink failed")
+	}
+
+	mainImpl(*params)
+
+	t.Error("Error didn't appear")
+}
+
 func TestMainImplFallbackIPOk(t *testing.T) {
 	var actualFallbackIP net.IP
 	expectedFallbackIP := net.IP{192, 168, 1, 1}
@@ -125,6 +243,264 @@ func TestMainImplFallbackIPOk(t *testing.T) {
 	}
 }
 
+func TestMainImplTamperReactionBackoffOk(t *testing.T) {
+	var actualBackoff time.Duration
+	defer catchError(t)()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(params.getEnv, "TAMPER_REACTION_BACKOFF", "1m")
+	params.addStaticRouteController = func(mgr manager.Manager, options staticroute.ManagerOptions) error {
+		actualBackoff = options.TamperReactionBackoff
+		return nil
+	}
+
+	mainImpl(*params)
+
+	if actualBackoff != time.Minute {
+		t.Errorf("Tamper reaction backoff not match 1m != %s", actualBackoff)
+	}
+}
+
+func TestMainImplTamperReactionBackoffDefault(t *testing.T) {
+	var actualBackoff time.Duration
+	defer catchError(t)()
+	params, _ := getContextForHappyFlow()
+	params.addStaticRouteController = func(mgr manager.Manager, options staticroute.ManagerOptions) error {
+		actualBackoff = options.TamperReactionBackoff
+		return nil
+	}
+
+	mainImpl(*params)
+
+	if actualBackoff != defaultTamperReactionBackoff {
+		t.Errorf("Tamper reaction backoff not match %s != %s", defaultTamperReactionBackoff, actualBackoff)
+	}
+}
+
+func TestMainImplTamperReactionBackoffInvalid(t *testing.T) {
+	defer validateRecovery(t, "Unable to parse tamper reaction backoff 'TAMPER_REACTION_BACKOFF=invalid' time: invalid duration \"invalid\"")()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(params.getEnv, "TAMPER_REACTION_BACKOFF", "invalid")
+
+	mainImpl(*params)
+
+	t.Error("Error didn't appear")
+}
+
+func TestMainImplTamperReactionBackoffNotPositive(t *testing.T) {
+	defer validateRecovery(t, "Tamper reaction backoff must be positive 'TAMPER_REACTION_BACKOFF=0s'")()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(params.getEnv, "TAMPER_REACTION_BACKOFF", "0s")
+
+	mainImpl(*params)
+
+	t.Error("Error didn't appear")
+}
+
+func TestMainImplRouteProtocolOk(t *testing.T) {
+	var actualProtocol int
+	defer catchError(t)()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "ROUTE_PROTOCOL", "42")
+	params.newRouterManager = func(_ routemanager.Netlink, protocol int, _ routemanager.AuditOptions) routemanager.RouteManager {
+		actualProtocol = protocol
+		return mockRouteManager{}
+	}
+
+	mainImpl(*params)
+
+	if actualProtocol != 42 {
+		t.Errorf("Route protocol not match 42 != %d", actualProtocol)
+	}
+}
+
+func TestMainImplRouteProtocolDefault(t *testing.T) {
+	var actualProtocol int
+	defer catchError(t)()
+	params, _ := getContextForHappyFlow()
+	params.newRouterManager = func(_ routemanager.Netlink, protocol int, _ routemanager.AuditOptions) routemanager.RouteManager {
+		actualProtocol = protocol
+		return mockRouteManager{}
+	}
+
+	mainImpl(*params)
+
+	if actualProtocol != defaultRouteProtocol {
+		t.Errorf("Route protocol not match %d != %d", defaultRouteProtocol, actualProtocol)
+	}
+}
+
+func TestMainImplRouteProtocolInvalid(t *testing.T) {
+	defer validateRecovery(t, "Unable to parse route protocol 'ROUTE_PROTOCOL=foo' strconv.Atoi: parsing \"foo\": invalid syntax")()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "ROUTE_PROTOCOL", "foo")
+
+	mainImpl(*params)
+
+	t.Error("Error didn't appear")
+}
+
+func TestMainImplRouteProtocolReserved(t *testing.T) {
+	defer validateRecovery(t, "Route protocol must be between 5 and 255 'ROUTE_PROTOCOL=4'")()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "ROUTE_PROTOCOL", "4")
+
+	mainImpl(*params)
+
+	t.Error("Error didn't appear")
+}
+
+func TestMainImplRouteProtocolGreater(t *testing.T) {
+	defer validateRecovery(t, "Route protocol must be between 5 and 255 'ROUTE_PROTOCOL=256'")()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "ROUTE_PROTOCOL", "256")
+
+	mainImpl(*params)
+
+	t.Error("Error didn't appear")
+}
+
+func TestMainImplRouteAuditOk(t *testing.T) {
+	var actualAudit routemanager.AuditOptions
+	defer catchError(t)()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(withEnvMock(getEnvMock("", "hostname", "", "", ""), "ROUTE_AUDIT_INTERVAL", "1m"), "ROUTE_AUDIT_POLICY", "enforce")
+	params.newRouterManager = func(_ routemanager.Netlink, _ int, audit routemanager.AuditOptions) routemanager.RouteManager {
+		actualAudit = audit
+		return mockRouteManager{}
+	}
+
+	mainImpl(*params)
+
+	if actualAudit.Interval != time.Minute || actualAudit.Policy != routemanager.RepairEnforce {
+		t.Errorf("Route audit not match 1m enforce != %+v", actualAudit)
+	}
+}
+
+func TestMainImplRouteAuditDefault(t *testing.T) {
+	var actualAudit routemanager.AuditOptions
+	defer catchError(t)()
+	params, _ := getContextForHappyFlow()
+	params.newRouterManager = func(_ routemanager.Netlink, _ int, audit routemanager.AuditOptions) routemanager.RouteManager {
+		actualAudit = audit
+		return mockRouteManager{}
+	}
+
+	mainImpl(*params)
+
+	if actualAudit.Interval != 0 || actualAudit.Policy != defaultRouteAuditPolicy {
+		t.Errorf("Route audit must be disabled by default: %+v", actualAudit)
+	}
+}
+
+func TestMainImplRouteAuditIntervalInvalid(t *testing.T) {
+	defer validateRecovery(t, "Route audit interval must not be negative 'ROUTE_AUDIT_INTERVAL=-1s'")()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "ROUTE_AUDIT_INTERVAL", "-1s")
+
+	mainImpl(*params)
+
+	t.Error("Error didn't appear")
+}
+
+func TestMainImplRouteAuditPolicyInvalid(t *testing.T) {
+	defer validateRecovery(t, "Route audit policy must be one of none, restore or enforce 'ROUTE_AUDIT_POLICY=fix'")()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "ROUTE_AUDIT_POLICY", "fix")
+
+	mainImpl(*params)
+
+	t.Error("Error didn't appear")
+}
+
+func TestNewNetlinkOfProcess(t *testing.T) {
+	if nl := newNetlink(""); nl == nil {
+		t.Error("Netlink of the process must be returned")
+	}
+}
+
+func TestNewNetlinkMissingNamespace(t *testing.T) {
+	defer validateRecovery(t, "Network namespace can not be opened 'ROUTE_NETNS=/nonexistent/netns': no such file or directory")()
+
+	newNetlink("/nonexistent/netns")
+
+	t.Error("Error didn't appear")
+}
+
+func TestMainImplRouteNetns(t *testing.T) {
+	defer catchError(t)()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "ROUTE_NETNS", "/run/netns/host")
+	var netnsPath string
+	params.newNetlink = func(path string) routemanager.Netlink {
+		netnsPath = path
+		return nlfake.NewNetlink()
+	}
+
+	mainImpl(*params)
+
+	if netnsPath != "/run/netns/host" {
+		t.Errorf("Network namespace mismatch: /run/netns/host != %s", netnsPath)
+	}
+}
+
+func TestMainImplRouteNetnsCanNotBeOpened(t *testing.T) {
+	defer validateRecovery(t, "Network namespace can not be opened 'ROUTE_NETNS=/nonexistent/netns': no such file or directory")()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "ROUTE_NETNS", "/nonexistent/netns")
+	params.newNetlink = newNetlink
+
+	mainImpl(*params)
+
+	t.Error("Error didn't appear")
+}
+
+func TestMainImplMetricsBindAddress(t *testing.T) {
+	var testData = []struct {
+		env      string
+		expected string
+	}{
+		{"", defaultMetricsBindAddress},
+		{"127.0.0.1:8080", "127.0.0.1:8080"},
+		{"0", "0"},
+	}
+	for _, td := range testData {
+		var actualBindAddress string
+		params, _ := getContextForHappyFlow()
+		params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "METRICS_BIND_ADDRESS", td.env)
+		params.newManager = func(c *rest.Config, o manager.Options) (manager.Manager, error) {
+			actualBindAddress = o.Metrics.BindAddress
+			return mockManager{}, nil
+		}
+
+		mainImpl(*params)
+
+		if actualBindAddress != td.expected {
+			t.Errorf("Metrics bind address not match %s != %s", td.expected, actualBindAddress)
+		}
+	}
+}
+
+func TestMainImplLeaderElection(t *testing.T) {
+	for _, webhooks := range []string{"", "true"} {
+		var options manager.Options
+		params, _ := getContextForHappyFlow()
+		params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "ENABLE_WEBHOOKS", webhooks)
+		params.newManager = func(c *rest.Config, o manager.Options) (manager.Manager, error) {
+			options = o
+			return mockManager{}, nil
+		}
+
+		mainImpl(*params)
+
+		if options.LeaderElection != (webhooks == "true") || options.LeaderElectionID != leaderElectionID {
+			t.Errorf("Leader election must be enabled only for the storage migration of the webhooks: %t %s", options.LeaderElection, options.LeaderElectionID)
+		}
+		if options.Controller.NeedLeaderElection == nil || *options.Controller.NeedLeaderElection {
+			t.Error("Controllers must run on every node")
+		}
+	}
+}
+
 func TestMainImplGetConfigFails(t *testing.T) {
 	err := new(goruntime.PanicNilError)
 	defer validateRecovery(t, err)()
@@ -227,9 +603,54 @@ func TestMainImplFallbackIPInvalid(t *testing.T) {
 }
 
 func TestMainImplFallbackIPv6Provided(t *testing.T) {
-	defer validateRecovery(t, "Environment variable parse error: FALLBACK_IP_FOR_GW_SELECTION.")()
+	var actualFallbackIP, actualFallbackIPv6 net.IP
+	expectedFallbackIPv6 := net.ParseIP("1:2:3:4:5::6")
+	defer catchError(t)()
 	params, _ := getContextForHappyFlow()
-	params.getEnv = getEnvMock("", "hostname", "", "", "1:2:3:4:5::6")
+	params.getEnv = getEnvMock("", "hostname", "", "", expectedFallbackIPv6.String())
+	params.addStaticRouteController = func(mgr manager.Manager, options staticroute.ManagerOptions) error {
+		actualFallbackIP = options.FallbackIPForGwSelection
+		actualFallbackIPv6 = options.FallbackIPv6ForGwSelection
+		return nil
+	}
+
+	mainImpl(*params)
+
+	if !defaultFallbackIP.Equal(actualFallbackIP) {
+		t.Errorf("Default fallback IP must be kept %s != %s", defaultFallbackIP.String(), actualFallbackIP.String())
+	}
+	if !expectedFallbackIPv6.Equal(actualFallbackIPv6) {
+		t.Errorf("Invalid IPv6 fallback IP detected %s != %s", expectedFallbackIPv6.String(), actualFallbackIPv6.String())
+	}
+}
+
+func TestMainImplFallbackIPBothFamiliesProvided(t *testing.T) {
+	var actualFallbackIP, actualFallbackIPv6 net.IP
+	expectedFallbackIP := net.IP{192, 168, 1, 1}
+	expectedFallbackIPv6 := net.ParseIP("fd00::1")
+	defer catchError(t)()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = getEnvMock("", "hostname", "", "", "fd00::1, 192.168.1.1")
+	params.addStaticRouteController = func(mgr manager.Manager, options staticroute.ManagerOptions) error {
+		actualFallbackIP = options.FallbackIPForGwSelection
+		actualFallbackIPv6 = options.FallbackIPv6ForGwSelection
+		return nil
+	}
+
+	mainImpl(*params)
+
+	if !expectedFallbackIP.Equal(actualFallbackIP) {
+		t.Errorf("Invalid fallback IP detected %s != %s", expectedFallbackIP.String(), actualFallbackIP.String())
+	}
+	if !expectedFallbackIPv6.Equal(actualFallbackIPv6) {
+		t.Errorf("Invalid IPv6 fallback IP detected %s != %s", expectedFallbackIPv6.String(), actualFallbackIPv6.String())
+	}
+}
+
+func TestMainImplFallbackIPSameFamilyTwice(t *testing.T) {
+	defer validateRecovery(t, "Environment variable FALLBACK_IP_FOR_GW_SELECTION must contain at most one IP address per family.")()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = getEnvMock("", "hostname", "", "", "fd00::1,fd00::2")
 
 	mainImpl(*params)
 
@@ -288,6 +709,85 @@ func TestMainImplAddStaticRouteControllerFails(t *testing.T) {
 	t.Error("Error didn't appear")
 }
 
+func TestMainImplStaticRouteRule(t *testing.T) {
+	var actualOptions staticrouterule.ManagerOptions
+	var actualProtocol int
+	defer catchError(t)()
+	params, callbacks := getContextForHappyFlow()
+	params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "ROUTE_PROTOCOL", "200")
+	params.newKubernetesConfig = func(c *rest.Config) (discoverable, error) {
+		return mockDiscoverable{apiResourceList: &metav1.APIResourceList{
+			APIResources: []metav1.APIResource{{Kind: "StaticRoute"}, {Kind: "StaticRouteRule"}},
+		}}, nil
+	}
+	params.newRuleManager = func(_ routemanager.Netlink, protocol int) rulemanager.RuleManager {
+		actualProtocol = protocol
+		return mockRuleManager{}
+	}
+	params.addStaticRouteRuleController = func(mgr manager.Manager, options staticrouterule.ManagerOptions) error {
+		callbacks.addStaticRouteRuleControllerCalled = true
+		actualOptions = options
+		return nil
+	}
+
+	mainImpl(*params)
+
+	if !callbacks.addStaticRouteRuleControllerCalled {
+		t.Fatal("StaticRouteRule controller must be added")
+	}
+	if actualProtocol != 200 || actualOptions.Hostname != "hostname" || actualOptions.RuleManager == nil || actualOptions.TamperReactionBackoff != defaultTamperReactionBackoff {
+		t.Errorf("StaticRouteRule controller is not configured properly: %d %+v", actualProtocol, actualOptions)
+	}
+}
+
+func TestMainImplStaticRoutePolicy(t *testing.T) {
+	for _, policies := range []bool{false, true} {
+		var actualOptions staticroute.ManagerOptions
+		var actualValidator *staticroutev1.StaticRouteValidator
+		params, _ := getContextForHappyFlow()
+		params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "ENABLE_WEBHOOKS", "true")
+		resources := []metav1.APIResource{{Kind: "StaticRoute"}}
+		if policies {
+			resources = append(resources, metav1.APIResource{Kind: "StaticRoutePolicy"})
+		}
+		params.newKubernetesConfig = func(c *rest.Config) (discoverable, error) {
+			return mockDiscoverable{apiResourceList: &metav1.APIResourceList{APIResources: resources}}, nil
+		}
+		params.addStaticRouteController = func(mgr manager.Manager, options staticroute.ManagerOptions) error {
+			actualOptions = options
+			return nil
+		}
+		params.addStaticRouteWebhook = func(mgr manager.Manager, validator *staticroutev1.StaticRouteValidator) error {
+			actualValidator = validator
+			return nil
+		}
+
+		mainImpl(*params)
+
+		if actualOptions.Policies != policies || actualValidator.Policies != policies {
+			t.Errorf("StaticRoutePolicies must be enabled only if the CRD is installed (%t): %t %t", policies, actualOptions.Policies, actualValidator.Policies)
+		}
+	}
+}
+
+func TestMainImplAddStaticRouteRuleControllerFails(t *testing.T) {
+	err := new(goruntime.PanicNilError)
+	defer validateRecovery(t, err)()
+	params, _ := getContextForHappyFlow()
+	params.newKubernetesConfig = func(c *rest.Config) (discoverable, error) {
+		return mockDiscoverable{apiResourceList: &metav1.APIResourceList{
+			APIResources: []metav1.APIResource{{Kind: "StaticRoute"}, {Kind: "StaticRouteRule"}},
+		}}, nil
+	}
+	params.addStaticRouteRuleController = func(manager.Manager, staticrouterule.ManagerOptions) error {
+		return err
+	}
+
+	mainImpl(*params)
+
+	t.Error("Error didn't appear")
+}
+
 func TestMainImplAddNodeControllerFails(t *testing.T) {
 	err := new(goruntime.PanicNilError)
 	defer validateRecovery(t, err)()
@@ -301,6 +801,71 @@ func TestMainImplAddNodeControllerFails(t *testing.T) {
 	t.Error("Error didn't appear")
 }
 
+func TestMainImplWebhookEnabled(t *testing.T) {
+	var actualValidator *staticroutev1.StaticRouteValidator
+	defer catchError(t)()
+	params, callbacks := getContextForHappyFlow()
+	params.getEnv = withEnvMock(getEnvMock("", "hostname", "42", "", ""), "ENABLE_WEBHOOKS", "true")
+	params.osEnv = osEnvMock([]string{"PROTECTED_SUBNET_HOST=192.168.0.0/24"})
+	params.addStaticRouteWebhook = func(mgr manager.Manager, validator *staticroutev1.StaticRouteValidator) error {
+		callbacks.addStaticRouteWebhookCalled = true
+		actualValidator = validator
+		return nil
+	}
+
+	mainImpl(*params)
+
+	if !callbacks.addStaticRouteWebhookCalled {
+		t.Fatal("Webhook must be registered")
+	}
+	if !callbacks.addStorageMigrationCalled {
+		t.Error("Storage migration must be registered")
+	}
+	if actualValidator.DefaultTable != 42 || len(actualValidator.ProtectedSubnets.Subnets()) != 1 || actualValidator.Client == nil {
+		t.Errorf("Validator is not configured properly: %+v", actualValidator)
+	}
+}
+
+func TestMainImplAddStaticRouteWebhookFails(t *testing.T) {
+	err := new(goruntime.PanicNilError)
+	defer validateRecovery(t, err)()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "ENABLE_WEBHOOKS", "true")
+	params.addStaticRouteWebhook = func(manager.Manager, *staticroutev1.StaticRouteValidator) error {
+		return err
+	}
+
+	mainImpl(*params)
+
+	t.Error("Error didn't appear")
+}
+
+func TestMainImplAddStorageMigrationFails(t *testing.T) {
+	err := new(goruntime.PanicNilError)
+	defer validateRecovery(t, err)()
+	params, _ := getContextForHappyFlow()
+	params.getEnv = withEnvMock(getEnvMock("", "hostname", "", "", ""), "ENABLE_WEBHOOKS", "true")
+	params.addStorageMigration = func(manager.Manager) error {
+		return err
+	}
+
+	mainImpl(*params)
+
+	t.Error("Error didn't appear")
+}
+
+func TestAddToScheme(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := addToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	for _, gv := range []schema.GroupVersion{staticroutev1.GroupVersion, staticroutev2.GroupVersion} {
+		if !s.Recognizes(gv.WithKind("StaticRoute")) {
+			t.Errorf("StaticRoute %s is not registered", gv.Version)
+		}
+	}
+}
+
 func TestMainImplManagerStartFails(t *testing.T) {
 	err := new(goruntime.PanicNilError)
 	defer validateRecovery(t, err)()
@@ -336,7 +901,10 @@ func getContextForHappyFlow() (*mainImplParams, *mockCallbacks) {
 			callbacks.newKubernetesConfigCalled = true
 			return mockDiscoverable{}, nil
 		},
-		newRouterManager: func() routemanager.RouteManager {
+		newNetlink: func(string) routemanager.Netlink {
+			return nlfake.NewNetlink()
+		},
+		newRouterManager: func(routemanager.Netlink, int, routemanager.AuditOptions) routemanager.RouteManager {
 			callbacks.newRouterManagerCalled = true
 			return mockRouteManager{}
 		},
@@ -346,14 +914,46 @@ func getContextForHappyFlow() (*mainImplParams, *mockCallbacks) {
 			callbacks.addStaticRouteControllerCalled = true
 			return nil
 		},
+		newRuleManager: func(routemanager.Netlink, int) rulemanager.RuleManager {
+			callbacks.newRuleManagerCalled = true
+			return mockRuleManager{}
+		},
+		addStaticRouteRuleController: func(manager.Manager, staticrouterule.ManagerOptions) error {
+			callbacks.addStaticRouteRuleControllerCalled = true
+			return nil
+		},
 		addNodeController: func(manager.Manager) error {
 			callbacks.addNodeControllerCalled = true
 			return nil
 		},
-		getGw: func(ip net.IP) (net.IP, error) {
+		addStaticRouteWebhook: func(manager.Manager, *staticroutev1.StaticRouteValidator) error {
+			callbacks.addStaticRouteWebhookCalled = true
+			return nil
+		},
+		addStorageMigration: func(manager.Manager) error {
+			callbacks.addStorageMigrationCalled = true
+			return nil
+		},
+		getGw: func(_ routemanager.Netlink, ip net.IP) (net.IP, error) {
 			callbacks.routerGetCalled = true
 			return net.IP{10, 0, 0, 1}, nil
 		},
+		getLinkIndex: func(routemanager.Netlink, string) (int, error) {
+			return 1, nil
+		},
+		getLinkIndexByAddress: func(routemanager.Netlink, *net.IPNet) (int, error) {
+			return 1, nil
+		},
+		getLinkAddress: func(routemanager.Netlink, string, bool) (net.IP, error) {
+			return net.IP{10, 0, 0, 2}, nil
+		},
+		probeGateway: func(routemanager.Netlink, string, net.IP, int, time.Duration) error {
+			return nil
+		},
+		getNodeSubnets: func(routemanager.Netlink) ([]*net.IPNet, error) {
+			callbacks.getNodeSubnetsCalled = true
+			return []*net.IPNet{}, nil
+		},
 		setupSignalHandler: func() context.Context {
 			callbacks.setupSignalHandlerCalled = true
 			return context.TODO()
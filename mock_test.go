@@ -22,6 +22,7 @@ import (
 
 	staticroutev1 "github.com/IBM/staticroute-operator/api/v1"
 	"github.com/IBM/staticroute-operator/pkg/routemanager"
+	"github.com/IBM/staticroute-operator/pkg/rulemanager"
 	"github.com/go-logr/logr"
 	openapi_v2 "github.com/google/gnostic-models/openapiv2"
 	corev1 "k8s.io/api/core/v1"
@@ -61,6 +62,16 @@ func getEnvMock(metricsNS, nodeHostName, targetTable, protectedSubnets, fallback
 	}
 }
 
+// withEnvMock extends an existing getEnv mock with an additional variable
+func withEnvMock(getEnv func(string) string, name, value string) func(string) string {
+	return func(key string) string {
+		if key == name {
+			return value
+		}
+		return getEnv(key)
+	}
+}
+
 func osEnvMock(envvars []string) func() []string {
 	return func() []string {
 		return envvars
@@ -91,15 +102,20 @@ func (l mockLogger) Info(string, ...interface{}) {}
 func (l mockLogger) Error(error, string, ...interface{}) {}
 
 type mockCallbacks struct {
-	getConfigCalled                bool
-	newManagerCalled               bool
-	addToSchemeCalled              bool
-	newKubernetesConfigCalled      bool
-	newRouterManagerCalled         bool
-	addStaticRouteControllerCalled bool
-	addNodeControllerCalled        bool
-	routerGetCalled                bool
-	setupSignalHandlerCalled       bool
+	getConfigCalled                    bool
+	newManagerCalled                   bool
+	addToSchemeCalled                  bool
+	newKubernetesConfigCalled          bool
+	newRouterManagerCalled             bool
+	addStaticRouteControllerCalled     bool
+	newRuleManagerCalled               bool
+	addStaticRouteRuleControllerCalled bool
+	addNodeControllerCalled            bool
+	addStaticRouteWebhookCalled        bool
+	addStorageMigrationCalled          bool
+	routerGetCalled                    bool
+	getNodeSubnetsCalled               bool
+	setupSignalHandlerCalled           bool
 }
 
 type mockManager struct {
@@ -159,7 +175,7 @@ func (m mockManager) GetRESTMapper() meta.RESTMapper {
 }
 
 func (m mockManager) GetAPIReader() client.Reader {
-	return nil
+	return m.GetClient()
 }
 
 func (m mockManager) GetWebhookServer() webhook.Server {
@@ -196,14 +212,34 @@ func (m mockRouteManager) IsRegistered(string) bool {
 	return false
 }
 
+func (m mockRouteManager) GetRoute(string) (routemanager.Route, error) {
+	return routemanager.Route{}, routemanager.ErrNotFound
+}
+
+func (m mockRouteManager) ListRoutes() map[string]routemanager.Route {
+	return map[string]routemanager.Route{}
+}
+
 func (m mockRouteManager) RegisterRoute(string, routemanager.Route) error {
 	return nil
 }
 
+func (m mockRouteManager) UpdateRoute(string, routemanager.Route) error {
+	return nil
+}
+
 func (m mockRouteManager) DeRegisterRoute(string) error {
 	return nil
 }
 
+func (m mockRouteManager) CollectGarbage(func(routemanager.Route) (string, bool)) error {
+	return nil
+}
+
+func (m mockRouteManager) Audit() ([]routemanager.Drift, error) {
+	return nil, nil
+}
+
 func (m mockRouteManager) RegisterWatcher(routemanager.RouteWatcher) {
 
 }
@@ -217,6 +253,35 @@ func (m mockRouteManager) Run(stopChan chan struct{}) error {
 	return nil
 }
 
+type mockRuleManager struct{}
+
+func (m mockRuleManager) IsRegistered(string) bool {
+	return false
+}
+
+func (m mockRuleManager) RegisterRule(string, rulemanager.Rule) error {
+	return nil
+}
+
+func (m mockRuleManager) DeRegisterRule(string) error {
+	return nil
+}
+
+func (m mockRuleManager) CollectGarbage(func(rulemanager.Rule) (string, bool)) error {
+	return nil
+}
+
+func (m mockRuleManager) RegisterWatcher(rulemanager.RuleWatcher) {
+}
+
+func (m mockRuleManager) DeRegisterWatcher(rulemanager.RuleWatcher) {
+}
+
+func (m mockRuleManager) Run(stopChan chan struct{}) error {
+	<-stopChan
+	return nil
+}
+
 type mockDiscoverable struct {
 	apiResourceList                   *metav1.APIResourceList
 	serverResourcesForGroupVersionErr error
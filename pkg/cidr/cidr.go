@@ -0,0 +1,174 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// Package cidr implements overlap checks of subnets, which do not depend on the size of the subnets.
+package cidr
+
+import (
+	"net"
+)
+
+// Overlap returns true if the two subnets share any address. Two CIDRs overlap if and only if
+// one of them contains the network address of the other.
+func Overlap(a, b *net.IPNet) bool {
+	return a.Contains(b.IP.Mask(b.Mask)) || b.Contains(a.IP.Mask(a.Mask))
+}
+
+// Set is a binary prefix trie of subnets, it finds the overlapping subnets in O(prefix length)
+// regardless of the number and the size of the stored subnets. A nil Set is empty.
+// A Set must not be modified after it was created, but it can be queried concurrently.
+type Set struct {
+	v4      *node
+	v6      *node
+	subnets []*net.IPNet
+}
+
+type node struct {
+	children [2]*node
+	// subnet ends at this node
+	subnet *net.IPNet
+	// first is the first subnet inserted into the subtree of this node
+	first *net.IPNet
+}
+
+// NewSet creates a Set of the given subnets, the ones with a non-canonical mask are ignored
+func NewSet(subnets ...*net.IPNet) *Set {
+	s := &Set{}
+	for _, subnet := range subnets {
+		if subnet == nil {
+			continue
+		}
+		ip, ones, ok := prefix(subnet)
+		if !ok {
+			continue
+		}
+		root := &s.v6
+		if len(ip) == net.IPv4len {
+			root = &s.v4
+		}
+		if *root == nil {
+			*root = &node{}
+		}
+		n := *root
+		for i := 0; ; i++ {
+			if n.first == nil {
+				n.first = subnet
+			}
+			if i == ones {
+				break
+			}
+			b := bit(ip, i)
+			if n.children[b] == nil {
+				n.children[b] = &node{}
+			}
+			n = n.children[b]
+		}
+		if n.subnet == nil {
+			n.subnet = subnet
+		}
+		s.subnets = append(s.subnets, subnet)
+	}
+	return s
+}
+
+// Subnets returns the subnets of the Set in insertion order
+func (s *Set) Subnets() []*net.IPNet {
+	if s == nil {
+		return nil
+	}
+	return s.subnets
+}
+
+// Overlapping returns a subnet of the Set which overlaps with the given one, nil if there is none.
+// Subnets containing the given one are preferred over the ones inside it.
+func (s *Set) Overlapping(subnet *net.IPNet) *net.IPNet {
+	if s == nil || subnet == nil {
+		return nil
+	}
+	ip, ones, ok := prefix(subnet)
+	if !ok {
+		return nil
+	}
+	n := s.v6
+	if len(ip) == net.IPv4len {
+		n = s.v4
+	}
+	for i := 0; i < ones; i++ {
+		if n == nil {
+			return nil
+		}
+		if n.subnet != nil {
+			return n.subnet
+		}
+		n = n.children[bit(ip, i)]
+	}
+	if n == nil {
+		return nil
+	}
+	return n.first
+}
+
+// Containing returns a subnet of the Set which contains the given one, nil if there is none
+func (s *Set) Containing(subnet *net.IPNet) *net.IPNet {
+	if s == nil || subnet == nil {
+		return nil
+	}
+	ip, ones, ok := prefix(subnet)
+	if !ok {
+		return nil
+	}
+	n := s.v6
+	if len(ip) == net.IPv4len {
+		n = s.v4
+	}
+	for i := 0; n != nil; i++ {
+		if n.subnet != nil {
+			return n.subnet
+		}
+		if i == ones {
+			break
+		}
+		n = n.children[bit(ip, i)]
+	}
+	return nil
+}
+
+// Overlaps returns true if any subnet of the Set overlaps with the given one
+func (s *Set) Overlaps(subnet *net.IPNet) bool {
+	return s.Overlapping(subnet) != nil
+}
+
+// prefix returns the masked network address in its shortest form and the prefix length
+func prefix(subnet *net.IPNet) (net.IP, int, bool) {
+	ones, bits := subnet.Mask.Size()
+	if bits == 0 {
+		return nil, 0, false
+	}
+	ip := subnet.IP.Mask(subnet.Mask)
+	if ip == nil {
+		return nil, 0, false
+	}
+	if bits == 8*net.IPv4len {
+		ip = ip.To4()
+	} else {
+		ip = ip.To16()
+	}
+	return ip, ones, ip != nil
+}
+
+func bit(ip net.IP, i int) int {
+	return int(ip[i/8]>>(7-uint(i%8))) & 1
+}
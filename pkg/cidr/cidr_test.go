@@ -0,0 +1,200 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package cidr
+
+import (
+	"fmt"
+	"net"
+	"testing"
+)
+
+func mustParse(cidr string) *net.IPNet {
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return subnet
+}
+
+var overlapTestData = []struct {
+	a, b    string
+	overlap bool
+}{
+	{"10.0.0.0/8", "10.1.0.0/16", true},
+	{"10.1.0.0/16", "10.0.0.0/8", true},
+	{"10.0.0.0/8", "11.0.0.0/8", false},
+	{"0.0.0.0/0", "192.168.1.0/24", true},
+	{"192.168.1.0/24", "192.168.1.0/24", true},
+	{"192.168.1.0/25", "192.168.1.128/25", false},
+	{"192.168.1.1/32", "192.168.1.0/24", true},
+	{"fd00::/8", "fd00:1::/64", true},
+	{"fd00::/8", "fe00::/8", false},
+	{"::/0", "fd00:1::/64", true},
+	{"0.0.0.0/0", "fd00:1::/64", false},
+	{"::/0", "10.0.0.0/8", false},
+}
+
+func TestOverlap(t *testing.T) {
+	for _, td := range overlapTestData {
+		if res := Overlap(mustParse(td.a), mustParse(td.b)); res != td.overlap {
+			t.Errorf("Overlap of %s and %s must be %t", td.a, td.b, td.overlap)
+		}
+	}
+}
+
+func TestSetOverlaps(t *testing.T) {
+	for _, td := range overlapTestData {
+		if res := NewSet(mustParse(td.a)).Overlaps(mustParse(td.b)); res != td.overlap {
+			t.Errorf("Overlap of set %s and %s must be %t", td.a, td.b, td.overlap)
+		}
+	}
+}
+
+func TestSetOverlapping(t *testing.T) {
+	set := NewSet(mustParse("10.0.0.0/8"), mustParse("172.16.0.0/16"), mustParse("172.16.10.0/24"), mustParse("fd00::/8"))
+	var testData = []struct {
+		subnet   string
+		expected string
+	}{
+		{"10.10.10.0/24", "10.0.0.0/8"},
+		{"172.0.0.0/8", "172.16.0.0/16"},
+		{"172.16.10.128/25", "172.16.0.0/16"},
+		{"172.16.10.0/24", "172.16.0.0/16"},
+		{"0.0.0.0/0", "10.0.0.0/8"},
+		{"fd00:1::/64", "fd00::/8"},
+		{"192.168.0.0/16", ""},
+		{"fe00::/8", ""},
+	}
+	for _, td := range testData {
+		res := set.Overlapping(mustParse(td.subnet))
+		if (td.expected == "" && res != nil) || (td.expected != "" && (res == nil || res.String() != td.expected)) {
+			t.Errorf("Overlapping subnet of %s must be '%s': %v", td.subnet, td.expected, res)
+		}
+	}
+}
+
+func TestSetContaining(t *testing.T) {
+	set := NewSet(mustParse("10.0.0.0/8"), mustParse("192.168.1.0/24"), mustParse("fd00::/8"))
+	var testData = []struct {
+		subnet   string
+		expected string
+	}{
+		{"10.10.10.0/24", "10.0.0.0/8"},
+		{"10.0.0.0/8", "10.0.0.0/8"},
+		{"192.168.1.128/25", "192.168.1.0/24"},
+		{"192.168.0.0/16", ""},
+		{"0.0.0.0/0", ""},
+		{"fd00:1::/64", "fd00::/8"},
+		{"::/0", ""},
+	}
+	for _, td := range testData {
+		res := set.Containing(mustParse(td.subnet))
+		if (td.expected == "" && res != nil) || (td.expected != "" && (res == nil || res.String() != td.expected)) {
+			t.Errorf("Containing subnet of %s must be '%s': %v", td.subnet, td.expected, res)
+		}
+	}
+}
+
+func TestSetEmpty(t *testing.T) {
+	var nilSet *Set
+	if nilSet.Overlaps(mustParse("0.0.0.0/0")) || NewSet().Overlaps(mustParse("0.0.0.0/0")) || nilSet.Containing(mustParse("10.0.0.0/8")) != nil {
+		t.Error("Empty set must not overlap")
+	}
+	if len(nilSet.Subnets()) != 0 {
+		t.Error("Nil set must not have subnets")
+	}
+}
+
+func TestSetIgnoresInvalid(t *testing.T) {
+	invalid := &net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.IPMask{0xff, 0, 0xff, 0}}
+	set := NewSet(nil, invalid, mustParse("10.0.0.0/8"))
+
+	if len(set.Subnets()) != 1 {
+		t.Errorf("Invalid subnets must be ignored: %v", set.Subnets())
+	}
+	if set.Overlaps(invalid) || set.Overlaps(nil) {
+		t.Error("Invalid subnets must not overlap")
+	}
+}
+
+func TestSetNonCanonicalAddress(t *testing.T) {
+	set := NewSet(&net.IPNet{IP: net.IP{192, 168, 0, 1}, Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0)})
+
+	if !set.Overlaps(&net.IPNet{IP: net.ParseIP("192.168.0.200"), Mask: net.IPv4Mask(0xff, 0xff, 0xff, 0xff)}) {
+		t.Error("Host bits must be masked")
+	}
+}
+
+// protectedSubnets returns n disjoint /24 subnets in 10.0.0.0/8 and the given large ranges
+func protectedSubnets(n int, large ...string) []*net.IPNet {
+	subnets := []*net.IPNet{}
+	for i := 0; i < n; i++ {
+		subnets = append(subnets, mustParse(fmt.Sprintf("10.%d.%d.0/24", i/256%256, i%256)))
+	}
+	for _, l := range large {
+		subnets = append(subnets, mustParse(l))
+	}
+	return subnets
+}
+
+func linearOverlap(protecteds []*net.IPNet, subnet *net.IPNet) bool {
+	for _, protected := range protecteds {
+		if Overlap(protected, subnet) {
+			return true
+		}
+	}
+	return false
+}
+
+func BenchmarkOverlapLargeRanges(b *testing.B) {
+	protecteds := protectedSubnets(0, "0.0.0.0/0", "10.0.0.0/8", "::/0", "fd00::/8")
+	subnet := mustParse("192.168.1.0/24")
+	for i := 0; i < b.N; i++ {
+		linearOverlap(protecteds, subnet)
+	}
+}
+
+func BenchmarkSetOverlapsLargeRanges(b *testing.B) {
+	set := NewSet(protectedSubnets(0, "0.0.0.0/0", "10.0.0.0/8", "::/0", "fd00::/8")...)
+	subnet := mustParse("192.168.1.0/24")
+	for i := 0; i < b.N; i++ {
+		set.Overlaps(subnet)
+	}
+}
+
+func BenchmarkOverlapManySubnets(b *testing.B) {
+	protecteds := protectedSubnets(10000, "172.16.0.0/12")
+	subnet := mustParse("192.168.1.0/24")
+	for i := 0; i < b.N; i++ {
+		linearOverlap(protecteds, subnet)
+	}
+}
+
+func BenchmarkSetOverlapsManySubnets(b *testing.B) {
+	set := NewSet(protectedSubnets(10000, "172.16.0.0/12")...)
+	subnet := mustParse("192.168.1.0/24")
+	for i := 0; i < b.N; i++ {
+		set.Overlaps(subnet)
+	}
+}
+
+func BenchmarkNewSet(b *testing.B) {
+	protecteds := protectedSubnets(10000, "172.16.0.0/12", "fd00::/8")
+	for i := 0; i < b.N; i++ {
+		NewSet(protecteds...)
+	}
+}
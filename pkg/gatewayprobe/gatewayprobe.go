@@ -0,0 +1,183 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// Package gatewayprobe checks whether a gateway is alive, by its neighbor state, by ICMP echo or by TCP connect.
+package gatewayprobe
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBM/staticroute-operator/pkg/routemanager"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// Probe methods, they match the probe types of the StaticRoute API
+const (
+	Neighbor = "neighbor"
+	ICMP     = "icmp"
+	TCP      = "tcp"
+)
+
+// discardPort is the destination of the datagram which triggers the neighbor resolution
+const discardPort = 9
+
+// neighborPollInterval is the frequency of reading the neighbor table while the resolution is in progress
+const neighborPollInterval = 50 * time.Millisecond
+
+var echoSeq atomic.Uint32
+
+// Prober probes the gateways from the network namespace of its Netlink, where the routes through them are installed
+type Prober struct {
+	nl routemanager.Netlink
+}
+
+// NewProber creates a Prober which opens its sockets and reads the neighbor table through the given Netlink
+func NewProber(nl routemanager.Netlink) *Prober {
+	return &Prober{nl: nl}
+}
+
+// Probe checks the gateway with the given method, it returns nil if the gateway is healthy
+func (p *Prober) Probe(method string, gateway net.IP, port int, timeout time.Duration) error {
+	switch method {
+	case Neighbor:
+		return p.probeNeighbor(gateway, timeout)
+	case ICMP:
+		return p.probeICMP(gateway, timeout)
+	case TCP:
+		return p.probeTCP(gateway, port, timeout)
+	}
+	return fmt.Errorf("unknown probe method: %s", method)
+}
+
+// probeTCP opens and closes a TCP connection to the port of the gateway
+func (p *Prober) probeTCP(gateway net.IP, port int, timeout time.Duration) error {
+	// The connection is made in the namespace, as the socket is connected by the dial
+	return p.nl.RunInNamespace(func() error {
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(gateway.String(), strconv.Itoa(port)), timeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+}
+
+// probeICMP sends an echo request to the gateway and waits for the reply. It needs the CAP_NET_RAW capability.
+func (p *Prober) probeICMP(gateway net.IP, timeout time.Duration) error {
+	network, protocol := "ip4:icmp", 1
+	var requestType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	if gateway.To4() == nil {
+		network, protocol = "ip6:ipv6-icmp", 58
+		requestType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+	var conn *icmp.PacketConn
+	err := p.nl.RunInNamespace(func() (err error) {
+		conn, err = icmp.ListenPacket(network, "")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	id, seq := os.Getpid()&0xffff, int(echoSeq.Add(1)&0xffff)
+	request, err := (&icmp.Message{Type: requestType, Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("staticroute-operator")}}).Marshal(nil)
+	if err != nil {
+		return err
+	}
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	if _, err = conn.WriteTo(request, &net.IPAddr{IP: gateway}); err != nil {
+		return err
+	}
+	buffer := make([]byte, 1500)
+	for {
+		// The raw socket receives every ICMP message of the node, the reply is identified by the ID and the sequence
+		n, peer, err := conn.ReadFrom(buffer)
+		if err != nil {
+			return err
+		}
+		if addr, ok := peer.(*net.IPAddr); !ok || !addr.IP.Equal(gateway) {
+			continue
+		}
+		reply, err := icmp.ParseMessage(protocol, buffer[:n])
+		if err != nil || reply.Type != replyType {
+			continue
+		}
+		if echo, ok := reply.Body.(*icmp.Echo); ok && echo.ID == id && echo.Seq == seq {
+			return nil
+		}
+	}
+}
+
+// probeNeighbor sends a datagram to the gateway, so the kernel resolves or confirms its link layer address, then
+// waits until the neighbor entry is valid. Stale entries are valid too, the kernel marks them failed if the gateway
+// does not answer the following solicitations, which is detected by the next probes.
+func (p *Prober) probeNeighbor(gateway net.IP, timeout time.Duration) error {
+	_ = p.nl.RunInNamespace(func() error {
+		conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: gateway, Port: discardPort})
+		if err != nil {
+			return err
+		}
+		_, _ = conn.Write(nil)
+		return conn.Close()
+	})
+	family := netlink.FAMILY_V4
+	if gateway.To4() == nil {
+		family = netlink.FAMILY_V6
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		neighbors, err := p.nl.NeighList(0, family)
+		if err != nil {
+			return err
+		}
+		state := 0
+		for _, neighbor := range neighbors {
+			if neighbor.IP.Equal(gateway) {
+				state = neighbor.State
+				break
+			}
+		}
+		if done, err := neighborResult(state); done {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return errors.New("link layer address of the gateway is not resolved")
+		}
+		time.Sleep(neighborPollInterval)
+	}
+}
+
+// neighborResult evaluates the state of the neighbor entry, it returns false while the resolution is in progress
+func neighborResult(state int) (bool, error) {
+	switch {
+	case state&(netlink.NUD_REACHABLE|netlink.NUD_STALE|netlink.NUD_DELAY|netlink.NUD_PROBE|netlink.NUD_PERMANENT|netlink.NUD_NOARP) != 0:
+		return true, nil
+	case state&netlink.NUD_FAILED != 0:
+		return true, errors.New("link layer address of the gateway can not be resolved")
+	}
+	return false, nil
+}
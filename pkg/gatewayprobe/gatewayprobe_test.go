@@ -0,0 +1,163 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package gatewayprobe
+
+import (
+	"errors"
+	"net"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/IBM/staticroute-operator/pkg/routemanager"
+	"github.com/IBM/staticroute-operator/pkg/routemanager/fake"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+func TestProbeTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+
+	prober := NewProber(routemanager.NewNetlink())
+	if err := prober.Probe(TCP, net.IP{127, 0, 0, 1}, port, time.Second); err != nil {
+		t.Errorf("Listening port must be healthy: %v", err)
+	}
+
+	listener.Close()
+	if err := prober.Probe(TCP, net.IP{127, 0, 0, 1}, port, time.Second); err == nil {
+		t.Error("Closed port must be unhealthy")
+	}
+}
+
+func TestProbeUnknownMethod(t *testing.T) {
+	if err := NewProber(fake.NewNetlink()).Probe("http", net.IP{127, 0, 0, 1}, 80, time.Second); err == nil {
+		t.Error("Unknown method must fail")
+	}
+}
+
+func TestNeighborResult(t *testing.T) {
+	var testData = []struct {
+		state   int
+		done    bool
+		healthy bool
+	}{
+		{netlink.NUD_REACHABLE, true, true},
+		{netlink.NUD_STALE, true, true},
+		{netlink.NUD_DELAY, true, true},
+		{netlink.NUD_PROBE, true, true},
+		{netlink.NUD_PERMANENT, true, true},
+		{netlink.NUD_FAILED, true, false},
+		{netlink.NUD_INCOMPLETE, false, false},
+		{0, false, false},
+	}
+	for _, td := range testData {
+		done, err := neighborResult(td.state)
+		if done != td.done || (done && (err == nil) != td.healthy) {
+			t.Errorf("State %d: expected done %v healthy %v, actual done %v error %v", td.state, td.done, td.healthy, done, err)
+		}
+	}
+}
+
+func TestProbeNeighbor(t *testing.T) {
+	kernel := fake.NewNetlink()
+	for _, neigh := range []netlink.Neigh{
+		{LinkIndex: 1, IP: net.IP{127, 0, 0, 2}, State: netlink.NUD_REACHABLE},
+		{LinkIndex: 1, IP: net.IP{127, 0, 0, 3}, State: netlink.NUD_FAILED},
+	} {
+		if err := kernel.NeighAdd(&neigh); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prober := NewProber(kernel)
+
+	if err := prober.Probe(Neighbor, net.IP{127, 0, 0, 2}, 0, time.Second); err != nil {
+		t.Errorf("Reachable neighbor must be healthy: %v", err)
+	}
+	if err := prober.Probe(Neighbor, net.IP{127, 0, 0, 3}, 0, time.Second); err == nil {
+		t.Error("Failed neighbor must be unhealthy")
+	}
+	if err := prober.Probe(Neighbor, net.IP{127, 0, 0, 4}, 0, 100*time.Millisecond); err == nil {
+		t.Error("Unknown neighbor must be unhealthy after the timeout")
+	}
+	kernel.Fail("NeighList", errors.New("failed"))
+	if err := prober.Probe(Neighbor, net.IP{127, 0, 0, 2}, 0, time.Second); err == nil {
+		t.Error("Neighbor table must be read")
+	}
+}
+
+func TestProbeTCPInNamespace(t *testing.T) {
+	runtime.LockOSThread()
+	origin, err := netns.Get()
+	if err != nil {
+		runtime.UnlockOSThread()
+		t.Skipf("Network namespace of the test is not available: %v", err)
+	}
+	defer origin.Close()
+	// New switches the thread into the new namespace
+	namespace, err := netns.New()
+	if err != nil {
+		runtime.UnlockOSThread()
+		t.Skipf("Network namespace can not be created: %v", err)
+	}
+	defer namespace.Close()
+	// The loopback link of the new namespace is down
+	lo, err := netlink.LinkByName("lo")
+	if err == nil {
+		err = netlink.LinkSetUp(lo)
+	}
+	var listener net.Listener
+	if err == nil {
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err := netns.Set(origin); err != nil {
+		// The thread stays locked, so it is terminated with the goroutine of the test
+		t.Fatalf("Network namespace of the test can not be restored: %v", err)
+	}
+	runtime.UnlockOSThread()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+	nl, err := routemanager.NewNetlinkAt(namespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	if err := NewProber(nl).Probe(TCP, net.IP{127, 0, 0, 1}, port, time.Second); err != nil {
+		t.Errorf("Port listening in the namespace must be healthy: %v", err)
+	}
+	if conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), time.Second); err == nil {
+		conn.Close()
+		t.Error("Port must not be listening in the namespace of the process")
+	}
+}
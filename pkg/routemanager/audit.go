@@ -0,0 +1,160 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package routemanager
+
+import (
+	"bytes"
+	"sort"
+	"syscall"
+
+	"github.com/vishvananda/netlink"
+)
+
+// ip6DefaultPriority is the priority the kernel assigns to the IPv6 routes installed without one
+const ip6DefaultPriority = 1024
+
+// kernelRoute is a route listed from the kernel, foreign routes do not carry our protocol ID
+type kernelRoute struct {
+	route   Route
+	foreign bool
+}
+
+func (r *routeManagerImpl) Audit() ([]Drift, error) {
+	resultChan := make(chan routeManagerImplAuditResult)
+	r.auditChan <- routeManagerImplAuditParams{resultChan}
+	result := <-resultChan
+	return result.drifts, result.err
+}
+
+// auditRoutes lists the tables of the managed routes and compares the managed routes with them one by one.
+// The drifts are repaired according to the policy, counted and reported to the watchers. A shadowing route left
+// in place is reported only by the audit which finds it first, the further audits return it without reporting.
+func (r *routeManagerImpl) auditRoutes() ([]Drift, error) {
+	tables := make(map[int][]kernelRoute)
+	for _, route := range r.managedRoutes {
+		if _, found := tables[route.Table]; found {
+			continue
+		}
+		routes, err := r.listTable(route.Table)
+		if err != nil {
+			return nil, err
+		}
+		tables[route.Table] = routes
+	}
+
+	names := make([]string, 0, len(r.managedRoutes))
+	for name := range r.managedRoutes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	drifts := []Drift{}
+	shadowingRoutes := make(map[string][]Route)
+	for _, name := range names {
+		route := r.managedRoutes[name]
+		for _, drift := range findDrifts(route, tables[route.Table]) {
+			r.repair(&drift)
+			drifts = append(drifts, drift)
+			if drift.Type == DriftShadowed && !drift.Repaired {
+				shadowingRoutes[name] = append(shadowingRoutes[name], *drift.Kernel)
+				if containsRoute(r.shadowingRoutes[name], *drift.Kernel) {
+					continue
+				}
+			}
+			observeDrift(drift)
+			for _, watcher := range r.watchers {
+				if driftWatcher, ok := watcher.(DriftWatcher); ok {
+					driftWatcher.RouteDrifted(name, drift)
+				}
+			}
+		}
+	}
+	r.shadowingRoutes = shadowingRoutes
+	return drifts, nil
+}
+
+// containsRoute returns true if the route is in the list
+func containsRoute(routes []Route, route Route) bool {
+	for _, r := range routes {
+		if r.equal(route) {
+			return true
+		}
+	}
+	return false
+}
+
+// listTable returns the IP routes of both families in the table
+func (r *routeManagerImpl) listTable(table int) ([]kernelRoute, error) {
+	nlRoutes, err := r.nl.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: table}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		return nil, err
+	}
+	routes := []kernelRoute{}
+	for _, nlRoute := range nlRoutes {
+		// Only IP routes have a destination
+		if nlRoute.Dst == nil {
+			continue
+		}
+		routes = append(routes, kernelRoute{route: fromNetLinkRoute(nlRoute), foreign: nlRoute.Protocol != r.protocol})
+	}
+	return routes, nil
+}
+
+// findDrifts compares a managed route with the routes of its table in the kernel
+func findDrifts(managed Route, kernel []kernelRoute) []Drift {
+	expected := managed.InKernel()
+	drifts := []Drift{}
+	found := false
+	for _, k := range kernel {
+		switch {
+		case k.route.conflicts(expected):
+			found = true
+			if k.foreign || !k.route.equal(expected) {
+				drifts = append(drifts, Drift{Type: DriftModified, Route: managed, Kernel: &k.route})
+			}
+		case k.foreign && k.route.Priority < expected.Priority && k.route.Dst.IP.Equal(expected.Dst.IP) && bytes.Equal(k.route.Dst.Mask, expected.Dst.Mask):
+			drifts = append(drifts, Drift{Type: DriftShadowed, Route: managed, Kernel: &k.route})
+		}
+	}
+	if !found {
+		drifts = append(drifts, Drift{Type: DriftMissing, Route: managed})
+	}
+	return drifts
+}
+
+// repair restores the managed route of the drift, or removes the shadowing route, if the policy allows it
+func (r *routeManagerImpl) repair(drift *Drift) {
+	var nlRoute netlink.Route
+	switch {
+	case r.audit.Policy != RepairRestore && r.audit.Policy != RepairEnforce:
+		return
+	case drift.Type == DriftMissing:
+		nlRoute = r.toNetLinkRoute(drift.Route)
+		drift.Err = r.nl.RouteAdd(&nlRoute)
+	case drift.Type == DriftModified:
+		nlRoute = r.toNetLinkRoute(drift.Route)
+		drift.Err = r.nl.RouteReplace(&nlRoute)
+	case drift.Type == DriftShadowed && r.audit.Policy == RepairEnforce:
+		// The protocol of the shadowing route is not known, the kernel deletes the route regardless of it
+		nlRoute = drift.Kernel.toNetLinkRoute()
+		if err := r.nl.RouteDel(&nlRoute); err != nil && syscall.ESRCH.Error() != err.Error() {
+			drift.Err = err
+		}
+	default:
+		return
+	}
+	drift.Repaired = drift.Err == nil
+}
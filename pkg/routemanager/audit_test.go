@@ -0,0 +1,277 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package routemanager
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/IBM/staticroute-operator/pkg/routemanager/fake"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+type MockDriftWatcher struct {
+	MockRouteWatcher
+	routeDriftedCalledWith chan Drift
+}
+
+// RouteDrifted drops the drifts of the further audits, so the event loop is not blocked
+func (m MockDriftWatcher) RouteDrifted(n string, d Drift) {
+	select {
+	case m.routeDriftedCalledWith <- d:
+	default:
+	}
+}
+
+func newAuditedRouteManager(policy RepairPolicy) *testableRouteManager {
+	testable := newTestableRouteManager()
+	testable.rm.(*routeManagerImpl).audit.Policy = policy
+	return &testable
+}
+
+// foreignNetLinkRoute returns the route as the kernel reports it after someone else installed it
+func foreignNetLinkRoute(route Route) netlink.Route {
+	nlRoute := route.toNetLinkRoute()
+	nlRoute.Protocol = unix.RTPROT_BOOT
+	return nlRoute
+}
+
+// auditTampered registers the route, lets tamper change the kernel behind the back of the route manager and
+// audits the routes
+func auditTampered(t *testing.T, testable *testableRouteManager, route Route, tamper func(*fake.Netlink) error) []Drift {
+	testable.start()
+	t.Cleanup(testable.stop)
+	if err := testable.rm.RegisterRoute(gTestRouteName, route); err != nil {
+		t.Fatal("RegisterRoute shall pass here")
+	}
+	if err := tamper(testable.kernel); err != nil {
+		t.Fatal(err)
+	}
+	drifts, err := testable.rm.Audit()
+	if err != nil {
+		t.Fatalf("Audit shall pass here: %s", err.Error())
+	}
+	return drifts
+}
+
+func deleteTestRoute(kernel *fake.Netlink) error {
+	return kernel.RouteDel(&netlink.Route{Dst: &gTestRoute.Dst})
+}
+
+func TestAuditNoDrift(t *testing.T) {
+	other := Route{Dst: net.IPNet{IP: net.IP{192, 168, 2, 0}, Mask: net.CIDRMask(24, 32)}, Gw: net.IP{192, 168, 1, 254}, Table: 254}
+	testable := newAuditedRouteManager(RepairRestore)
+
+	drifts := auditTampered(t, testable, gTestRoute, func(kernel *fake.Netlink) error {
+		nlRoute := foreignNetLinkRoute(other)
+		return kernel.RouteAdd(&nlRoute)
+	})
+
+	if len(drifts) != 0 {
+		t.Errorf("Route in the kernel must not drift: %+v", drifts)
+	}
+}
+
+func TestAuditMissingRestored(t *testing.T) {
+	testable := newAuditedRouteManager(RepairRestore)
+
+	drifts := auditTampered(t, testable, gTestRoute, deleteTestRoute)
+
+	if len(drifts) != 1 || drifts[0].Type != DriftMissing || !drifts[0].Repaired || drifts[0].Kernel != nil {
+		t.Fatalf("Missing route must be reported and repaired: %+v", drifts)
+	}
+	testable.expectKernelRoutes(t, ownNetLinkRoute(gTestRoute))
+}
+
+func TestAuditModifiedReplaced(t *testing.T) {
+	modified := gTestRoute
+	modified.Gw = net.IP{192, 168, 1, 253}
+	testable := newAuditedRouteManager(RepairRestore)
+
+	drifts := auditTampered(t, testable, gTestRoute, func(kernel *fake.Netlink) error {
+		nlRoute := ownNetLinkRoute(modified)
+		return kernel.RouteReplace(&nlRoute)
+	})
+
+	if len(drifts) != 1 || drifts[0].Type != DriftModified || !drifts[0].Repaired || !drifts[0].Kernel.Gw.Equal(modified.Gw) {
+		t.Fatalf("Modified route must be reported and repaired: %+v", drifts)
+	}
+	testable.expectKernelRoutes(t, ownNetLinkRoute(gTestRoute))
+}
+
+func TestAuditForeignIsModified(t *testing.T) {
+	testable := newAuditedRouteManager(RepairNone)
+
+	drifts := auditTampered(t, testable, gTestRoute, func(kernel *fake.Netlink) error {
+		nlRoute := foreignNetLinkRoute(gTestRoute)
+		return kernel.RouteReplace(&nlRoute)
+	})
+
+	if len(drifts) != 1 || drifts[0].Type != DriftModified || drifts[0].Repaired {
+		t.Errorf("Route taken over by someone else must be reported as modified: %+v", drifts)
+	}
+	testable.expectKernelRoutes(t, foreignNetLinkRoute(gTestRoute))
+}
+
+func TestAuditShadowed(t *testing.T) {
+	managed := gTestRoute
+	managed.Priority = 100
+	shadowing := gTestRoute
+	shadowing.Priority = 10
+	var testData = []struct {
+		policy   RepairPolicy
+		repaired bool
+	}{
+		{RepairNone, false},
+		{RepairRestore, false},
+		{RepairEnforce, true},
+	}
+	for _, td := range testData {
+		t.Run(string(td.policy), func(t *testing.T) {
+			testable := newAuditedRouteManager(td.policy)
+
+			drifts := auditTampered(t, testable, managed, func(kernel *fake.Netlink) error {
+				nlRoute := foreignNetLinkRoute(shadowing)
+				return kernel.RouteAdd(&nlRoute)
+			})
+
+			if len(drifts) != 1 || drifts[0].Type != DriftShadowed || drifts[0].Repaired != td.repaired || drifts[0].Kernel.Priority != 10 {
+				t.Fatalf("Shadowing route must be reported: %+v", drifts)
+			}
+			if td.repaired {
+				testable.expectKernelRoutes(t, ownNetLinkRoute(managed))
+			} else {
+				testable.expectKernelRoutes(t, ownNetLinkRoute(managed), foreignNetLinkRoute(shadowing))
+			}
+		})
+	}
+}
+
+func TestAuditShadowedReportedOnce(t *testing.T) {
+	managed := gTestRoute
+	managed.Priority = 100
+	shadowing := gTestRoute
+	shadowing.Priority = 10
+	changed := shadowing
+	changed.Gw = net.IP{192, 168, 1, 253}
+	testable := newAuditedRouteManager(RepairRestore)
+	mockWatcher := MockDriftWatcher{
+		MockRouteWatcher:       MockRouteWatcher{routeDeletedCalledWith: make(chan Route, 1)},
+		routeDriftedCalledWith: make(chan Drift, 3),
+	}
+	drifts := auditTampered(t, testable, managed, func(kernel *fake.Netlink) error {
+		nlRoute := foreignNetLinkRoute(shadowing)
+		return kernel.RouteAdd(&nlRoute)
+	})
+	if len(drifts) != 1 {
+		t.Fatalf("Shadowing route must be found: %+v", drifts)
+	}
+	testable.rm.RegisterWatcher(mockWatcher)
+
+	drifts, err := testable.rm.Audit()
+	if err != nil || len(drifts) != 1 || drifts[0].Type != DriftShadowed {
+		t.Fatalf("Shadowing route must be returned by every audit: %+v %v", drifts, err)
+	}
+	if len(mockWatcher.routeDriftedCalledWith) != 0 {
+		t.Error("Shadowing route already found must not be reported again")
+	}
+
+	nlRoute := foreignNetLinkRoute(changed)
+	if err := testable.kernel.RouteReplace(&nlRoute); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testable.rm.Audit(); err != nil {
+		t.Fatal(err)
+	}
+	if len(mockWatcher.routeDriftedCalledWith) != 1 {
+		t.Fatal("Changed shadowing route must be reported")
+	}
+	if drift := <-mockWatcher.routeDriftedCalledWith; !drift.Kernel.Gw.Equal(changed.Gw) {
+		t.Errorf("Changed shadowing route must be reported: %+v", drift)
+	}
+}
+
+func TestAuditRepairFails(t *testing.T) {
+	testable := newAuditedRouteManager(RepairRestore)
+
+	drifts := auditTampered(t, testable, gTestRoute, func(kernel *fake.Netlink) error {
+		kernel.Fail("RouteAdd", errors.New("bla"))
+		return deleteTestRoute(kernel)
+	})
+
+	if len(drifts) != 1 || drifts[0].Repaired || drifts[0].Err == nil {
+		t.Errorf("Failed repair must be reported: %+v", drifts)
+	}
+	testable.expectKernelRoutes(t)
+}
+
+func TestAuditIPv6DefaultPriority(t *testing.T) {
+	route := Route{Dst: net.IPNet{IP: net.ParseIP("fd00:10::"), Mask: net.CIDRMask(64, 128)}, Gw: net.ParseIP("fd00::1"), Table: 254}
+	testable := newAuditedRouteManager(RepairRestore)
+
+	// The kernel installs the route with the default priority of IPv6
+	drifts := auditTampered(t, testable, route, func(*fake.Netlink) error { return nil })
+
+	if len(drifts) != 0 {
+		t.Errorf("IPv6 route with the default priority of the kernel must not drift: %+v", drifts)
+	}
+}
+
+func TestAuditListFails(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.start()
+	defer testable.stop()
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+
+	testable.kernel.Fail("RouteListFiltered", errors.New("bla"))
+	if _, err := testable.rm.Audit(); err == nil {
+		t.Error("Audit shall fail here")
+	}
+}
+
+func TestAuditPeriodicNotifiesWatchers(t *testing.T) {
+	testable := newAuditedRouteManager(RepairNone)
+	testable.rm.(*routeManagerImpl).audit.Interval = 10 * time.Millisecond
+	mockWatcher := MockDriftWatcher{
+		MockRouteWatcher:       MockRouteWatcher{routeDeletedCalledWith: make(chan Route, 1)},
+		routeDriftedCalledWith: make(chan Drift, 1),
+	}
+	testable.start()
+	defer testable.stop()
+	testable.rm.RegisterWatcher(mockWatcher)
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+	if err := deleteTestRoute(testable.kernel); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case drift := <-mockWatcher.routeDriftedCalledWith:
+		if drift.Type != DriftMissing || !drift.Route.equal(gTestRoute) {
+			t.Errorf("Missing route must be reported: %+v", drift)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Periodic audit must notify the watchers")
+	}
+	testable.rm.DeRegisterWatcher(mockWatcher)
+}
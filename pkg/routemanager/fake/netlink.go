@@ -0,0 +1,786 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+// Package fake provides an in-memory model of the kernel's netlink interface for the tests.
+package fake
+
+import (
+	"bytes"
+	"net"
+	"slices"
+	"sync"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// ip6DefaultPriority is the priority the kernel assigns to the IPv6 routes added without one
+	ip6DefaultPriority = 1024
+	// updateQueueLength is the number of route updates buffered per subscriber, the rest is dropped like ENOBUFS
+	updateQueueLength = 128
+)
+
+/*
+Netlink models the routing tables, the policy routing rules and the links of a network namespace the way the kernel
+handles them:
+  - a route is identified by its family, table, destination, TOS and priority, adding an existing one fails with
+    EEXIST, deleting a missing one with ESRCH
+  - the unset attributes of an added route get the kernel defaults: main table, unicast type, boot protocol and
+    priority 1024 for IPv6
+  - the route changes and the rule deletions are sent to the subscribers asynchronously, like the netlink multicast
+    messages
+  - adding an address adds the route of its prefix, deleting a link removes its addresses and the routes through it
+  - a new namespace holds the default rules and the loopback link
+
+The next call of an operation can be made to fail with Fail.
+*/
+type Netlink struct {
+	mutex       sync.Mutex
+	routes      []netlink.Route
+	rules       []netlink.Rule
+	links       []netlink.Link
+	addrs       []netlink.Addr
+	neighs      []netlink.Neigh
+	subscribers []*subscriber
+	// ruleSubscribers are signalled when a rule is deleted
+	ruleSubscribers []*ruleSubscriber
+	failures        map[string]error
+}
+
+type subscriber struct {
+	updates chan netlink.RouteUpdate
+	closed  chan struct{}
+}
+
+type ruleSubscriber struct {
+	deletions chan struct{}
+	closed    chan struct{}
+}
+
+// NewNetlink returns the model of an empty network namespace
+func NewNetlink() *Netlink {
+	f := &Netlink{failures: make(map[string]error)}
+	f.links = append(f.links, &netlink.Device{LinkAttrs: netlink.LinkAttrs{
+		Index: 1,
+		Name:  "lo",
+		MTU:   65536,
+		Flags: net.FlagUp | net.FlagLoopback,
+	}})
+	for _, family := range []int{netlink.FAMILY_V4, netlink.FAMILY_V6} {
+		f.rules = append(f.rules, defaultRule(family, 0, unix.RT_TABLE_LOCAL), defaultRule(family, 32766, unix.RT_TABLE_MAIN))
+		if family == netlink.FAMILY_V4 {
+			f.rules = append(f.rules, defaultRule(family, 32767, unix.RT_TABLE_DEFAULT))
+		}
+	}
+	return f
+}
+
+func defaultRule(family, priority, table int) netlink.Rule {
+	rule := *netlink.NewRule()
+	rule.Family = family
+	rule.Priority = priority
+	rule.Table = table
+	rule.Protocol = unix.RTPROT_KERNEL
+	return rule
+}
+
+// Fail makes the next call of the operation (i.e. "RouteAdd") return the error without changing anything
+func (f *Netlink) Fail(operation string, err error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.failures[operation] = err
+}
+
+// CloseSubscriptions closes the update channels of the subscribers, like a failing netlink socket does
+func (f *Netlink) CloseSubscriptions() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, s := range f.subscribers {
+		close(s.closed)
+	}
+	for _, s := range f.ruleSubscribers {
+		close(s.closed)
+	}
+	f.subscribers = nil
+	f.ruleSubscribers = nil
+}
+
+// failure returns and clears the error injected for the operation, the caller holds the mutex
+func (f *Netlink) failure(operation string) error {
+	err := f.failures[operation]
+	delete(f.failures, operation)
+	return err
+}
+
+func (f *Netlink) RouteAdd(route *netlink.Route) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("RouteAdd"); err != nil {
+		return err
+	}
+	nlRoute, err := f.normalizeRoute(route)
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(f.routes, func(r netlink.Route) bool { return sameRoute(r, nlRoute) }) {
+		return unix.EEXIST
+	}
+	f.routes = append(f.routes, nlRoute)
+	f.notify(unix.RTM_NEWROUTE, nlRoute)
+	return nil
+}
+
+func (f *Netlink) RouteReplace(route *netlink.Route) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("RouteReplace"); err != nil {
+		return err
+	}
+	nlRoute, err := f.normalizeRoute(route)
+	if err != nil {
+		return err
+	}
+	if index := slices.IndexFunc(f.routes, func(r netlink.Route) bool { return sameRoute(r, nlRoute) }); index >= 0 {
+		f.routes[index] = nlRoute
+	} else {
+		f.routes = append(f.routes, nlRoute)
+	}
+	f.notify(unix.RTM_NEWROUTE, nlRoute)
+	return nil
+}
+
+func (f *Netlink) RouteDel(route *netlink.Route) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("RouteDel"); err != nil {
+		return err
+	}
+	// The kernel deletes the first route matching the attributes set in the request
+	index := slices.IndexFunc(f.routes, func(r netlink.Route) bool { return deleteMatches(route, r) })
+	if index < 0 {
+		return unix.ESRCH
+	}
+	deleted := f.routes[index]
+	f.routes = slices.Delete(f.routes, index, index+1)
+	f.notify(unix.RTM_DELROUTE, deleted)
+	return nil
+}
+
+// RouteListFiltered lists only the main table unless the table is filtered, like the netlink package does
+func (f *Netlink) RouteListFiltered(family int, filter *netlink.Route, filterMask uint64) ([]netlink.Route, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("RouteListFiltered"); err != nil {
+		return nil, err
+	}
+	routes := []netlink.Route{}
+	for _, route := range f.routes {
+		if family != netlink.FAMILY_ALL && route.Family != family {
+			continue
+		}
+		if route.Table != unix.RT_TABLE_MAIN && (filter == nil || filterMask&netlink.RT_FILTER_TABLE == 0) {
+			continue
+		}
+		if filter != nil && !listMatches(filter, filterMask, route) {
+			continue
+		}
+		routes = append(routes, copyRoute(route))
+	}
+	return routes, nil
+}
+
+// RouteGet looks up the route to the destination in the main table, the most specific one wins
+func (f *Netlink) RouteGet(destination net.IP) ([]netlink.Route, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("RouteGet"); err != nil {
+		return nil, err
+	}
+	var best *netlink.Route
+	for i := range f.routes {
+		route := &f.routes[i]
+		if route.Table != unix.RT_TABLE_MAIN || !route.Dst.Contains(destination) {
+			continue
+		}
+		if best == nil || prefixLength(route) > prefixLength(best) ||
+			(prefixLength(route) == prefixLength(best) && route.Priority < best.Priority) {
+			best = route
+		}
+	}
+	if best == nil {
+		return nil, unix.ENETUNREACH
+	}
+	switch best.Type {
+	case unix.RTN_BLACKHOLE:
+		return nil, unix.EINVAL
+	case unix.RTN_UNREACHABLE:
+		return nil, unix.EHOSTUNREACH
+	case unix.RTN_PROHIBIT:
+		return nil, unix.EACCES
+	}
+	return []netlink.Route{{
+		Dst:       prefix(destination, -1, best.Family),
+		Gw:        best.Gw,
+		LinkIndex: best.LinkIndex,
+		Src:       best.Src,
+		Family:    best.Family,
+		Table:     unix.RT_TABLE_MAIN,
+		Type:      unix.RTN_UNICAST,
+	}}, nil
+}
+
+// RouteSubscribe sends the route changes to the channel until the done channel is closed, then closes the channel
+func (f *Netlink) RouteSubscribe(updateChan chan<- netlink.RouteUpdate, doneChan <-chan struct{}) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("RouteSubscribe"); err != nil {
+		return err
+	}
+	s := &subscriber{updates: make(chan netlink.RouteUpdate, updateQueueLength), closed: make(chan struct{})}
+	f.subscribers = append(f.subscribers, s)
+	go func() {
+		defer close(updateChan)
+		defer f.unsubscribe(s)
+		for {
+			select {
+			case update := <-s.updates:
+				select {
+				case updateChan <- update:
+				case <-doneChan:
+					return
+				case <-s.closed:
+					return
+				}
+			case <-doneChan:
+				return
+			case <-s.closed:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (f *Netlink) unsubscribe(s *subscriber) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.subscribers = slices.DeleteFunc(f.subscribers, func(item *subscriber) bool { return item == s })
+}
+
+// notify queues the route change for the subscribers, the caller holds the mutex
+func (f *Netlink) notify(updateType uint16, route netlink.Route) {
+	for _, s := range f.subscribers {
+		select {
+		case s.updates <- netlink.RouteUpdate{Type: updateType, Route: copyRoute(route)}:
+		default:
+		}
+	}
+}
+
+func (f *Netlink) RuleAdd(rule *netlink.Rule) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("RuleAdd"); err != nil {
+		return err
+	}
+	nlRule := *rule
+	if nlRule.Family == 0 {
+		nlRule.Family = ruleFamily(rule)
+	}
+	if nlRule.Priority < 0 {
+		nlRule.Priority = f.defaultRulePriority(nlRule.Family)
+	}
+	if nlRule.Mark != 0 && nlRule.Mask == nil {
+		// The kernel compares the whole mark if no mask is given
+		allBits := ^uint32(0)
+		nlRule.Mask = &allBits
+	}
+	if slices.ContainsFunc(f.rules, func(r netlink.Rule) bool { return sameRule(r, nlRule) }) {
+		return unix.EEXIST
+	}
+	// The rules are evaluated in the order of their priority, the new one goes after the ones with the same priority
+	index := slices.IndexFunc(f.rules, func(r netlink.Rule) bool { return r.Priority > nlRule.Priority })
+	if index < 0 {
+		index = len(f.rules)
+	}
+	f.rules = slices.Insert(f.rules, index, nlRule)
+	return nil
+}
+
+// defaultRulePriority returns the priority the kernel assigns to a rule added without one: the one before the
+// first rule with a non-zero priority
+func (f *Netlink) defaultRulePriority(family int) int {
+	for _, rule := range f.rules {
+		if rule.Family == family && rule.Priority > 0 {
+			return rule.Priority - 1
+		}
+	}
+	return 0
+}
+
+func (f *Netlink) RuleDel(rule *netlink.Rule) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("RuleDel"); err != nil {
+		return err
+	}
+	index := slices.IndexFunc(f.rules, func(r netlink.Rule) bool { return ruleDeleteMatches(rule, r) })
+	if index < 0 {
+		return unix.ENOENT
+	}
+	f.rules = slices.Delete(f.rules, index, index+1)
+	for _, s := range f.ruleSubscribers {
+		select {
+		case s.deletions <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// RuleSubscribe signals on the channel whenever a rule is deleted until the done channel is closed, then closes the channel
+func (f *Netlink) RuleSubscribe(updateChan chan<- struct{}, doneChan <-chan struct{}) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("RuleSubscribe"); err != nil {
+		return err
+	}
+	s := &ruleSubscriber{deletions: make(chan struct{}, updateQueueLength), closed: make(chan struct{})}
+	f.ruleSubscribers = append(f.ruleSubscribers, s)
+	go func() {
+		defer close(updateChan)
+		defer f.unsubscribeRules(s)
+		for {
+			select {
+			case <-s.deletions:
+				select {
+				case updateChan <- struct{}{}:
+				case <-doneChan:
+					return
+				case <-s.closed:
+					return
+				}
+			case <-doneChan:
+				return
+			case <-s.closed:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (f *Netlink) unsubscribeRules(s *ruleSubscriber) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.ruleSubscribers = slices.DeleteFunc(f.ruleSubscribers, func(item *ruleSubscriber) bool { return item == s })
+}
+
+func (f *Netlink) RuleList(family int) ([]netlink.Rule, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("RuleList"); err != nil {
+		return nil, err
+	}
+	rules := []netlink.Rule{}
+	for _, rule := range f.rules {
+		if family == netlink.FAMILY_ALL || rule.Family == family {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// LinkAdd adds the link, it gets the next free index if it has none
+func (f *Netlink) LinkAdd(link netlink.Link) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("LinkAdd"); err != nil {
+		return err
+	}
+	attrs := link.Attrs()
+	if attrs == nil || attrs.Name == "" {
+		return unix.EINVAL
+	}
+	for _, existing := range f.links {
+		if existing.Attrs().Name == attrs.Name {
+			return unix.EEXIST
+		}
+		if existing.Attrs().Index == attrs.Index {
+			return unix.EBUSY
+		}
+	}
+	if attrs.Index == 0 {
+		for _, existing := range f.links {
+			attrs.Index = max(attrs.Index, existing.Attrs().Index)
+		}
+		attrs.Index++
+	}
+	f.links = append(f.links, link)
+	return nil
+}
+
+// LinkDel deletes the link given by its index or name together with its routes
+func (f *Netlink) LinkDel(link netlink.Link) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("LinkDel"); err != nil {
+		return err
+	}
+	index := slices.IndexFunc(f.links, func(l netlink.Link) bool {
+		if link.Attrs().Index != 0 {
+			return l.Attrs().Index == link.Attrs().Index
+		}
+		return l.Attrs().Name == link.Attrs().Name
+	})
+	if index < 0 {
+		return unix.ENODEV
+	}
+	linkIndex := f.links[index].Attrs().Index
+	f.links = slices.Delete(f.links, index, index+1)
+	f.addrs = slices.DeleteFunc(f.addrs, func(a netlink.Addr) bool { return a.LinkIndex == linkIndex })
+	f.routes = slices.DeleteFunc(f.routes, func(r netlink.Route) bool {
+		if r.LinkIndex != linkIndex {
+			return false
+		}
+		f.notify(unix.RTM_DELROUTE, r)
+		return true
+	})
+	return nil
+}
+
+func (f *Netlink) LinkByName(name string) (netlink.Link, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("LinkByName"); err != nil {
+		return nil, err
+	}
+	for _, link := range f.links {
+		if link.Attrs().Name == name {
+			return link, nil
+		}
+	}
+	return nil, unix.ENODEV
+}
+
+func (f *Netlink) LinkByIndex(index int) (netlink.Link, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("LinkByIndex"); err != nil {
+		return nil, err
+	}
+	for _, link := range f.links {
+		if link.Attrs().Index == index {
+			return link, nil
+		}
+	}
+	return nil, unix.ENODEV
+}
+
+func (f *Netlink) LinkList() ([]netlink.Link, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("LinkList"); err != nil {
+		return nil, err
+	}
+	return slices.Clone(f.links), nil
+}
+
+// AddrAdd adds the address to the link together with the route of its prefix, like the kernel does
+func (f *Netlink) AddrAdd(link netlink.Link, addr *netlink.Addr) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("AddrAdd"); err != nil {
+		return err
+	}
+	if addr.IPNet == nil {
+		return unix.EINVAL
+	}
+	linkIndex := link.Attrs().Index
+	if !slices.ContainsFunc(f.links, func(l netlink.Link) bool { return l.Attrs().Index == linkIndex }) {
+		return unix.ENODEV
+	}
+	if slices.ContainsFunc(f.addrs, func(a netlink.Addr) bool { return a.LinkIndex == linkIndex && a.IP.Equal(addr.IP) }) {
+		return unix.EEXIST
+	}
+	nlAddr := *addr
+	nlAddr.IPNet = &net.IPNet{IP: addr.IP, Mask: addr.Mask}
+	nlAddr.LinkIndex = linkIndex
+	f.addrs = append(f.addrs, nlAddr)
+	family := ipFamily(addr.IP)
+	ones, bits := addr.Mask.Size()
+	if ones == bits {
+		return nil
+	}
+	route := netlink.Route{
+		Dst:       prefix(addr.IP.Mask(addr.Mask), ones, family),
+		Src:       addr.IP,
+		LinkIndex: linkIndex,
+		Scope:     netlink.SCOPE_LINK,
+		Protocol:  unix.RTPROT_KERNEL,
+		Family:    family,
+	}
+	if family == netlink.FAMILY_V6 {
+		route.Src, route.Scope = nil, netlink.SCOPE_UNIVERSE
+	}
+	route, err := f.normalizeRoute(&route)
+	if err != nil {
+		return err
+	}
+	f.routes = append(f.routes, route)
+	f.notify(unix.RTM_NEWROUTE, route)
+	return nil
+}
+
+// AddrList lists the addresses of the link, or of all links if it is nil
+func (f *Netlink) AddrList(link netlink.Link, family int) ([]netlink.Addr, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("AddrList"); err != nil {
+		return nil, err
+	}
+	addrs := []netlink.Addr{}
+	for _, addr := range f.addrs {
+		if link != nil && addr.LinkIndex != link.Attrs().Index {
+			continue
+		}
+		if family != netlink.FAMILY_ALL && ipFamily(addr.IP) != family {
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+// NeighAdd adds the neighbor entry, like the kernel does when it resolves the link layer address of a neighbor
+func (f *Netlink) NeighAdd(neigh *netlink.Neigh) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("NeighAdd"); err != nil {
+		return err
+	}
+	if slices.ContainsFunc(f.neighs, func(n netlink.Neigh) bool { return n.LinkIndex == neigh.LinkIndex && n.IP.Equal(neigh.IP) }) {
+		return unix.EEXIST
+	}
+	nlNeigh := *neigh
+	nlNeigh.Family = ipFamily(neigh.IP)
+	f.neighs = append(f.neighs, nlNeigh)
+	return nil
+}
+
+// NeighList lists the neighbor entries of the link, or of all links if the index is 0
+func (f *Netlink) NeighList(linkIndex, family int) ([]netlink.Neigh, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.failure("NeighList"); err != nil {
+		return nil, err
+	}
+	neighs := []netlink.Neigh{}
+	for _, neigh := range f.neighs {
+		if (linkIndex == 0 || neigh.LinkIndex == linkIndex) && (family == netlink.FAMILY_ALL || neigh.Family == family) {
+			neighs = append(neighs, neigh)
+		}
+	}
+	return neighs, nil
+}
+
+// RunInNamespace calls the function, the sockets of the fake kernel are the ones of the process
+func (f *Netlink) RunInNamespace(fn func() error) error {
+	f.mutex.Lock()
+	err := f.failure("RunInNamespace")
+	f.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return fn()
+}
+
+// normalizeRoute validates the route and fills the attributes the kernel sets by default, the caller holds the mutex
+func (f *Netlink) normalizeRoute(route *netlink.Route) (netlink.Route, error) {
+	nlRoute := copyRoute(*route)
+	if nlRoute.Family == 0 {
+		switch {
+		case nlRoute.Dst != nil:
+			nlRoute.Family = ipFamily(nlRoute.Dst.IP)
+		case nlRoute.Gw != nil:
+			nlRoute.Family = ipFamily(nlRoute.Gw)
+		default:
+			return nlRoute, unix.EINVAL
+		}
+	}
+	if nlRoute.Dst == nil {
+		nlRoute.Dst = prefix(nil, 0, nlRoute.Family)
+	}
+	if nlRoute.LinkIndex != 0 && !slices.ContainsFunc(f.links, func(l netlink.Link) bool { return l.Attrs().Index == nlRoute.LinkIndex }) {
+		return nlRoute, unix.ENODEV
+	}
+	if nlRoute.Table == unix.RT_TABLE_UNSPEC {
+		nlRoute.Table = unix.RT_TABLE_MAIN
+	}
+	if nlRoute.Type == 0 {
+		nlRoute.Type = unix.RTN_UNICAST
+	}
+	if nlRoute.Protocol == unix.RTPROT_UNSPEC {
+		nlRoute.Protocol = unix.RTPROT_BOOT
+	}
+	if nlRoute.Family == netlink.FAMILY_V6 && nlRoute.Priority == 0 {
+		nlRoute.Priority = ip6DefaultPriority
+	}
+	return nlRoute, nil
+}
+
+// sameRoute returns true if the kernel identifies both routes as the same one
+func sameRoute(a, b netlink.Route) bool {
+	return a.Family == b.Family && a.Table == b.Table && a.Priority == b.Priority && a.Tos == b.Tos && ipNetEqual(a.Dst, b.Dst)
+}
+
+// deleteMatches returns true if the route matches the attributes set in the delete request
+func deleteMatches(request *netlink.Route, route netlink.Route) bool {
+	table := request.Table
+	if table == unix.RT_TABLE_UNSPEC {
+		table = unix.RT_TABLE_MAIN
+	}
+	dst := request.Dst
+	if dst == nil {
+		// The zero prefix of the family, the one of the route is used if the family is not known either
+		family := request.Family
+		if family == 0 {
+			family = route.Family
+		}
+		dst = prefix(nil, 0, family)
+	}
+	return route.Table == table && route.Family == ipFamily(dst.IP) && ipNetEqual(route.Dst, dst) &&
+		route.Tos == request.Tos &&
+		(request.Priority == 0 || route.Priority == request.Priority) &&
+		(request.Protocol == unix.RTPROT_UNSPEC || route.Protocol == request.Protocol) &&
+		(request.Type == 0 || route.Type == request.Type) &&
+		(request.Gw == nil || route.Gw.Equal(request.Gw)) &&
+		(request.LinkIndex == 0 || route.LinkIndex == request.LinkIndex)
+}
+
+// listMatches applies the filter the way the netlink package does
+func listMatches(filter *netlink.Route, filterMask uint64, route netlink.Route) bool {
+	switch {
+	case filterMask&netlink.RT_FILTER_TABLE != 0 && filter.Table != unix.RT_TABLE_UNSPEC && route.Table != filter.Table:
+		return false
+	case filterMask&netlink.RT_FILTER_PROTOCOL != 0 && route.Protocol != filter.Protocol:
+		return false
+	case filterMask&netlink.RT_FILTER_SCOPE != 0 && route.Scope != filter.Scope:
+		return false
+	case filterMask&netlink.RT_FILTER_TYPE != 0 && route.Type != filter.Type:
+		return false
+	case filterMask&netlink.RT_FILTER_TOS != 0 && route.Tos != filter.Tos:
+		return false
+	case filterMask&netlink.RT_FILTER_OIF != 0 && route.LinkIndex != filter.LinkIndex:
+		return false
+	case filterMask&netlink.RT_FILTER_GW != 0 && !route.Gw.Equal(filter.Gw):
+		return false
+	case filterMask&netlink.RT_FILTER_SRC != 0 && !route.Src.Equal(filter.Src):
+		return false
+	case filterMask&netlink.RT_FILTER_DST != 0 && filter.Dst != nil && !ipNetEqual(route.Dst, filter.Dst):
+		return false
+	case filterMask&netlink.RT_FILTER_PRIORITY != 0 && route.Priority != filter.Priority:
+		return false
+	}
+	return true
+}
+
+// sameRule returns true if the kernel treats the rules as duplicates
+func sameRule(a, b netlink.Rule) bool {
+	return a.Family == b.Family && a.Priority == b.Priority && a.Table == b.Table && a.Mark == b.Mark &&
+		maskEqual(a.Mask, b.Mask) && a.Tos == b.Tos && a.Invert == b.Invert && a.IifName == b.IifName &&
+		a.OifName == b.OifName && ipNetEqual(a.Src, b.Src) && ipNetEqual(a.Dst, b.Dst)
+}
+
+// ruleDeleteMatches returns true if the rule matches the attributes set in the delete request
+func ruleDeleteMatches(request *netlink.Rule, rule netlink.Rule) bool {
+	family := request.Family
+	if family == 0 {
+		family = ruleFamily(request)
+	}
+	return rule.Family == family &&
+		(request.Priority < 0 || rule.Priority == request.Priority) &&
+		(request.Table <= 0 || rule.Table == request.Table) &&
+		(request.Mark == 0 || rule.Mark == request.Mark) &&
+		(request.Mask == nil || maskEqual(rule.Mask, request.Mask)) &&
+		(request.Src == nil || ipNetEqual(rule.Src, request.Src)) &&
+		(request.Dst == nil || ipNetEqual(rule.Dst, request.Dst)) &&
+		(request.IifName == "" || rule.IifName == request.IifName) &&
+		(request.OifName == "" || rule.OifName == request.OifName) &&
+		(request.Protocol == 0 || rule.Protocol == request.Protocol)
+}
+
+// ruleFamily returns the family of the selectors of the rule, IPv4 if it has none
+func ruleFamily(rule *netlink.Rule) int {
+	for _, subnet := range []*net.IPNet{rule.Src, rule.Dst} {
+		if subnet != nil {
+			return ipFamily(subnet.IP)
+		}
+	}
+	return netlink.FAMILY_V4
+}
+
+// prefix returns the prefix of the address in the family, the host prefix if the length is negative and the zero
+// prefix if the address is nil
+func prefix(ip net.IP, length int, family int) *net.IPNet {
+	bits := 8 * net.IPv6len
+	if family == netlink.FAMILY_V4 {
+		bits = 8 * net.IPv4len
+	}
+	if ip == nil {
+		ip = make(net.IP, bits/8)
+	}
+	if length < 0 {
+		length = bits
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(length, bits)}
+}
+
+func prefixLength(route *netlink.Route) int {
+	ones, _ := route.Dst.Mask.Size()
+	return ones
+}
+
+func ipNetEqual(a, b *net.IPNet) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.IP.Equal(b.IP) && bytes.Equal(a.Mask, b.Mask)
+}
+
+func maskEqual(a, b *uint32) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// copyRoute returns a copy of the route which does not share the destination with the original
+func copyRoute(route netlink.Route) netlink.Route {
+	if route.Dst != nil {
+		dst := *route.Dst
+		route.Dst = &dst
+	}
+	return route
+}
+
+func ipFamily(ip net.IP) int {
+	if ip.To4() == nil {
+		return netlink.FAMILY_V6
+	}
+	return netlink.FAMILY_V4
+}
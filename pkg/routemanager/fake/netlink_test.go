@@ -0,0 +1,483 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package fake
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+func mustParseCIDR(t *testing.T, cidr string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ipNet
+}
+
+func listAll(t *testing.T, f *Netlink) []netlink.Route {
+	routes, err := f.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: unix.RT_TABLE_UNSPEC}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return routes
+}
+
+func receiveUpdate(t *testing.T, updates <-chan netlink.RouteUpdate) netlink.RouteUpdate {
+	select {
+	case update := <-updates:
+		return update
+	case <-time.After(time.Second):
+		t.Fatal("Route update was not sent")
+	}
+	return netlink.RouteUpdate{}
+}
+
+func TestRouteAddSetsKernelDefaults(t *testing.T) {
+	f := NewNetlink()
+	if err := f.RouteAdd(&netlink.Route{Dst: mustParseCIDR(t, "10.0.0.0/8"), Gw: net.IP{192, 168, 0, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.RouteAdd(&netlink.Route{Dst: mustParseCIDR(t, "fd00::/64"), Gw: net.ParseIP("fe80::1")}); err != nil {
+		t.Fatal(err)
+	}
+	routes := listAll(t, f)
+	if len(routes) != 2 {
+		t.Fatalf("Expected 2 routes, got %v", routes)
+	}
+	for _, route := range routes {
+		if route.Table != unix.RT_TABLE_MAIN || route.Type != unix.RTN_UNICAST || route.Protocol != unix.RTPROT_BOOT {
+			t.Errorf("Kernel defaults are not set: %v", route)
+		}
+	}
+	if routes[0].Family != netlink.FAMILY_V4 || routes[0].Priority != 0 {
+		t.Errorf("IPv4 route is not stored as added: %v", routes[0])
+	}
+	if routes[1].Family != netlink.FAMILY_V6 || routes[1].Priority != ip6DefaultPriority {
+		t.Errorf("IPv6 route does not get the default priority: %v", routes[1])
+	}
+}
+
+func TestRouteAddExisting(t *testing.T) {
+	f := NewNetlink()
+	route := netlink.Route{Dst: mustParseCIDR(t, "10.0.0.0/8"), Gw: net.IP{192, 168, 0, 1}, Table: 42}
+	if err := f.RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+	other := route
+	other.Gw = net.IP{192, 168, 0, 2}
+	if err := f.RouteAdd(&other); !errors.Is(err, unix.EEXIST) {
+		t.Errorf("EEXIST is expected for the same destination, table and priority, got %v", err)
+	}
+	other.Priority = 10
+	if err := f.RouteAdd(&other); err != nil {
+		t.Errorf("Route with another priority must be added: %v", err)
+	}
+	other.Priority, other.Table = 0, 43
+	if err := f.RouteAdd(&other); err != nil {
+		t.Errorf("Route in another table must be added: %v", err)
+	}
+	if routes := listAll(t, f); len(routes) != 3 {
+		t.Errorf("Expected 3 routes, got %v", routes)
+	}
+}
+
+func TestRouteAddUnknownLink(t *testing.T) {
+	f := NewNetlink()
+	if err := f.RouteAdd(&netlink.Route{Dst: mustParseCIDR(t, "10.0.0.0/8"), LinkIndex: 42}); !errors.Is(err, unix.ENODEV) {
+		t.Errorf("ENODEV is expected, got %v", err)
+	}
+}
+
+func TestRouteReplace(t *testing.T) {
+	f := NewNetlink()
+	route := netlink.Route{Dst: mustParseCIDR(t, "10.0.0.0/8"), Gw: net.IP{192, 168, 0, 1}}
+	if err := f.RouteReplace(&route); err != nil {
+		t.Fatal(err)
+	}
+	route.Gw = net.IP{192, 168, 0, 2}
+	if err := f.RouteReplace(&route); err != nil {
+		t.Fatal(err)
+	}
+	routes := listAll(t, f)
+	if len(routes) != 1 || !routes[0].Gw.Equal(route.Gw) {
+		t.Errorf("Route is not replaced in place: %v", routes)
+	}
+}
+
+func TestRouteDel(t *testing.T) {
+	route := netlink.Route{Dst: mustParseCIDR(t, "10.0.0.0/8"), Gw: net.IP{192, 168, 0, 1}, Protocol: 196, Priority: 10}
+	testCases := []struct {
+		name    string
+		request netlink.Route
+		err     error
+	}{
+		{"only destination", netlink.Route{Dst: mustParseCIDR(t, "10.0.0.0/8")}, nil},
+		{"all attributes", route, nil},
+		{"other gateway", netlink.Route{Dst: route.Dst, Gw: net.IP{192, 168, 0, 2}}, unix.ESRCH},
+		{"other protocol", netlink.Route{Dst: route.Dst, Protocol: unix.RTPROT_STATIC}, unix.ESRCH},
+		{"other priority", netlink.Route{Dst: route.Dst, Priority: 20}, unix.ESRCH},
+		{"other table", netlink.Route{Dst: route.Dst, Table: 42}, unix.ESRCH},
+		{"other destination", netlink.Route{Dst: mustParseCIDR(t, "10.0.0.0/16")}, unix.ESRCH},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := NewNetlink()
+			if err := f.RouteAdd(&route); err != nil {
+				t.Fatal(err)
+			}
+			if err := f.RouteDel(&tc.request); !errors.Is(err, tc.err) {
+				t.Errorf("Expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestRouteListFiltered(t *testing.T) {
+	f := NewNetlink()
+	for _, route := range []netlink.Route{
+		{Dst: mustParseCIDR(t, "10.0.0.0/8"), Protocol: 196},
+		{Dst: mustParseCIDR(t, "10.0.0.0/8"), Table: 42, Protocol: 196},
+		{Dst: mustParseCIDR(t, "10.1.0.0/16"), Table: 42},
+		{Dst: mustParseCIDR(t, "fd00::/64"), Table: 42, Protocol: 196},
+	} {
+		if err := f.RouteAdd(&route); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testCases := []struct {
+		name     string
+		family   int
+		filter   *netlink.Route
+		mask     uint64
+		expected int
+	}{
+		{"main table by default", netlink.FAMILY_ALL, nil, 0, 1},
+		{"all tables", netlink.FAMILY_ALL, &netlink.Route{}, netlink.RT_FILTER_TABLE, 4},
+		{"one table", netlink.FAMILY_ALL, &netlink.Route{Table: 42}, netlink.RT_FILTER_TABLE, 3},
+		{"family", netlink.FAMILY_V4, &netlink.Route{Table: 42}, netlink.RT_FILTER_TABLE, 2},
+		{"protocol", netlink.FAMILY_ALL, &netlink.Route{Protocol: 196}, netlink.RT_FILTER_TABLE | netlink.RT_FILTER_PROTOCOL, 3},
+		{"destination", netlink.FAMILY_ALL, &netlink.Route{Dst: mustParseCIDR(t, "10.0.0.0/8")}, netlink.RT_FILTER_TABLE | netlink.RT_FILTER_DST, 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			routes, err := f.RouteListFiltered(tc.family, tc.filter, tc.mask)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(routes) != tc.expected {
+				t.Errorf("Expected %d routes, got %v", tc.expected, routes)
+			}
+		})
+	}
+}
+
+func TestRouteListReturnsCopies(t *testing.T) {
+	f := NewNetlink()
+	if err := f.RouteAdd(&netlink.Route{Dst: mustParseCIDR(t, "10.0.0.0/8")}); err != nil {
+		t.Fatal(err)
+	}
+	listAll(t, f)[0].Dst.Mask = net.CIDRMask(16, 32)
+	if ones, _ := listAll(t, f)[0].Dst.Mask.Size(); ones != 8 {
+		t.Error("Route of the kernel must not be changed through the listed copy")
+	}
+}
+
+func TestRouteGet(t *testing.T) {
+	f := NewNetlink()
+	if err := f.LinkAdd(&netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: "eth0"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, route := range []netlink.Route{
+		{Dst: mustParseCIDR(t, "0.0.0.0/0"), Gw: net.IP{10, 0, 0, 1}},
+		{Dst: mustParseCIDR(t, "10.0.0.0/24"), LinkIndex: 2, Scope: netlink.SCOPE_LINK},
+		{Dst: mustParseCIDR(t, "172.16.0.0/12"), Type: unix.RTN_BLACKHOLE},
+		{Dst: mustParseCIDR(t, "192.168.0.0/16"), Gw: net.IP{10, 0, 0, 2}, Table: 42},
+	} {
+		if err := f.RouteAdd(&route); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testCases := []struct {
+		name      string
+		ip        net.IP
+		gw        net.IP
+		linkIndex int
+		err       error
+	}{
+		{"directly connected", net.IP{10, 0, 0, 5}, nil, 2, nil},
+		{"through default gateway", net.IP{192, 168, 1, 1}, net.IP{10, 0, 0, 1}, 0, nil},
+		{"blackhole", net.IP{172, 16, 0, 1}, nil, 0, unix.EINVAL},
+		{"no route", net.ParseIP("fd00::1"), nil, 0, unix.ENETUNREACH},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			routes, err := f.RouteGet(tc.ip)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("Expected %v, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(routes) != 1 || !routes[0].Gw.Equal(tc.gw) || routes[0].LinkIndex != tc.linkIndex || !routes[0].Dst.IP.Equal(tc.ip) {
+				t.Errorf("Unexpected route: %v", routes)
+			}
+		})
+	}
+}
+
+func TestRouteSubscribe(t *testing.T) {
+	f := NewNetlink()
+	updates := make(chan netlink.RouteUpdate)
+	done := make(chan struct{})
+	if err := f.RouteSubscribe(updates, done); err != nil {
+		t.Fatal(err)
+	}
+	route := netlink.Route{Dst: mustParseCIDR(t, "10.0.0.0/8"), Protocol: 196}
+	if err := f.RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.RouteDel(&netlink.Route{Dst: route.Dst}); err != nil {
+		t.Fatal(err)
+	}
+	if update := receiveUpdate(t, updates); update.Type != unix.RTM_NEWROUTE || update.Protocol != 196 {
+		t.Errorf("Expected the new route, got %v", update)
+	}
+	if update := receiveUpdate(t, updates); update.Type != unix.RTM_DELROUTE || update.Protocol != 196 {
+		t.Errorf("Expected the deleted route, got %v", update)
+	}
+	close(done)
+	if _, open := <-updates; open {
+		t.Error("Update channel must be closed after done")
+	}
+}
+
+func TestCloseSubscriptions(t *testing.T) {
+	f := NewNetlink()
+	updates := make(chan netlink.RouteUpdate)
+	if err := f.RouteSubscribe(updates, make(chan struct{})); err != nil {
+		t.Fatal(err)
+	}
+	ruleUpdates := make(chan struct{})
+	if err := f.RuleSubscribe(ruleUpdates, make(chan struct{})); err != nil {
+		t.Fatal(err)
+	}
+	f.CloseSubscriptions()
+	if _, open := <-updates; open {
+		t.Error("Update channel must be closed")
+	}
+	if _, open := <-ruleUpdates; open {
+		t.Error("Rule update channel must be closed")
+	}
+}
+
+func TestRuleSubscribe(t *testing.T) {
+	f := NewNetlink()
+	updates := make(chan struct{})
+	done := make(chan struct{})
+	if err := f.RuleSubscribe(updates, done); err != nil {
+		t.Fatal(err)
+	}
+	rule := netlink.NewRule()
+	rule.Priority = 1000
+	rule.Table = 100
+	if err := f.RuleAdd(rule); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.RuleDel(rule); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-updates:
+	case <-time.After(time.Second):
+		t.Fatal("Rule deletion must be signalled")
+	}
+	close(done)
+	if _, open := <-updates; open {
+		t.Error("Update channel must be closed after done")
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	f := NewNetlink()
+	for _, neigh := range []netlink.Neigh{
+		{LinkIndex: 1, IP: net.IP{10, 0, 0, 1}, State: netlink.NUD_REACHABLE},
+		{LinkIndex: 2, IP: net.ParseIP("fd00::1"), State: netlink.NUD_STALE},
+	} {
+		if err := f.NeighAdd(&neigh); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.NeighAdd(&netlink.Neigh{LinkIndex: 1, IP: net.IP{10, 0, 0, 1}}); !errors.Is(err, unix.EEXIST) {
+		t.Errorf("EEXIST is expected for the same neighbor, got %v", err)
+	}
+	if neighs, _ := f.NeighList(0, netlink.FAMILY_V4); len(neighs) != 1 || neighs[0].State != netlink.NUD_REACHABLE {
+		t.Errorf("IPv4 neighbor is expected, got %v", neighs)
+	}
+	if neighs, _ := f.NeighList(2, netlink.FAMILY_ALL); len(neighs) != 1 || neighs[0].Family != netlink.FAMILY_V6 {
+		t.Errorf("IPv6 neighbor of the link is expected, got %v", neighs)
+	}
+}
+
+func TestRunInNamespace(t *testing.T) {
+	f := NewNetlink()
+	called := false
+	if err := f.RunInNamespace(func() error { called = true; return nil }); err != nil || !called {
+		t.Errorf("Function must be called: %v", err)
+	}
+	injected := errors.New("injected")
+	f.Fail("RunInNamespace", injected)
+	if err := f.RunInNamespace(func() error { t.Error("Function must not be called"); return nil }); err != injected {
+		t.Errorf("Injected error is expected, got %v", err)
+	}
+}
+
+func TestFail(t *testing.T) {
+	f := NewNetlink()
+	injected := errors.New("injected")
+	f.Fail("RouteAdd", injected)
+	route := netlink.Route{Dst: mustParseCIDR(t, "10.0.0.0/8")}
+	if err := f.RouteAdd(&route); err != injected {
+		t.Errorf("Injected error is expected, got %v", err)
+	}
+	if routes := listAll(t, f); len(routes) != 0 {
+		t.Errorf("Failed operation must not change the routes: %v", routes)
+	}
+	if err := f.RouteAdd(&route); err != nil {
+		t.Errorf("Only the next call shall fail: %v", err)
+	}
+}
+
+func TestRules(t *testing.T) {
+	f := NewNetlink()
+	rules, err := f.RuleList(netlink.FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("Expected the 3 default rules, got %v", rules)
+	}
+	rule := netlink.NewRule()
+	rule.Src = mustParseCIDR(t, "10.0.0.0/8")
+	rule.Table = 42
+	if err := f.RuleAdd(rule); err != nil {
+		t.Fatal(err)
+	}
+	rules, _ = f.RuleList(netlink.FAMILY_V4)
+	if len(rules) != 4 || rules[1].Priority != 32765 || rules[1].Table != 42 {
+		t.Errorf("Rule must be added before the main table with the default priority, got %v", rules)
+	}
+	if rules, _ = f.RuleList(netlink.FAMILY_V6); len(rules) != 2 {
+		t.Errorf("Expected the 2 default IPv6 rules, got %v", rules)
+	}
+	rule.Priority = 32765
+	if err := f.RuleAdd(rule); !errors.Is(err, unix.EEXIST) {
+		t.Errorf("EEXIST is expected for the same rule, got %v", err)
+	}
+	if err := f.RuleDel(rule); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.RuleDel(rule); !errors.Is(err, unix.ENOENT) {
+		t.Errorf("ENOENT is expected for a missing rule, got %v", err)
+	}
+}
+
+func TestRuleMarkWithoutMask(t *testing.T) {
+	f := NewNetlink()
+	rule := netlink.NewRule()
+	rule.Mark = 100
+	rule.Priority = 1000
+	rule.Table = 42
+	if err := f.RuleAdd(rule); err != nil {
+		t.Fatal(err)
+	}
+	rules, _ := f.RuleList(netlink.FAMILY_V4)
+	if rules[1].Mask == nil || *rules[1].Mask != ^uint32(0) {
+		t.Errorf("The whole mark must be compared if no mask is given, got %v", rules[1])
+	}
+	if err := f.RuleAdd(rule); !errors.Is(err, unix.EEXIST) {
+		t.Errorf("EEXIST is expected for the same rule, got %v", err)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	f := NewNetlink()
+	if err := f.LinkAdd(&netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: "lo"}}); !errors.Is(err, unix.EEXIST) {
+		t.Errorf("EEXIST is expected for the same name, got %v", err)
+	}
+	link := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: "eth0"}}
+	if err := f.LinkAdd(link); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := f.LinkByName("eth0"); err != nil || found.Attrs().Index != 2 {
+		t.Errorf("Link must get the next index: %v %v", found, err)
+	}
+	if err := f.RouteAdd(&netlink.Route{Dst: mustParseCIDR(t, "10.0.0.0/24"), LinkIndex: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.LinkDel(link); err != nil {
+		t.Fatal(err)
+	}
+	if routes := listAll(t, f); len(routes) != 0 {
+		t.Errorf("Routes of the deleted link must be removed: %v", routes)
+	}
+	if _, err := f.LinkByIndex(2); !errors.Is(err, unix.ENODEV) {
+		t.Errorf("ENODEV is expected for a missing link, got %v", err)
+	}
+	if links, _ := f.LinkList(); len(links) != 1 {
+		t.Errorf("Only the loopback is expected, got %v", links)
+	}
+}
+
+func TestAddrs(t *testing.T) {
+	f := NewNetlink()
+	link := &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: "eth0"}}
+	if err := f.LinkAdd(link); err != nil {
+		t.Fatal(err)
+	}
+	addr := &netlink.Addr{IPNet: &net.IPNet{IP: net.IP{10, 0, 0, 5}, Mask: net.CIDRMask(24, 32)}}
+	if err := f.AddrAdd(link, addr); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.AddrAdd(link, addr); !errors.Is(err, unix.EEXIST) {
+		t.Errorf("EEXIST is expected for the same address, got %v", err)
+	}
+	if err := f.AddrAdd(&netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Index: 42}}, addr); !errors.Is(err, unix.ENODEV) {
+		t.Errorf("ENODEV is expected for a missing link, got %v", err)
+	}
+	routes := listAll(t, f)
+	if len(routes) != 1 || routes[0].Dst.String() != "10.0.0.0/24" || routes[0].LinkIndex != link.Index || routes[0].Protocol != unix.RTPROT_KERNEL {
+		t.Errorf("Route of the prefix must be added: %v", routes)
+	}
+	if addrs, _ := f.AddrList(link, netlink.FAMILY_V4); len(addrs) != 1 || addrs[0].LinkIndex != link.Index {
+		t.Errorf("Address of the link must be listed: %v", addrs)
+	}
+	if addrs, _ := f.AddrList(nil, netlink.FAMILY_V6); len(addrs) != 0 {
+		t.Errorf("Address of other family must not be listed: %v", addrs)
+	}
+	if err := f.LinkDel(link); err != nil {
+		t.Fatal(err)
+	}
+	if addrs, _ := f.AddrList(nil, netlink.FAMILY_ALL); len(addrs) != 0 {
+		t.Errorf("Addresses of the deleted link must be removed: %v", addrs)
+	}
+}
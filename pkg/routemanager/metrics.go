@@ -0,0 +1,90 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package routemanager
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	operationRegister   = "register"
+	operationUpdate     = "update"
+	operationDeRegister = "deregister"
+)
+
+var (
+	managedRoutesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "staticroute",
+		Name:      "managed_routes",
+		Help:      "Number of routes managed by the operator per routing table",
+	}, []string{"table"})
+	operationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "staticroute",
+		Name:      "route_operation_duration_seconds",
+		Help:      "Latency of route registrations, updates and deregistrations, including the netlink calls",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 8),
+	}, []string{"operation"})
+	operationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "staticroute",
+		Name:      "route_operation_errors_total",
+		Help:      "Number of failed route registrations, updates and deregistrations",
+	}, []string{"operation"})
+	tamperedRoutes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "staticroute",
+		Name:      "route_tampered_total",
+		Help:      "Number of managed routes deleted by an external entity",
+	})
+	routeDrifts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "staticroute",
+		Name:      "route_drifts_total",
+		Help:      "Number of differences between the managed routes and the kernel found by the audit",
+	}, []string{"type", "repaired"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(managedRoutesGauge, operationDuration, operationErrors, tamperedRoutes, routeDrifts)
+}
+
+// updateManagedRoutesMetric recounts the managed routes, so tables without routes disappear from the metric
+func (r *routeManagerImpl) updateManagedRoutesMetric() {
+	counts := make(map[int]int)
+	for _, route := range r.managedRoutes {
+		counts[route.Table]++
+	}
+	managedRoutesGauge.Reset()
+	for table, count := range counts {
+		managedRoutesGauge.WithLabelValues(strconv.Itoa(table)).Set(float64(count))
+	}
+}
+
+// observeOperation records the latency and the outcome of a route operation
+func observeOperation(operation string, start time.Time, err error) error {
+	operationDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		operationErrors.WithLabelValues(operation).Inc()
+	}
+	return err
+}
+
+// observeDrift counts a drift found by the audit
+func observeDrift(drift Drift) {
+	routeDrifts.WithLabelValues(string(drift.Type), strconv.FormatBool(drift.Repaired)).Inc()
+}
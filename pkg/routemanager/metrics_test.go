@@ -0,0 +1,98 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package routemanager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/vishvananda/netlink"
+)
+
+func TestManagedRoutesMetric(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+	other := gTestRoute
+	other.Table = 42
+	if err := testable.rm.RegisterRoute("other", other); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+	if value := testutil.ToFloat64(managedRoutesGauge.WithLabelValues("254")); value != 1 {
+		t.Errorf("Managed routes in table 254 must be 1, it is %f", value)
+	}
+
+	if err := testable.rm.DeRegisterRoute("other"); err != nil {
+		t.Error("DeRegisterRoute shall pass here")
+	}
+	if count := testutil.CollectAndCount(managedRoutesGauge); count != 1 {
+		t.Errorf("Tables without routes must be removed from the metric, %d tables reported", count)
+	}
+	if err := testable.rm.DeRegisterRoute(gTestRouteName); err != nil {
+		t.Error("DeRegisterRoute shall pass here")
+	}
+}
+
+func TestOperationMetrics(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.kernel.Fail("RouteAdd", errors.New("bla"))
+	testable.start()
+	defer testable.stop()
+	errorsBefore := testutil.ToFloat64(operationErrors.WithLabelValues(operationRegister))
+
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err == nil {
+		t.Error("RegisterRoute shall fail here")
+	}
+
+	if value := testutil.ToFloat64(operationErrors.WithLabelValues(operationRegister)); value != errorsBefore+1 {
+		t.Errorf("Register error must be counted: %f", value)
+	}
+	if testutil.CollectAndCount(operationDuration) == 0 {
+		t.Error("Register latency must be observed")
+	}
+}
+
+func TestTamperedRoutesMetric(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.start()
+	defer testable.stop()
+	before := testutil.ToFloat64(tamperedRoutes)
+	mockWatcher := MockRouteWatcher{routeDeletedCalledWith: make(chan Route)}
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+	testable.rm.RegisterWatcher(mockWatcher)
+
+	if err := testable.kernel.RouteDel(&netlink.Route{Dst: &gTestRoute.Dst}); err != nil {
+		t.Fatal(err)
+	}
+	// The watcher is notified after the tampering is counted
+	<-mockWatcher.routeDeletedCalledWith
+	testable.rm.DeRegisterWatcher(mockWatcher)
+	if err := testable.rm.DeRegisterRoute(gTestRouteName); err != nil {
+		t.Error("DeRegisterRoute shall pass here")
+	}
+
+	if value := testutil.ToFloat64(tamperedRoutes); value != before+1 {
+		t.Errorf("Tampered route must be counted: %f", value)
+	}
+}
@@ -0,0 +1,166 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package routemanager
+
+import (
+	"net"
+	"runtime"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+// Netlink is the part of the kernel's netlink interface used by the operator: the routes, the policy routing rules,
+// the links, the addresses and the neighbors of a network namespace. The errors of the kernel (i.e. EEXIST, ESRCH) are returned as syscall.Errno.
+type Netlink interface {
+	RouteAdd(*netlink.Route) error
+	RouteReplace(*netlink.Route) error
+	RouteDel(*netlink.Route) error
+	RouteListFiltered(family int, filter *netlink.Route, filterMask uint64) ([]netlink.Route, error)
+	RouteGet(net.IP) ([]netlink.Route, error)
+	//RouteSubscribe sends the route changes to the channel until the done channel is closed
+	RouteSubscribe(chan<- netlink.RouteUpdate, <-chan struct{}) error
+	RuleAdd(*netlink.Rule) error
+	RuleDel(*netlink.Rule) error
+	RuleList(family int) ([]netlink.Rule, error)
+	//RuleSubscribe signals on the channel whenever a rule is deleted, until the done channel is closed
+	RuleSubscribe(chan<- struct{}, <-chan struct{}) error
+	LinkAdd(netlink.Link) error
+	LinkDel(netlink.Link) error
+	LinkByName(string) (netlink.Link, error)
+	LinkByIndex(int) (netlink.Link, error)
+	LinkList() ([]netlink.Link, error)
+	//AddrList lists the addresses of the link, or of all links if it is nil
+	AddrList(link netlink.Link, family int) ([]netlink.Addr, error)
+	//NeighList lists the neighbor entries of the link, or of all links if the index is 0
+	NeighList(linkIndex, family int) ([]netlink.Neigh, error)
+	//RunInNamespace calls the function in the network namespace, so the sockets it opens belong to the namespace
+	RunInNamespace(func() error) error
+}
+
+// kernelNetlink talks to the kernel through the netlink package, the handle implements everything but the subscriptions.
+// The namespace is nil if the handle belongs to the network namespace of the process.
+type kernelNetlink struct {
+	*netlink.Handle
+	namespace *netns.NsHandle
+}
+
+// blank assignment to verify that kernelNetlink implements Netlink
+var _ Netlink = kernelNetlink{}
+
+// NewNetlink returns the Netlink of the network namespace of the process
+func NewNetlink() Netlink {
+	// The zero value of the handle uses the network namespace of the process, like the functions of the netlink package
+	return kernelNetlink{Handle: &netlink.Handle{}}
+}
+
+// NewNetlinkAt returns the Netlink of the network namespace given by its handle (file descriptor). The handle must stay
+// open as long as the Netlink is used, because the subscriptions open their sockets in the namespace.
+func NewNetlinkAt(namespace netns.NsHandle) (Netlink, error) {
+	handle, err := netlink.NewHandleAt(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return kernelNetlink{Handle: handle, namespace: &namespace}, nil
+}
+
+// NewNetlinkAtPath returns the Netlink of the network namespace bind mounted to the path (i.e. /var/run/netns/vpn).
+// The namespace is kept open for the lifetime of the process.
+func NewNetlinkAtPath(path string) (Netlink, error) {
+	namespace, err := netns.GetFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	nl, err := NewNetlinkAt(namespace)
+	if err != nil {
+		namespace.Close()
+		return nil, err
+	}
+	return nl, nil
+}
+
+func (k kernelNetlink) RouteSubscribe(updateChan chan<- netlink.RouteUpdate, doneChan <-chan struct{}) error {
+	if k.namespace == nil {
+		return netlink.RouteSubscribe(updateChan, doneChan)
+	}
+	return netlink.RouteSubscribeWithOptions(updateChan, doneChan, netlink.RouteSubscribeOptions{Namespace: k.namespace})
+}
+
+// RunInNamespace locks the calling goroutine to its thread and switches the thread into the namespace while the
+// function runs, the sockets keep their namespace afterwards
+func (k kernelNetlink) RunInNamespace(f func() error) error {
+	if k.namespace == nil {
+		return f()
+	}
+	runtime.LockOSThread()
+	origin, err := netns.Get()
+	if err != nil {
+		runtime.UnlockOSThread()
+		return err
+	}
+	defer origin.Close()
+	if err := netns.Set(*k.namespace); err != nil {
+		runtime.UnlockOSThread()
+		return err
+	}
+	defer func() {
+		// The thread stays locked if it can not be moved back, so it is terminated with the goroutine
+		if netns.Set(origin) == nil {
+			runtime.UnlockOSThread()
+		}
+	}()
+	return f()
+}
+
+// RuleSubscribe closes the channel when the subscription ends. Netlink package has no rule subscription, and it can
+// not parse the rule messages, so the receiver has to list the rules to find out which one is missing.
+func (k kernelNetlink) RuleSubscribe(updateChan chan<- struct{}, doneChan <-chan struct{}) error {
+	namespace := netns.None()
+	if k.namespace != nil {
+		namespace = *k.namespace
+	}
+	s, err := nl.SubscribeAt(namespace, netns.None(), unix.NETLINK_ROUTE, unix.RTNLGRP_IPV4_RULE, unix.RTNLGRP_IPV6_RULE)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-doneChan
+		s.Close()
+	}()
+	go func() {
+		defer close(updateChan)
+		for {
+			msgs, _, err := s.Receive()
+			if err != nil {
+				return
+			}
+			for _, m := range msgs {
+				if m.Header.Type != unix.RTM_DELRULE {
+					continue
+				}
+				select {
+				case updateChan <- struct{}{}:
+				case <-doneChan:
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
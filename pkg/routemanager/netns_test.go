@@ -0,0 +1,181 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package routemanager
+
+import (
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+// newTestNamespace creates a throwaway network namespace with a veth pair, one end holding 10.0.0.5/24 and fd00::5/64.
+// The test is skipped without the privileges to create it.
+func newTestNamespace(t *testing.T) (netns.NsHandle, *netlink.Handle) {
+	runtime.LockOSThread()
+	origin, err := netns.Get()
+	if err != nil {
+		runtime.UnlockOSThread()
+		t.Skipf("Network namespace of the test is not available: %v", err)
+	}
+	defer origin.Close()
+	// New switches the thread into the new namespace
+	namespace, err := netns.New()
+	if err != nil {
+		runtime.UnlockOSThread()
+		t.Skipf("Network namespace can not be created: %v", err)
+	}
+	if err := netns.Set(origin); err != nil {
+		// The thread stays locked, so it is terminated with the goroutine of the test
+		t.Fatalf("Network namespace of the test can not be restored: %v", err)
+	}
+	runtime.UnlockOSThread()
+	t.Cleanup(func() { namespace.Close() })
+
+	handle, err := netlink.NewHandleAt(namespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(handle.Close)
+	link := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "veth0"}, PeerName: "veth1"}
+	if err := handle.LinkAdd(link); err != nil {
+		t.Skipf("Veth link can not be created: %v", err)
+	}
+	for _, name := range []string{"veth0", "veth1"} {
+		if err := handle.LinkSetUp(&netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, addr := range []*netlink.Addr{
+		{IPNet: &net.IPNet{IP: net.IP{10, 0, 0, 5}, Mask: net.CIDRMask(24, 32)}},
+		// Without duplicate address detection the address is usable at once
+		{IPNet: &net.IPNet{IP: net.ParseIP("fd00::5"), Mask: net.CIDRMask(64, 128)}, Flags: unix.IFA_F_NODAD},
+	} {
+		if err := handle.AddrAdd(link, addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return namespace, handle
+}
+
+func listProtocolRoutes(t *testing.T, list func(int, *netlink.Route, uint64) ([]netlink.Route, error), dst net.IPNet) []netlink.Route {
+	routes, err := list(netlink.FAMILY_ALL, &netlink.Route{Protocol: gTestProtocol, Dst: &dst}, netlink.RT_FILTER_PROTOCOL|netlink.RT_FILTER_DST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return routes
+}
+
+func TestRouteManagerInNamespace(t *testing.T) {
+	testCases := []struct {
+		name  string
+		route Route
+	}{
+		{"IPv4", Route{Dst: net.IPNet{IP: net.IP{192, 168, 1, 0}, Mask: net.CIDRMask(24, 32)}, Gw: net.IP{10, 0, 0, 1}, Table: 254}},
+		{"IPv6", Route{Dst: net.IPNet{IP: net.ParseIP("fd00:1::"), Mask: net.CIDRMask(64, 128)}, Gw: net.ParseIP("fd00::1"), Table: 254}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			namespace, handle := newTestNamespace(t)
+			rm, err := NewAt(namespace, int(gTestProtocol), AuditOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(rm.(*routeManagerImpl).nl.(kernelNetlink).Close)
+			testable := testableRouteManager{rm: rm, stopChan: make(chan struct{})}
+			testable.start()
+			defer testable.stop()
+
+			if err := rm.RegisterRoute(gTestRouteName, tc.route); err != nil {
+				t.Fatalf("RegisterRoute shall pass here: %v", err)
+			}
+			if routes := listProtocolRoutes(t, handle.RouteListFiltered, tc.route.Dst); len(routes) != 1 || !routes[0].Gw.Equal(tc.route.Gw) {
+				t.Errorf("Route must be installed in the namespace: %v", routes)
+			}
+			if routes := listProtocolRoutes(t, netlink.RouteListFiltered, tc.route.Dst); len(routes) != 0 {
+				t.Errorf("Route must not be installed in the namespace of the process: %v", routes)
+			}
+			if drifts, err := rm.Audit(); err != nil || len(drifts) != 0 {
+				t.Errorf("Route in the namespace must not drift: %v %v", drifts, err)
+			}
+
+			mockWatcher := MockRouteWatcher{routeDeletedCalledWith: make(chan Route, 1)}
+			rm.RegisterWatcher(mockWatcher)
+			if err := handle.RouteDel(&netlink.Route{Dst: &tc.route.Dst}); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case deleted := <-mockWatcher.routeDeletedCalledWith:
+				if !deleted.equal(tc.route.InKernel()) {
+					t.Errorf("Deleted route must be reported: %v", deleted)
+				}
+			case <-time.After(5 * time.Second):
+				t.Error("Deletion in the namespace must be reported to the watchers")
+			}
+			rm.DeRegisterWatcher(mockWatcher)
+
+			if err := rm.DeRegisterRoute(gTestRouteName); err != nil {
+				t.Errorf("DeRegisterRoute shall pass here: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewNetlinkAtPathMissing(t *testing.T) {
+	if _, err := NewNetlinkAtPath("/nonexistent/netns"); err == nil {
+		t.Error("Missing namespace must be reported")
+	}
+}
+
+func TestRunInNamespace(t *testing.T) {
+	namespace, _ := newTestNamespace(t)
+	nl, err := NewNetlinkAt(namespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(nl.(kernelNetlink).Close)
+	origin, err := netns.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer origin.Close()
+
+	err = nl.RunInNamespace(func() error {
+		current, err := netns.Get()
+		if err != nil {
+			return err
+		}
+		defer current.Close()
+		if !current.Equal(namespace) {
+			t.Error("Function must run in the namespace")
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("RunInNamespace shall pass here: %v", err)
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	if current, err := netns.Get(); err != nil || !current.Equal(origin) {
+		t.Errorf("Thread must be moved back to the namespace of the process: %v", err)
+	}
+}
@@ -17,11 +17,17 @@
 package routemanager
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"net"
 	"reflect"
+	"slices"
 	"syscall"
+	"time"
 
 	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
 	"golang.org/x/sys/unix"
 )
 
@@ -32,14 +38,20 @@ var (
 
 type routeManagerImpl struct {
 	managedRoutes         map[string]Route
+	shadowingRoutes       map[string][]Route
 	watchers              []RouteWatcher
-	nlRouteSubscribeFunc  func(chan<- netlink.RouteUpdate, <-chan struct{}) error
-	nlRouteAddFunc        func(route *netlink.Route) error
-	nlRouteDelFunc        func(route *netlink.Route) error
+	protocol              netlink.RouteProtocol
+	audit                 AuditOptions
+	nl                    Netlink
 	registerRouteChan     chan routeManagerImplRegisterRouteParams
+	updateRouteChan       chan routeManagerImplRegisterRouteParams
 	deRegisterRouteChan   chan routeManagerImplDeRegisterRouteParams
 	registerWatcherChan   chan RouteWatcher
 	deRegisterWatcherChan chan RouteWatcher
+	collectGarbageChan    chan routeManagerImplCollectGarbageParams
+	auditChan             chan routeManagerImplAuditParams
+	getRouteChan          chan routeManagerImplGetRouteParams
+	listRoutesChan        chan routeManagerImplListRoutesParams
 }
 
 type routeManagerImplRegisterRouteParams struct {
@@ -53,52 +65,231 @@ type routeManagerImplDeRegisterRouteParams struct {
 	err  chan<- error
 }
 
-// New creates a RouteManager for production use. It populates the routeManagerImpl structure with the final pointers to netlink package's functions.
-func New() RouteManager {
+type routeManagerImplCollectGarbageParams struct {
+	adopt func(Route) (string, bool)
+	err   chan<- error
+}
+
+type routeManagerImplAuditParams struct {
+	result chan<- routeManagerImplAuditResult
+}
+
+type routeManagerImplAuditResult struct {
+	drifts []Drift
+	err    error
+}
+
+type routeManagerImplGetRouteParams struct {
+	name   string
+	result chan<- routeManagerImplGetRouteResult
+}
+
+type routeManagerImplGetRouteResult struct {
+	route Route
+	err   error
+}
+
+type routeManagerImplListRoutesParams struct {
+	routes chan<- map[string]Route
+}
+
+// New creates a RouteManager for production use, which manages the routes of the network namespace of the process.
+// The routes are installed with the given routing protocol ID (rtm_protocol), which identifies them as owned by the RouteManager.
+// The managed routes are compared with the kernel periodically according to the audit options.
+func New(protocol int, audit AuditOptions) RouteManager {
+	return NewWithNetlink(NewNetlink(), protocol, audit)
+}
+
+// NewAt creates a RouteManager for production use, which manages the routes of the network namespace given by its
+// handle instead of the one of the process. The handle must stay open as long as the RouteManager runs.
+func NewAt(namespace netns.NsHandle, protocol int, audit AuditOptions) (RouteManager, error) {
+	nl, err := NewNetlinkAt(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithNetlink(nl, protocol, audit), nil
+}
+
+// NewWithNetlink creates a RouteManager which manages the routes through the given Netlink, i.e. a fake kernel in tests
+func NewWithNetlink(nl Netlink, protocol int, audit AuditOptions) RouteManager {
 	return &routeManagerImpl{
 		managedRoutes:         make(map[string]Route),
-		nlRouteSubscribeFunc:  netlink.RouteSubscribe,
-		nlRouteAddFunc:        netlink.RouteAdd,
-		nlRouteDelFunc:        netlink.RouteDel,
+		shadowingRoutes:       make(map[string][]Route),
+		protocol:              netlink.RouteProtocol(protocol),
+		audit:                 audit,
+		nl:                    nl,
 		registerRouteChan:     make(chan routeManagerImplRegisterRouteParams),
+		updateRouteChan:       make(chan routeManagerImplRegisterRouteParams),
 		deRegisterRouteChan:   make(chan routeManagerImplDeRegisterRouteParams),
 		registerWatcherChan:   make(chan RouteWatcher),
 		deRegisterWatcherChan: make(chan RouteWatcher),
+		collectGarbageChan:    make(chan routeManagerImplCollectGarbageParams),
+		auditChan:             make(chan routeManagerImplAuditParams),
+		getRouteChan:          make(chan routeManagerImplGetRouteParams),
+		listRoutesChan:        make(chan routeManagerImplListRoutesParams),
 	}
 }
 
 func (r *routeManagerImpl) RegisterRoute(name string, route Route) error {
+	start := time.Now()
 	errChan := make(chan error)
 	r.registerRouteChan <- routeManagerImplRegisterRouteParams{name, route, errChan}
-	return <-errChan
+	return observeOperation(operationRegister, start, <-errChan)
 }
 
 func (r *routeManagerImpl) IsRegistered(name string) bool {
+	_, err := r.GetRoute(name)
+	return err == nil
+}
+
+// isRegistered is the version of IsRegistered for the event loop, which owns the managed routes
+func (r *routeManagerImpl) isRegistered(name string) bool {
 	_, exists := r.managedRoutes[name]
 	return exists
 }
 
+func (r *routeManagerImpl) GetRoute(name string) (Route, error) {
+	resultChan := make(chan routeManagerImplGetRouteResult)
+	r.getRouteChan <- routeManagerImplGetRouteParams{name, resultChan}
+	result := <-resultChan
+	return result.route, result.err
+}
+
+func (r *routeManagerImpl) getRoute(params routeManagerImplGetRouteParams) {
+	route, found := r.managedRoutes[params.name]
+	if !found {
+		params.result <- routeManagerImplGetRouteResult{err: ErrNotFound}
+		return
+	}
+	params.result <- routeManagerImplGetRouteResult{route: route.copy()}
+}
+
+func (r *routeManagerImpl) ListRoutes() map[string]Route {
+	routesChan := make(chan map[string]Route)
+	r.listRoutesChan <- routeManagerImplListRoutesParams{routesChan}
+	return <-routesChan
+}
+
+func (r *routeManagerImpl) listRoutes(params routeManagerImplListRoutesParams) {
+	routes := make(map[string]Route, len(r.managedRoutes))
+	for name, route := range r.managedRoutes {
+		routes[name] = route.copy()
+	}
+	params.routes <- routes
+}
+
 func (r *routeManagerImpl) registerRoute(params routeManagerImplRegisterRouteParams) {
-	if r.IsRegistered(params.name) {
+	if r.isRegistered(params.name) {
 		params.err <- errors.New("Route with the same Name already registered")
 		return
 	}
-	nlRoute := params.route.toNetLinkRoute()
+	for name, route := range r.managedRoutes {
+		if route.conflicts(params.route) {
+			params.err <- fmt.Errorf("Route with the same destination, table and priority already registered by %s", name)
+			return
+		}
+	}
+	if err := r.addRoute(params.route); err != nil {
+		params.err <- err
+		return
+	}
+	r.managedRoutes[params.name] = params.route
+	params.err <- nil
+}
+
+// addRoute installs the route in the kernel
+func (r *routeManagerImpl) addRoute(route Route) error {
+	nlRoute := r.toNetLinkRoute(route)
 	/* If syscall returns EEXIST (file exists), it means the route already existing.
-	   There is no evidence that we created is before a crash, or someone else.
-	   We assume we created it and so start managing it again. */
-	if err := r.nlRouteAddFunc(&nlRoute); err != nil && syscall.EEXIST.Error() != err.Error() {
+	   If it carries our protocol ID, we created it before a crash and start managing it again,
+	   otherwise it belongs to someone else. */
+	if err := r.nl.RouteAdd(&nlRoute); err != nil && syscall.EEXIST.Error() != err.Error() {
+		return err
+	} else if err != nil {
+		return r.adoptExisting(route)
+	}
+	return nil
+}
+
+func (r *routeManagerImpl) UpdateRoute(name string, route Route) error {
+	start := time.Now()
+	errChan := make(chan error)
+	r.updateRouteChan <- routeManagerImplRegisterRouteParams{name, route, errChan}
+	return observeOperation(operationUpdate, start, <-errChan)
+}
+
+func (r *routeManagerImpl) updateRoute(params routeManagerImplRegisterRouteParams) {
+	old, found := r.managedRoutes[params.name]
+	if !found {
+		params.err <- ErrNotFound
+		return
+	}
+	for name, route := range r.managedRoutes {
+		if name != params.name && route.conflicts(params.route) {
+			params.err <- fmt.Errorf("Route with the same destination, table and priority already registered by %s", name)
+			return
+		}
+	}
+	if old.conflicts(params.route) {
+		// The kernel replaces the route with the same destination, table and priority in one step
+		nlRoute := r.toNetLinkRoute(params.route)
+		if err := r.nl.RouteReplace(&nlRoute); err != nil {
+			params.err <- err
+			return
+		}
+		r.managedRoutes[params.name] = params.route
+		params.err <- nil
+		return
+	}
+	/* The new version does not take the place of the old one in the kernel, so it is added first and the old one
+	   is removed only afterwards. The deletion of the old version is not reported to the watchers any more. */
+	if err := r.addRoute(params.route); err != nil {
 		params.err <- err
 		return
 	}
 	r.managedRoutes[params.name] = params.route
+	nlRoute := r.toNetLinkRoute(old)
+	if err := r.nl.RouteDel(&nlRoute); err != nil && syscall.ESRCH.Error() != err.Error() {
+		params.err <- err
+		return
+	}
 	params.err <- nil
 }
 
+// adoptExisting checks whether the route already in the kernel was installed by us. A stale version of our route
+// (i.e. with a different gateway) is replaced.
+func (r *routeManagerImpl) adoptExisting(route Route) error {
+	existing, err := r.listOwnRoutes()
+	if err != nil {
+		return err
+	}
+	for _, nlRoute := range existing {
+		kernelRoute := fromNetLinkRoute(nlRoute)
+		if !kernelRoute.conflicts(route.InKernel()) {
+			continue
+		}
+		if kernelRoute.equal(route.InKernel()) {
+			return nil
+		}
+		if err := r.nl.RouteDel(&nlRoute); err != nil && syscall.ESRCH.Error() != err.Error() {
+			return err
+		}
+		nlRoute = r.toNetLinkRoute(route)
+		return r.nl.RouteAdd(&nlRoute)
+	}
+	return fmt.Errorf("Route to %s already exists in table %d, but it was not installed by the operator", route.Dst.String(), route.Table)
+}
+
+// listOwnRoutes returns the routes of all tables which carry our protocol ID
+func (r *routeManagerImpl) listOwnRoutes() ([]netlink.Route, error) {
+	return r.nl.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Protocol: r.protocol, Table: unix.RT_TABLE_UNSPEC}, netlink.RT_FILTER_PROTOCOL|netlink.RT_FILTER_TABLE)
+}
+
 func (r *routeManagerImpl) DeRegisterRoute(name string) error {
+	start := time.Now()
 	errChan := make(chan error)
 	r.deRegisterRouteChan <- routeManagerImplDeRegisterRouteParams{name, errChan}
-	return <-errChan
+	return observeOperation(operationDeRegister, start, <-errChan)
 }
 
 func (r *routeManagerImpl) deRegisterRoute(params routeManagerImplDeRegisterRouteParams) {
@@ -107,10 +298,10 @@ func (r *routeManagerImpl) deRegisterRoute(params routeManagerImplDeRegisterRout
 		params.err <- ErrNotFound
 		return
 	}
-	nlRoute := item.toNetLinkRoute()
+	nlRoute := r.toNetLinkRoute(item)
 	/* We remove the route from the managed ones, regardless of the ESRCH (no such process) error from the lower layer.
 	   Error supposed to happen only when the route is already missing, which was reported to the watchers, so they know. */
-	if err := r.nlRouteDelFunc(&nlRoute); err != nil && syscall.ESRCH.Error() != err.Error() {
+	if err := r.nl.RouteDel(&nlRoute); err != nil && syscall.ESRCH.Error() != err.Error() {
 		params.err <- err
 		return
 	}
@@ -118,6 +309,44 @@ func (r *routeManagerImpl) deRegisterRoute(params routeManagerImplDeRegisterRout
 	params.err <- nil
 }
 
+func (r *routeManagerImpl) CollectGarbage(adopt func(Route) (string, bool)) error {
+	errChan := make(chan error)
+	r.collectGarbageChan <- routeManagerImplCollectGarbageParams{adopt, errChan}
+	return <-errChan
+}
+
+func (r *routeManagerImpl) collectGarbage(params routeManagerImplCollectGarbageParams) {
+	existing, err := r.listOwnRoutes()
+	if err != nil {
+		params.err <- err
+		return
+	}
+	var firstErr error
+	for i := range existing {
+		route := fromNetLinkRoute(existing[i])
+		if r.isManaged(route) {
+			continue
+		}
+		if name, ok := params.adopt(route); ok && !r.isRegistered(name) {
+			r.managedRoutes[name] = route
+			continue
+		}
+		if err := r.nl.RouteDel(&existing[i]); err != nil && syscall.ESRCH.Error() != err.Error() && firstErr == nil {
+			firstErr = err
+		}
+	}
+	params.err <- firstErr
+}
+
+func (r *routeManagerImpl) isManaged(route Route) bool {
+	for _, managed := range r.managedRoutes {
+		if managed.InKernel().conflicts(route) {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *routeManagerImpl) RegisterWatcher(w RouteWatcher) {
 	r.registerWatcherChan <- w
 }
@@ -140,11 +369,88 @@ func (r *routeManagerImpl) deRegisterWatcher(w RouteWatcher) {
 }
 
 func (r Route) toNetLinkRoute() netlink.Route {
-	return netlink.Route{
-		Dst:   &r.Dst,
-		Gw:    r.Gw,
-		Table: r.Table,
+	nlRoute := netlink.Route{
+		Dst:       &r.Dst,
+		Gw:        r.Gw,
+		Table:     r.Table,
+		Priority:  r.Priority,
+		Family:    r.family(),
+		Type:      r.routeType(),
+		LinkIndex: r.LinkIndex,
+		Src:       r.Src,
+		Scope:     netlink.Scope(r.Scope),
+		MTU:       r.MTU,
+		AdvMSS:    r.AdvMSS,
+		InitCwnd:  r.InitCwnd,
+		InitRwnd:  r.InitRwnd,
+		Hoplimit:  r.Hoplimit,
+	}
+	// The destination of a device route is directly reachable on the link, like "ip route add ... dev" does
+	if r.Scope == 0 && r.LinkIndex != 0 && r.Gw == nil && len(r.MultiPath) == 0 && r.routeType() == unix.RTN_UNICAST {
+		nlRoute.Scope = netlink.SCOPE_LINK
+	}
+	// IPv6 routes have no scope, the kernel reports all of them as universe
+	if nlRoute.Family == netlink.FAMILY_V6 {
+		nlRoute.Scope = netlink.SCOPE_UNIVERSE
+	}
+	if r.OnLink {
+		nlRoute.Flags = int(netlink.FLAG_ONLINK)
+	}
+	for _, nextHop := range r.MultiPath {
+		// Kernel stores the weight decreased by one in the hops field
+		hops := 0
+		if nextHop.Weight > 1 {
+			hops = nextHop.Weight - 1
+		}
+		nlRoute.MultiPath = append(nlRoute.MultiPath, &netlink.NexthopInfo{Gw: nextHop.Gw, Hops: hops})
 	}
+	return nlRoute
+}
+
+// routeType returns the kernel route type, unicast if it is not set
+func (r Route) routeType() int {
+	if r.Type == 0 {
+		return unix.RTN_UNICAST
+	}
+	return r.Type
+}
+
+// family returns the netlink address family of the route, based on the destination
+func (r Route) family() int {
+	if r.Dst.IP.To4() == nil {
+		return netlink.FAMILY_V6
+	}
+	return netlink.FAMILY_V4
+}
+
+// copy returns a deep copy of the route, which does not share the addresses and the next hops with the original
+func (r Route) copy() Route {
+	r.Dst = net.IPNet{IP: slices.Clone(r.Dst.IP), Mask: slices.Clone(r.Dst.Mask)}
+	r.Gw = slices.Clone(r.Gw)
+	r.Src = slices.Clone(r.Src)
+	if r.MultiPath != nil {
+		nextHops := make([]NextHop, len(r.MultiPath))
+		for i, nextHop := range r.MultiPath {
+			nextHops[i] = NextHop{Gw: slices.Clone(nextHop.Gw), Weight: nextHop.Weight}
+		}
+		r.MultiPath = nextHops
+	}
+	return r
+}
+
+// InKernel returns the route as the kernel reports it, the kernel sets the default priority of the IPv6 routes
+func (r Route) InKernel() Route {
+	if r.Priority == 0 && r.family() == netlink.FAMILY_V6 {
+		r.Priority = ip6DefaultPriority
+	}
+	return r
+}
+
+// toNetLinkRoute converts the route and marks it with our protocol ID
+func (r *routeManagerImpl) toNetLinkRoute(route Route) netlink.Route {
+	nlRoute := route.toNetLinkRoute()
+	nlRoute.Protocol = r.protocol
+	return nlRoute
 }
 
 /*
@@ -158,26 +464,57 @@ This version of equal shall be used everywhere in this package.
 	to zero out the fields which we do not store in this package.
 */
 func (r Route) equal(x Route) bool {
-	return r.toNetLinkRoute().Equal(x.toNetLinkRoute())
+	// The kernel reports the output interface of every route, so it is compared only if both routes specify it
+	if r.LinkIndex == 0 || x.LinkIndex == 0 {
+		r.LinkIndex, x.LinkIndex = 0, 0
+	}
+	// netlink compares the hop limit only from the metrics
+	return r.toNetLinkRoute().Equal(x.toNetLinkRoute()) &&
+		r.MTU == x.MTU && r.AdvMSS == x.AdvMSS && r.InitCwnd == x.InitCwnd && r.InitRwnd == x.InitRwnd
+}
+
+// conflicts returns true if the kernel can not hold both routes at the same time
+func (r Route) conflicts(x Route) bool {
+	return r.Table == x.Table && r.Priority == x.Priority && r.Dst.IP.Equal(x.Dst.IP) && bytes.Equal(r.Dst.Mask, x.Dst.Mask)
 }
 
 func fromNetLinkRoute(netlinkRoute netlink.Route) Route {
-	return Route{
-		Dst:   *netlinkRoute.Dst,
-		Gw:    netlinkRoute.Gw,
-		Table: netlinkRoute.Table,
+	route := Route{
+		Dst:       *netlinkRoute.Dst,
+		Gw:        netlinkRoute.Gw,
+		Table:     netlinkRoute.Table,
+		Priority:  netlinkRoute.Priority,
+		LinkIndex: netlinkRoute.LinkIndex,
+		Src:       netlinkRoute.Src,
+		Scope:     int(netlinkRoute.Scope),
+		MTU:       netlinkRoute.MTU,
+		AdvMSS:    netlinkRoute.AdvMSS,
+		InitCwnd:  netlinkRoute.InitCwnd,
+		InitRwnd:  netlinkRoute.InitRwnd,
+		Hoplimit:  netlinkRoute.Hoplimit,
+		// The other flags (i.e. linkdown) are reported by the kernel, they are not part of the route
+		OnLink: netlinkRoute.Flags&int(netlink.FLAG_ONLINK) != 0,
 	}
+	// Unicast is the default, so it is not stored
+	if netlinkRoute.Type != unix.RTN_UNICAST {
+		route.Type = netlinkRoute.Type
+	}
+	for _, nextHop := range netlinkRoute.MultiPath {
+		route.MultiPath = append(route.MultiPath, NextHop{Gw: nextHop.Gw, Weight: nextHop.Hops + 1})
+	}
+	return route
 }
 
 func (r *routeManagerImpl) notifyWatchers(update netlink.RouteUpdate) {
-	if update.Type != unix.RTM_DELROUTE {
+	if update.Type != unix.RTM_DELROUTE || update.Route.Protocol != r.protocol {
 		return
 	}
-	for _, route := range r.managedRoutes {
-		updateRoute := fromNetLinkRoute(update.Route)
-		if route.equal(updateRoute) {
+	updateRoute := fromNetLinkRoute(update.Route)
+	for name, route := range r.managedRoutes {
+		if route.InKernel().equal(updateRoute) {
+			tamperedRoutes.Inc()
 			for _, watcher := range r.watchers {
-				watcher.RouteDeleted(updateRoute)
+				watcher.RouteDeleted(name, updateRoute)
 			}
 			break
 		}
@@ -186,9 +523,16 @@ func (r *routeManagerImpl) notifyWatchers(update netlink.RouteUpdate) {
 
 func (r *routeManagerImpl) Run(stopChan chan struct{}) error {
 	updateChan := make(chan netlink.RouteUpdate)
-	if err := r.nlRouteSubscribeFunc(updateChan, stopChan); err != nil {
+	if err := r.nl.RouteSubscribe(updateChan, stopChan); err != nil {
 		return err
 	}
+	// The channel of a disabled audit is nil, so it never fires
+	var auditTick <-chan time.Time
+	if r.audit.Interval > 0 {
+		ticker := time.NewTicker(r.audit.Interval)
+		defer ticker.Stop()
+		auditTick = ticker.C
+	}
 	for {
 		select {
 		case update, ok := <-updateChan:
@@ -204,8 +548,26 @@ func (r *routeManagerImpl) Run(stopChan chan struct{}) error {
 			r.deRegisterWatcher(watcher)
 		case params := <-r.registerRouteChan:
 			r.registerRoute(params)
+			r.updateManagedRoutesMetric()
+		case params := <-r.updateRouteChan:
+			r.updateRoute(params)
+			r.updateManagedRoutesMetric()
 		case params := <-r.deRegisterRouteChan:
 			r.deRegisterRoute(params)
+			r.updateManagedRoutesMetric()
+		case params := <-r.collectGarbageChan:
+			r.collectGarbage(params)
+			r.updateManagedRoutesMetric()
+		case <-auditTick:
+			// Failing to list the routes is not fatal, the next audit tries again
+			_, _ = r.auditRoutes()
+		case params := <-r.auditChan:
+			drifts, err := r.auditRoutes()
+			params.result <- routeManagerImplAuditResult{drifts, err}
+		case params := <-r.getRouteChan:
+			r.getRoute(params)
+		case params := <-r.listRoutesChan:
+			r.listRoutes(params)
 		}
 	}
 }
@@ -19,43 +19,44 @@ package routemanager
 import (
 	"errors"
 	"net"
-	"reflect"
-	"runtime"
 	"sync"
-	"syscall"
 	"testing"
+	"time"
 
+	"github.com/IBM/staticroute-operator/pkg/routemanager/fake"
 	"github.com/vishvananda/netlink"
 	"golang.org/x/sys/unix"
 )
 
 type MockRouteWatcher struct {
 	routeDeletedCalledWith chan Route
+	routeDeletedNames      chan string
 }
 
-func (m MockRouteWatcher) RouteDeleted(r Route) {
+func (m MockRouteWatcher) RouteDeleted(n string, r Route) {
+	if m.routeDeletedNames != nil {
+		m.routeDeletedNames <- n
+	}
 	m.routeDeletedCalledWith <- r
 }
 
-var gMockUpdateChan chan<- netlink.RouteUpdate
 var gTestRoute = Route{Dst: net.IPNet{IP: net.IP{192, 168, 1, 0}, Mask: net.CIDRMask(24, 32)}, Gw: net.IP{192, 168, 1, 254}, Table: 254}
 var gTestRouteName = "name"
+var gTestProtocol = netlink.RouteProtocol(196)
 
-func mockRouteSubscribe(u chan<- netlink.RouteUpdate, c <-chan struct{}) error {
-	gMockUpdateChan = u
-	return nil
-}
-
-func dummyRouteAdd(route *netlink.Route) error {
-	return nil
-}
+// blank assignment to verify that the fake kernel implements Netlink
+var _ Netlink = &fake.Netlink{}
 
-func dummyRouteDel(route *netlink.Route) error {
-	return nil
+// ownNetLinkRoute returns the route as the kernel reports it after the route manager installed it
+func ownNetLinkRoute(route Route) netlink.Route {
+	nlRoute := route.toNetLinkRoute()
+	nlRoute.Protocol = gTestProtocol
+	return nlRoute
 }
 
 type testableRouteManager struct {
 	rm       RouteManager
+	kernel   *fake.Netlink
 	runError error
 	wg       sync.WaitGroup
 	stopChan chan struct{}
@@ -75,33 +76,68 @@ func (m *testableRouteManager) stop() {
 }
 
 func newTestableRouteManager() testableRouteManager {
+	kernel := fake.NewNetlink()
 	return testableRouteManager{
 		rm: &routeManagerImpl{
 			managedRoutes:         make(map[string]Route),
-			nlRouteSubscribeFunc:  mockRouteSubscribe,
-			nlRouteAddFunc:        dummyRouteAdd,
-			nlRouteDelFunc:        dummyRouteDel,
+			protocol:              gTestProtocol,
+			nl:                    kernel,
 			registerRouteChan:     make(chan routeManagerImplRegisterRouteParams),
+			updateRouteChan:       make(chan routeManagerImplRegisterRouteParams),
 			deRegisterRouteChan:   make(chan routeManagerImplDeRegisterRouteParams),
 			registerWatcherChan:   make(chan RouteWatcher),
 			deRegisterWatcherChan: make(chan RouteWatcher),
+			collectGarbageChan:    make(chan routeManagerImplCollectGarbageParams),
+			auditChan:             make(chan routeManagerImplAuditParams),
+			getRouteChan:          make(chan routeManagerImplGetRouteParams),
+			listRoutesChan:        make(chan routeManagerImplListRoutesParams),
 		},
+		kernel:   kernel,
 		wg:       sync.WaitGroup{},
 		stopChan: make(chan struct{}),
 	}
 }
 
+// kernelRoutes returns the routes of all tables in the fake kernel
+func (m *testableRouteManager) kernelRoutes(t *testing.T) []netlink.Route {
+	routes, err := m.kernel.RouteListFiltered(netlink.FAMILY_ALL, &netlink.Route{Table: unix.RT_TABLE_UNSPEC}, netlink.RT_FILTER_TABLE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return routes
+}
+
+// addKernelRoute installs the route in the fake kernel behind the back of the route manager
+func (m *testableRouteManager) addKernelRoute(t *testing.T, route netlink.Route) {
+	if err := m.kernel.RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// expectKernelRoutes checks that the fake kernel holds exactly the given routes
+func (m *testableRouteManager) expectKernelRoutes(t *testing.T, expected ...netlink.Route) {
+	routes := m.kernelRoutes(t)
+	if len(routes) != len(expected) {
+		t.Errorf("Kernel must hold %d routes: %v", len(expected), routes)
+		return
+	}
+	for i := range expected {
+		if !fromNetLinkRoute(routes[i]).equal(fromNetLinkRoute(expected[i]).InKernel()) || routes[i].Protocol != expected[i].Protocol {
+			t.Errorf("Kernel route %v must be %v", routes[i], expected[i])
+		}
+	}
+}
+
 func TestNewDoesReturnValidManager(t *testing.T) {
-	rm := New()
-	//Pretty intuitive way to check if two function pointers are identical. Thanks for: https://github.com/stretchr/testify/issues/182#issuecomment-495359313
-	if runtime.FuncForPC(reflect.ValueOf(rm.(*routeManagerImpl).nlRouteAddFunc).Pointer()).Name() != runtime.FuncForPC(reflect.ValueOf(netlink.RouteAdd).Pointer()).Name() {
-		t.Error("nlRouteAddFunc function is not pointing to netlink package")
+	rm := New(int(gTestProtocol), AuditOptions{Interval: time.Minute, Policy: RepairRestore})
+	if _, ok := rm.(*routeManagerImpl).nl.(kernelNetlink); !ok {
+		t.Error("nl is not talking to the kernel")
 	}
-	if runtime.FuncForPC(reflect.ValueOf(rm.(*routeManagerImpl).nlRouteDelFunc).Pointer()).Name() != runtime.FuncForPC(reflect.ValueOf(netlink.RouteDel).Pointer()).Name() {
-		t.Error("nlRouteDelFunc function is not pointing to netlink package")
+	if rm.(*routeManagerImpl).protocol != gTestProtocol {
+		t.Error("protocol is not initialized")
 	}
-	if runtime.FuncForPC(reflect.ValueOf(rm.(*routeManagerImpl).nlRouteSubscribeFunc).Pointer()).Name() != runtime.FuncForPC(reflect.ValueOf(netlink.RouteSubscribe).Pointer()).Name() {
-		t.Error("nlRouteSubscribeFunc function is not pointing to netlink package")
+	if rm.(*routeManagerImpl).audit.Interval != time.Minute || rm.(*routeManagerImpl).audit.Policy != RepairRestore {
+		t.Error("audit is not initialized")
 	}
 	if rm.(*routeManagerImpl).registerRouteChan == nil {
 		t.Error("registerRoute channel is not initialized")
@@ -115,6 +151,18 @@ func TestNewDoesReturnValidManager(t *testing.T) {
 	if rm.(*routeManagerImpl).deRegisterWatcherChan == nil {
 		t.Error("deRegisterWatcher channel is not initialized")
 	}
+	if rm.(*routeManagerImpl).collectGarbageChan == nil {
+		t.Error("collectGarbage channel is not initialized")
+	}
+	if rm.(*routeManagerImpl).auditChan == nil {
+		t.Error("audit channel is not initialized")
+	}
+	if rm.(*routeManagerImpl).getRouteChan == nil {
+		t.Error("getRoute channel is not initialized")
+	}
+	if rm.(*routeManagerImpl).listRoutesChan == nil {
+		t.Error("listRoutes channel is not initialized")
+	}
 }
 
 func TestNothingBlocksInRun(t *testing.T) {
@@ -136,9 +184,7 @@ func TestNothingBlocksInRun(t *testing.T) {
 
 func TestRunReturnsSubscribeError(t *testing.T) {
 	testable := newTestableRouteManager()
-	testable.rm.(*routeManagerImpl).nlRouteSubscribeFunc = func(chan<- netlink.RouteUpdate, <-chan struct{}) error {
-		return errors.New("bla")
-	}
+	testable.kernel.Fail("RouteSubscribe", errors.New("bla"))
 	testable.start()
 	testable.stop()
 	if testable.runError == nil {
@@ -152,10 +198,7 @@ func TestWatchNewRouteDoesNotTrigger(t *testing.T) {
 	mockWatcher := MockRouteWatcher{routeDeletedCalledWith: make(chan Route)}
 
 	testable.rm.RegisterWatcher(mockWatcher)
-	if gMockUpdateChan == nil {
-		t.Error("Update channel did not populate")
-	}
-	gMockUpdateChan <- netlink.RouteUpdate{Type: unix.RTM_NEWROUTE, Route: gTestRoute.toNetLinkRoute()}
+	testable.addKernelRoute(t, ownNetLinkRoute(gTestRoute))
 	testable.rm.DeRegisterWatcher(mockWatcher)
 	testable.stop()
 
@@ -168,14 +211,15 @@ func TestWatchNewRouteDoesNotTrigger(t *testing.T) {
 
 func TestWatchDelRouteDoesNotTriggerIfNotWatched(t *testing.T) {
 	testable := newTestableRouteManager()
+	testable.addKernelRoute(t, ownNetLinkRoute(gTestRoute))
 	testable.start()
 	mockWatcher := MockRouteWatcher{routeDeletedCalledWith: make(chan Route)}
 
 	testable.rm.RegisterWatcher(mockWatcher)
-	if gMockUpdateChan == nil {
-		t.Error("Update channel did not populate")
+	nlRoute := ownNetLinkRoute(gTestRoute)
+	if err := testable.kernel.RouteDel(&nlRoute); err != nil {
+		t.Fatal(err)
 	}
-	gMockUpdateChan <- netlink.RouteUpdate{Type: unix.RTM_DELROUTE, Route: gTestRoute.toNetLinkRoute()}
 
 	testable.stop()
 	select {
@@ -190,24 +234,27 @@ func TestWatch(t *testing.T) {
 	testable.start()
 	defer testable.stop()
 
-	mockWatcher := MockRouteWatcher{routeDeletedCalledWith: make(chan Route)}
+	mockWatcher := MockRouteWatcher{routeDeletedCalledWith: make(chan Route), routeDeletedNames: make(chan string, 1)}
 	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
 		t.Error("RegisterRoute shall pass here")
 	}
 	testable.rm.RegisterWatcher(mockWatcher)
 
-	if gMockUpdateChan == nil {
-		t.Error("Update channel did not populate")
+	// Someone deletes the route behind our back
+	if err := testable.kernel.RouteDel(&netlink.Route{Dst: &gTestRoute.Dst}); err != nil {
+		t.Fatal(err)
 	}
-	gMockUpdateChan <- netlink.RouteUpdate{Type: unix.RTM_DELROUTE, Route: gTestRoute.toNetLinkRoute()}
 
 	fromUpdate := <-mockWatcher.routeDeletedCalledWith
+	if name := <-mockWatcher.routeDeletedNames; name != gTestRouteName {
+		t.Errorf("Name of the deleted route must be %s, it is %s", gTestRouteName, name)
+	}
 	if !fromUpdate.toNetLinkRoute().Equal(gTestRoute.toNetLinkRoute()) {
 		t.Error("Route in update event must be the same which we sent in")
 	}
 
 	if err := testable.rm.DeRegisterRoute(gTestRouteName); err != nil {
-		t.Error("RegisterRoute shall pass here")
+		t.Error("DeRegisterRoute shall pass here")
 	}
 	testable.rm.DeRegisterWatcher(mockWatcher)
 }
@@ -219,30 +266,20 @@ func TestWatchCloseUpdateChan(t *testing.T) {
 	mockWatcher := MockRouteWatcher{routeDeletedCalledWith: make(chan Route)}
 	testable.rm.RegisterWatcher(mockWatcher)
 
-	close(gMockUpdateChan)
+	testable.kernel.CloseSubscriptions()
 
 	testable.wg.Wait()
 }
 
 func TestRegisterRouteSuccess(t *testing.T) {
 	testable := newTestableRouteManager()
-	addCalledWith := make(chan *netlink.Route)
-	testable.rm.(*routeManagerImpl).nlRouteAddFunc = func(route *netlink.Route) error {
-		addCalledWith <- route
-		return nil
-	}
 	testable.start()
 
-	go func() {
-		if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
-			t.Error("RegisterRoute shall pass here")
-		}
-	}()
-	addedRoute := <-addCalledWith
-	testable.stop()
-	if !addedRoute.Equal(gTestRoute.toNetLinkRoute()) {
-		t.Error("Route sent to netlink does not match with the original")
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("RegisterRoute shall pass here")
 	}
+	testable.stop()
+	testable.expectKernelRoutes(t, ownNetLinkRoute(gTestRoute))
 	if len(testable.rm.(*routeManagerImpl).managedRoutes) != 1 {
 		t.Error("managedRoute slice must contain one element")
 	} else {
@@ -255,22 +292,13 @@ func TestRegisterRouteSuccess(t *testing.T) {
 
 func TestRegisterRouteFail(t *testing.T) {
 	testable := newTestableRouteManager()
-	addCalledWith := make(chan *netlink.Route)
-	testable.rm.(*routeManagerImpl).nlRouteAddFunc = func(route *netlink.Route) error {
-		addCalledWith <- route
-		return errors.New("bla")
-	}
+	testable.kernel.Fail("RouteAdd", errors.New("bla"))
 	testable.start()
 
-	go func() {
-		if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err == nil {
-			t.Error("RegisterRoute shall fail here")
-		}
-	}()
-	addedRoute := <-addCalledWith
-	if !addedRoute.Equal(gTestRoute.toNetLinkRoute()) {
-		t.Error("Route sent to netlink does not match with the original")
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err == nil {
+		t.Error("RegisterRoute shall fail here")
 	}
+	testable.expectKernelRoutes(t)
 	if len(testable.rm.(*routeManagerImpl).managedRoutes) > 0 {
 		t.Error("managedRoute slice must be empty")
 	}
@@ -300,28 +328,198 @@ func TestSameRegisterRouteTwiceFail(t *testing.T) {
 	testable.stop()
 }
 
-func TestDeRegisterRouteAlreadyDeleted(t *testing.T) {
+func TestRegisterConflictingRouteFail(t *testing.T) {
 	testable := newTestableRouteManager()
-	delCalledWith := make(chan *netlink.Route)
-	testable.rm.(*routeManagerImpl).nlRouteDelFunc = func(route *netlink.Route) error {
-		delCalledWith <- route
-		return errors.New(syscall.ESRCH.Error())
+	testable.start()
+
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("First RegisterRoute must pass")
+	}
+	backup := gTestRoute
+	backup.Gw = net.IP{192, 168, 1, 253}
+	if err := testable.rm.RegisterRoute("backup", backup); err == nil {
+		t.Error("Route with the same destination, table and priority shall fail")
+	}
+	backup.Priority = 100
+	if err := testable.rm.RegisterRoute("backup", backup); err != nil {
+		t.Errorf("Route with different priority must pass: %s", err.Error())
+	}
+	if len(testable.rm.(*routeManagerImpl).managedRoutes) != 2 {
+		t.Error("managedRoute slice must contain both routes")
+	}
+
+	testable.stop()
+}
+
+func TestToNetLinkRouteSetsPriority(t *testing.T) {
+	route := gTestRoute
+	route.Priority = 100
+
+	nlRoute := route.toNetLinkRoute()
+
+	if nlRoute.Priority != 100 {
+		t.Errorf("Priority must be propagated: %d", nlRoute.Priority)
+	}
+	if route.equal(gTestRoute) {
+		t.Error("Routes with different priorities must not be equal")
+	}
+	if !fromNetLinkRoute(nlRoute).equal(route) {
+		t.Error("Priority must be converted back from netlink")
+	}
+}
+
+func TestToNetLinkRouteSetsType(t *testing.T) {
+	route := Route{Dst: gTestRoute.Dst, Table: 254, Type: unix.RTN_BLACKHOLE}
+
+	nlRoute := route.toNetLinkRoute()
+
+	if nlRoute.Type != unix.RTN_BLACKHOLE {
+		t.Errorf("Type must be propagated: %d", nlRoute.Type)
+	}
+	if route.equal(Route{Dst: gTestRoute.Dst, Table: 254}) {
+		t.Error("Routes with different types must not be equal")
+	}
+	if !fromNetLinkRoute(nlRoute).equal(route) {
+		t.Error("Type must be converted back from netlink")
+	}
+}
+
+func TestToNetLinkRouteDefaultsToUnicast(t *testing.T) {
+	nlRoute := gTestRoute.toNetLinkRoute()
+
+	if nlRoute.Type != unix.RTN_UNICAST {
+		t.Errorf("Type must be unicast by default: %d", nlRoute.Type)
+	}
+	if fromNetLinkRoute(nlRoute).Type != 0 {
+		t.Error("Unicast type must be converted back to the default")
+	}
+}
+
+func TestToNetLinkRouteDeviceRoute(t *testing.T) {
+	route := Route{Dst: gTestRoute.Dst, Table: 254, LinkIndex: 3}
+
+	nlRoute := route.toNetLinkRoute()
+
+	if nlRoute.LinkIndex != 3 || nlRoute.Scope != netlink.SCOPE_LINK {
+		t.Errorf("Device route must be on-link: %+v", nlRoute)
+	}
+	if !fromNetLinkRoute(nlRoute).equal(route) {
+		t.Error("Device route must be converted back from netlink")
+	}
+}
+
+func TestToNetLinkRouteGatewayWithDevice(t *testing.T) {
+	route := gTestRoute
+	route.LinkIndex = 3
+
+	nlRoute := route.toNetLinkRoute()
+
+	if nlRoute.LinkIndex != 3 || nlRoute.Scope != netlink.SCOPE_UNIVERSE {
+		t.Errorf("Gateway route must keep the universe scope: %+v", nlRoute)
+	}
+}
+
+func TestToNetLinkRouteSrcScopeOnLink(t *testing.T) {
+	route := gTestRoute
+	route.Src = net.IP{10, 0, 0, 5}
+	route.Scope = unix.RT_SCOPE_LINK
+	route.LinkIndex = 3
+	route.OnLink = true
+
+	nlRoute := route.toNetLinkRoute()
+
+	if !nlRoute.Src.Equal(route.Src) || nlRoute.Scope != netlink.SCOPE_LINK || nlRoute.Flags != int(netlink.FLAG_ONLINK) {
+		t.Errorf("Src, scope and onlink must be propagated: %+v", nlRoute)
+	}
+	if route.equal(gTestRoute) {
+		t.Error("Routes with different source addresses must not be equal")
+	}
+	if !fromNetLinkRoute(nlRoute).equal(route) {
+		t.Error("Src, scope and onlink must be converted back from netlink")
+	}
+}
+
+func TestFromNetLinkRouteIgnoresKernelFlags(t *testing.T) {
+	nlRoute := gTestRoute.toNetLinkRoute()
+	nlRoute.Flags = int(netlink.FLAG_ONLINK) | unix.RTNH_F_LINKDOWN
+
+	route := fromNetLinkRoute(nlRoute)
+
+	if !route.OnLink || route.toNetLinkRoute().Flags != int(netlink.FLAG_ONLINK) {
+		t.Errorf("Only the onlink flag must be kept: %+v", route)
+	}
+}
+
+func TestToNetLinkRouteIPv6HasNoScope(t *testing.T) {
+	route := Route{Dst: net.IPNet{IP: net.ParseIP("fd00:1::"), Mask: net.CIDRMask(64, 128)}, Table: 254, LinkIndex: 3, Scope: unix.RT_SCOPE_LINK}
+
+	nlRoute := route.toNetLinkRoute()
+
+	if nlRoute.Scope != netlink.SCOPE_UNIVERSE {
+		t.Errorf("IPv6 routes must have universe scope: %d", nlRoute.Scope)
+	}
+}
+
+func TestToNetLinkRouteMetrics(t *testing.T) {
+	route := gTestRoute
+	route.MTU = 1400
+	route.AdvMSS = 1360
+	route.InitCwnd = 10
+	route.InitRwnd = 20
+	route.Hoplimit = 32
+
+	nlRoute := route.toNetLinkRoute()
+
+	if nlRoute.MTU != 1400 || nlRoute.AdvMSS != 1360 || nlRoute.InitCwnd != 10 || nlRoute.InitRwnd != 20 || nlRoute.Hoplimit != 32 {
+		t.Errorf("Metrics must be propagated: %+v", nlRoute)
+	}
+	if !fromNetLinkRoute(nlRoute).equal(route) {
+		t.Error("Metrics must be converted back from netlink")
+	}
+	for i, modify := range []func(*Route){
+		func(r *Route) { r.MTU = 1500 },
+		func(r *Route) { r.AdvMSS = 1460 },
+		func(r *Route) { r.InitCwnd = 0 },
+		func(r *Route) { r.InitRwnd = 0 },
+		func(r *Route) { r.Hoplimit = 64 },
+	} {
+		other := route
+		modify(&other)
+		if route.equal(other) {
+			t.Errorf("Routes with different metrics must not be equal at %d", i)
+		}
+	}
+}
+
+func TestEqualLinkIndex(t *testing.T) {
+	withDevice := gTestRoute
+	withDevice.LinkIndex = 3
+	otherDevice := gTestRoute
+	otherDevice.LinkIndex = 4
+
+	if !gTestRoute.equal(withDevice) || !withDevice.equal(gTestRoute) {
+		t.Error("Link index must be ignored if it is not specified")
 	}
+	if withDevice.equal(otherDevice) {
+		t.Error("Routes with different link indexes must not be equal")
+	}
+}
+
+func TestDeRegisterRouteAlreadyDeleted(t *testing.T) {
+	testable := newTestableRouteManager()
 	testable.start()
 	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
 		t.Error("RegisterRoute shall pass here")
 	}
+	nlRoute := ownNetLinkRoute(gTestRoute)
+	if err := testable.kernel.RouteDel(&nlRoute); err != nil {
+		t.Fatal(err)
+	}
 
-	go func() {
-		if err := testable.rm.DeRegisterRoute(gTestRouteName); err != nil {
-			t.Error("DeRegisterRoute shall pass here")
-		}
-	}()
-	deletedRoute := <-delCalledWith
-	testable.stop()
-	if !deletedRoute.Equal(gTestRoute.toNetLinkRoute()) {
-		t.Error("Route sent to netlink does not match with the original")
+	if err := testable.rm.DeRegisterRoute(gTestRouteName); err != nil {
+		t.Error("DeRegisterRoute shall pass here")
 	}
+	testable.stop()
 	if len(testable.rm.(*routeManagerImpl).managedRoutes) > 0 {
 		t.Error("managedRoute slice must be empty")
 	}
@@ -330,25 +528,16 @@ func TestDeRegisterRouteAlreadyDeleted(t *testing.T) {
 
 func TestDeRegisterRouteUnknownError(t *testing.T) {
 	testable := newTestableRouteManager()
-	delCalledWith := make(chan *netlink.Route)
-	testable.rm.(*routeManagerImpl).nlRouteDelFunc = func(route *netlink.Route) error {
-		delCalledWith <- route
-		return errors.New("bla")
-	}
 	testable.start()
 	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
 		t.Error("RegisterRoute shall pass here")
 	}
 
-	go func() {
-		if err := testable.rm.DeRegisterRoute(gTestRouteName); err == nil {
-			t.Error("DeRegisterRoute shall fail here")
-		}
-	}()
-	deletedRoute := <-delCalledWith
-	if !deletedRoute.Equal(gTestRoute.toNetLinkRoute()) {
-		t.Error("Route sent to netlink does not match with the original")
+	testable.kernel.Fail("RouteDel", errors.New("bla"))
+	if err := testable.rm.DeRegisterRoute(gTestRouteName); err == nil {
+		t.Error("DeRegisterRoute shall fail here")
 	}
+	testable.expectKernelRoutes(t, ownNetLinkRoute(gTestRoute))
 	if len(testable.rm.(*routeManagerImpl).managedRoutes) != 1 {
 		t.Error("managedRoute slice must still contain the route, which couldn't be removed due to an unknown error")
 	}
@@ -365,3 +554,423 @@ func TestDeRegisterRouteWhichIsNotRegistered(t *testing.T) {
 	}
 	testable.stop()
 }
+
+func TestToNetLinkRouteSetsFamily(t *testing.T) {
+	if family := gTestRoute.toNetLinkRoute().Family; family != netlink.FAMILY_V4 {
+		t.Errorf("IPv4 route must have FAMILY_V4, it has %d", family)
+	}
+	ipv6Route := Route{Dst: net.IPNet{IP: net.ParseIP("fd00:1::"), Mask: net.CIDRMask(64, 128)}, Gw: net.ParseIP("fd00::1"), Table: 254}
+	if family := ipv6Route.toNetLinkRoute().Family; family != netlink.FAMILY_V6 {
+		t.Errorf("IPv6 route must have FAMILY_V6, it has %d", family)
+	}
+}
+
+func TestWatchIPv6(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.start()
+	defer testable.stop()
+
+	ipv6Route := Route{Dst: net.IPNet{IP: net.ParseIP("fd00:1::"), Mask: net.CIDRMask(64, 128)}, Gw: net.ParseIP("fd00::1"), Table: 254}
+	mockWatcher := MockRouteWatcher{routeDeletedCalledWith: make(chan Route)}
+	if err := testable.rm.RegisterRoute(gTestRouteName, ipv6Route); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+	testable.rm.RegisterWatcher(mockWatcher)
+
+	// The kernel reports the route with the default IPv6 priority
+	if err := testable.kernel.RouteDel(&netlink.Route{Dst: &ipv6Route.Dst}); err != nil {
+		t.Fatal(err)
+	}
+
+	fromUpdate := <-mockWatcher.routeDeletedCalledWith
+	if !fromUpdate.equal(ipv6Route.InKernel()) {
+		t.Error("Route in update event must be the same which we sent in")
+	}
+
+	if err := testable.rm.DeRegisterRoute(gTestRouteName); err != nil {
+		t.Error("DeRegisterRoute shall pass here")
+	}
+	testable.rm.DeRegisterWatcher(mockWatcher)
+}
+
+func TestToNetLinkRouteMultiPath(t *testing.T) {
+	route := Route{
+		Dst:       net.IPNet{IP: net.IP{192, 168, 1, 0}, Mask: net.CIDRMask(24, 32)},
+		Table:     254,
+		MultiPath: []NextHop{{Gw: net.IP{10, 0, 0, 1}}, {Gw: net.IP{10, 0, 0, 2}, Weight: 3}},
+	}
+
+	nlRoute := route.toNetLinkRoute()
+
+	if len(nlRoute.MultiPath) != 2 {
+		t.Fatalf("Netlink route must have 2 next hops: %d", len(nlRoute.MultiPath))
+	}
+	if nlRoute.Gw != nil {
+		t.Error("Gateway must not be set for a multipath route")
+	}
+	if !nlRoute.MultiPath[0].Gw.Equal(net.IP{10, 0, 0, 1}) || nlRoute.MultiPath[0].Hops != 0 {
+		t.Errorf("First next hop is wrong: %s", nlRoute.MultiPath[0])
+	}
+	if !nlRoute.MultiPath[1].Gw.Equal(net.IP{10, 0, 0, 2}) || nlRoute.MultiPath[1].Hops != 2 {
+		t.Errorf("Second next hop is wrong: %s", nlRoute.MultiPath[1])
+	}
+}
+
+func TestMultiPathRouteEqualsKernelRoute(t *testing.T) {
+	route := Route{
+		Dst:       net.IPNet{IP: net.IP{192, 168, 1, 0}, Mask: net.CIDRMask(24, 32)},
+		Table:     254,
+		MultiPath: []NextHop{{Gw: net.IP{10, 0, 0, 1}}, {Gw: net.IP{10, 0, 0, 2}, Weight: 3}},
+	}
+	kernelRoute := route.toNetLinkRoute()
+	// Kernel reports the output interface and flags of the next hops
+	for _, nextHop := range kernelRoute.MultiPath {
+		nextHop.LinkIndex = 2
+		nextHop.Flags = 1
+	}
+
+	if !route.equal(fromNetLinkRoute(kernelRoute)) {
+		t.Error("Route coming back from the kernel must be equal to the managed one")
+	}
+	other := route
+	other.MultiPath = []NextHop{{Gw: net.IP{10, 0, 0, 1}}, {Gw: net.IP{10, 0, 0, 2}}}
+	if route.equal(other) {
+		t.Error("Routes with different weights must not be equal")
+	}
+}
+
+func TestWatchDelRouteOfOtherProtocolDoesNotTrigger(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.start()
+	defer testable.stop()
+
+	mockWatcher := MockRouteWatcher{routeDeletedCalledWith: make(chan Route, 1)}
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+	// Someone takes over the route, so it carries other protocol when it is deleted
+	foreign := gTestRoute.toNetLinkRoute()
+	if err := testable.kernel.RouteReplace(&foreign); err != nil {
+		t.Fatal(err)
+	}
+	testable.rm.RegisterWatcher(mockWatcher)
+
+	if err := testable.kernel.RouteDel(&foreign); err != nil {
+		t.Fatal(err)
+	}
+	testable.rm.DeRegisterWatcher(mockWatcher)
+
+	select {
+	case <-mockWatcher.routeDeletedCalledWith:
+		t.Error("Route of other protocol must not trigger the watchers")
+	default:
+	}
+}
+
+func TestRegisterRouteSetsProtocol(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+
+	if routes := testable.kernelRoutes(t); len(routes) != 1 || routes[0].Protocol != gTestProtocol {
+		t.Errorf("Route must be installed with protocol %d: %v", gTestProtocol, routes)
+	}
+}
+
+func TestRegisterRouteAdoptsOwnExisting(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.addKernelRoute(t, ownNetLinkRoute(gTestRoute))
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Errorf("Own existing route must be adopted: %s", err.Error())
+	}
+	if !testable.rm.IsRegistered(gTestRouteName) {
+		t.Error("Adopted route must be registered")
+	}
+	testable.expectKernelRoutes(t, ownNetLinkRoute(gTestRoute))
+}
+
+func TestRegisterRouteAdoptsOwnExistingIPv6(t *testing.T) {
+	ipv6Route := Route{Dst: net.IPNet{IP: net.ParseIP("fd00:1::"), Mask: net.CIDRMask(64, 128)}, Gw: net.ParseIP("fd00::1"), Table: 254}
+	testable := newTestableRouteManager()
+	testable.addKernelRoute(t, ownNetLinkRoute(ipv6Route))
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRoute(gTestRouteName, ipv6Route); err != nil {
+		t.Errorf("Own existing route with the default IPv6 priority must be adopted: %s", err.Error())
+	}
+}
+
+func TestRegisterRouteReplacesOwnStale(t *testing.T) {
+	testable := newTestableRouteManager()
+	stale := gTestRoute
+	stale.Gw = net.IP{192, 168, 1, 253}
+	testable.addKernelRoute(t, ownNetLinkRoute(stale))
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Errorf("Own stale route must be replaced: %s", err.Error())
+	}
+	testable.expectKernelRoutes(t, ownNetLinkRoute(gTestRoute))
+}
+
+func TestUpdateRouteReplacesInPlace(t *testing.T) {
+	testable := newTestableRouteManager()
+	updated := gTestRoute
+	updated.Gw = net.IP{192, 168, 1, 253}
+	testable.start()
+	defer testable.stop()
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+	updates := make(chan netlink.RouteUpdate, 2)
+	if err := testable.kernel.RouteSubscribe(updates, testable.stopChan); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testable.rm.UpdateRoute(gTestRouteName, updated); err != nil {
+		t.Errorf("UpdateRoute shall pass here: %s", err.Error())
+	}
+	testable.expectKernelRoutes(t, ownNetLinkRoute(updated))
+	if update := <-updates; update.Type != unix.RTM_NEWROUTE {
+		t.Error("Route must not be deleted when it is replaced in place")
+	}
+	if route, err := testable.rm.GetRoute(gTestRouteName); err != nil || !route.equal(updated) {
+		t.Error("Managed route must be updated")
+	}
+}
+
+func TestUpdateRouteAddsBeforeDelete(t *testing.T) {
+	testable := newTestableRouteManager()
+	updated := gTestRoute
+	updated.Table = 42
+	testable.start()
+	defer testable.stop()
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+	updates := make(chan netlink.RouteUpdate, 2)
+	if err := testable.kernel.RouteSubscribe(updates, testable.stopChan); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testable.rm.UpdateRoute(gTestRouteName, updated); err != nil {
+		t.Errorf("UpdateRoute shall pass here: %s", err.Error())
+	}
+	operations := []netlink.RouteUpdate{<-updates, <-updates}
+	if operations[0].Type != unix.RTM_NEWROUTE || operations[0].Table != 42 || operations[1].Type != unix.RTM_DELROUTE || operations[1].Table != gTestRoute.Table {
+		t.Errorf("New version must be added before the old one is deleted: %v", operations)
+	}
+	testable.expectKernelRoutes(t, ownNetLinkRoute(updated))
+	if route, err := testable.rm.GetRoute(gTestRouteName); err != nil || route.Table != 42 {
+		t.Error("Managed route must be updated")
+	}
+}
+
+func TestUpdateRouteFail(t *testing.T) {
+	testable := newTestableRouteManager()
+	updated := gTestRoute
+	updated.Gw = net.IP{192, 168, 1, 253}
+	testable.start()
+	defer testable.stop()
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+
+	testable.kernel.Fail("RouteReplace", errors.New("bla"))
+	if err := testable.rm.UpdateRoute(gTestRouteName, updated); err == nil {
+		t.Error("UpdateRoute shall fail here")
+	}
+	if route, err := testable.rm.GetRoute(gTestRouteName); err != nil || !route.equal(gTestRoute) {
+		t.Error("Managed route must not be changed")
+	}
+	testable.expectKernelRoutes(t, ownNetLinkRoute(gTestRoute))
+}
+
+func TestUpdateRouteNotRegistered(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.UpdateRoute(gTestRouteName, gTestRoute); err != ErrNotFound {
+		t.Errorf("UpdateRoute must return ErrNotFound: %v", err)
+	}
+}
+
+func TestUpdateRouteConflictsWithOther(t *testing.T) {
+	testable := newTestableRouteManager()
+	other := gTestRoute
+	other.Table = 42
+	testable.rm.(*routeManagerImpl).managedRoutes[gTestRouteName] = gTestRoute
+	testable.rm.(*routeManagerImpl).managedRoutes["other"] = other
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.UpdateRoute(gTestRouteName, other); err == nil {
+		t.Error("UpdateRoute must fail if the new version conflicts with another managed route")
+	}
+}
+
+func TestRegisterRouteForeignExistingFail(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.addKernelRoute(t, foreignNetLinkRoute(gTestRoute))
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err == nil {
+		t.Error("Route installed by someone else must not be adopted")
+	}
+	if testable.rm.IsRegistered(gTestRouteName) {
+		t.Error("Route must not be registered")
+	}
+	if routes := testable.kernelRoutes(t); len(routes) != 1 || routes[0].Protocol != unix.RTPROT_BOOT {
+		t.Errorf("Route of someone else must be left alone: %v", routes)
+	}
+}
+
+func TestCollectGarbage(t *testing.T) {
+	testable := newTestableRouteManager()
+	orphan := Route{Dst: net.IPNet{IP: net.IP{192, 168, 2, 0}, Mask: net.CIDRMask(24, 32)}, Gw: net.IP{192, 168, 1, 254}, Table: 254}
+	adoptable := Route{Dst: net.IPNet{IP: net.IP{192, 168, 3, 0}, Mask: net.CIDRMask(24, 32)}, Gw: net.IP{192, 168, 1, 254}, Table: 254}
+	foreign := Route{Dst: net.IPNet{IP: net.IP{192, 168, 4, 0}, Mask: net.CIDRMask(24, 32)}, Gw: net.IP{192, 168, 1, 254}, Table: 254}
+	testable.addKernelRoute(t, ownNetLinkRoute(orphan))
+	testable.addKernelRoute(t, ownNetLinkRoute(adoptable))
+	testable.addKernelRoute(t, foreignNetLinkRoute(foreign))
+	testable.start()
+	defer testable.stop()
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+
+	err := testable.rm.CollectGarbage(func(r Route) (string, bool) {
+		if r.equal(adoptable) {
+			return "adopted", true
+		}
+		return "", false
+	})
+
+	if err != nil {
+		t.Errorf("CollectGarbage shall pass here: %s", err.Error())
+	}
+	testable.expectKernelRoutes(t, ownNetLinkRoute(adoptable), foreignNetLinkRoute(foreign), ownNetLinkRoute(gTestRoute))
+	if !testable.rm.IsRegistered("adopted") || !testable.rm.IsRegistered(gTestRouteName) {
+		t.Error("Managed and adopted routes must be registered")
+	}
+}
+
+func TestCollectGarbageListFails(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.kernel.Fail("RouteListFiltered", errors.New("bla"))
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.CollectGarbage(func(Route) (string, bool) { return "", false }); err == nil {
+		t.Error("CollectGarbage shall fail here")
+	}
+}
+
+func TestCollectGarbageDeleteFails(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.addKernelRoute(t, ownNetLinkRoute(gTestRoute))
+	testable.kernel.Fail("RouteDel", errors.New("bla"))
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.CollectGarbage(func(Route) (string, bool) { return "", false }); err == nil {
+		t.Error("CollectGarbage shall fail here")
+	}
+}
+
+func TestGetRoute(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.start()
+	defer testable.stop()
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+
+	route, err := testable.rm.GetRoute(gTestRouteName)
+
+	if err != nil || !route.equal(gTestRoute) {
+		t.Errorf("Managed route must be returned: %v %v", route, err)
+	}
+	route.Gw[3] = 1
+	route.Dst.IP[2] = 2
+	if managed, _ := testable.rm.GetRoute(gTestRouteName); !managed.equal(gTestRoute) {
+		t.Errorf("Managed route must not be changed through the returned copy: %v", managed)
+	}
+	if _, err := testable.rm.GetRoute("unknown"); err != ErrNotFound {
+		t.Errorf("GetRoute must return ErrNotFound: %v", err)
+	}
+}
+
+func TestListRoutes(t *testing.T) {
+	testable := newTestableRouteManager()
+	multiPath := Route{
+		Dst:       net.IPNet{IP: net.IP{192, 168, 2, 0}, Mask: net.CIDRMask(24, 32)},
+		Table:     254,
+		MultiPath: []NextHop{{Gw: net.IP{10, 0, 0, 1}}, {Gw: net.IP{10, 0, 0, 2}, Weight: 3}},
+	}
+	testable.start()
+	defer testable.stop()
+	if routes := testable.rm.ListRoutes(); len(routes) != 0 {
+		t.Errorf("No routes must be listed: %v", routes)
+	}
+	if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+	if err := testable.rm.RegisterRoute("multipath", multiPath); err != nil {
+		t.Error("RegisterRoute shall pass here")
+	}
+
+	routes := testable.rm.ListRoutes()
+
+	if len(routes) != 2 || !routes[gTestRouteName].equal(gTestRoute) || !routes["multipath"].equal(multiPath) {
+		t.Errorf("Managed routes must be listed: %v", routes)
+	}
+	routes["multipath"].MultiPath[0].Gw[3] = 5
+	delete(routes, gTestRouteName)
+	if managed := testable.rm.ListRoutes(); len(managed) != 2 || !managed["multipath"].equal(multiPath) {
+		t.Errorf("Managed routes must not be changed through the returned copy: %v", managed)
+	}
+}
+
+// TestQueriesDuringChanges is meant to be run with the race detector
+func TestQueriesDuringChanges(t *testing.T) {
+	testable := newTestableRouteManager()
+	testable.start()
+	defer testable.stop()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			if err := testable.rm.RegisterRoute(gTestRouteName, gTestRoute); err != nil {
+				t.Errorf("RegisterRoute shall pass here: %v", err)
+			}
+			if err := testable.rm.DeRegisterRoute(gTestRouteName); err != nil {
+				t.Errorf("DeRegisterRoute shall pass here: %v", err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			testable.rm.IsRegistered(gTestRouteName)
+			for _, route := range testable.rm.ListRoutes() {
+				if !route.equal(gTestRoute) {
+					t.Errorf("Only the test route must be listed: %v", route)
+				}
+			}
+		}
+	}()
+	wg.Wait()
+}
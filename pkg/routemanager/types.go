@@ -18,6 +18,7 @@ package routemanager
 
 import (
 	"net"
+	"time"
 )
 
 // Route structure represents just-enough data to manage IP routes from user code
@@ -25,21 +26,120 @@ type Route struct {
 	Dst   net.IPNet
 	Gw    net.IP
 	Table int
+	// Priority is the metric of the route, routes with the same destination and different priorities can coexist
+	Priority int
+	// MultiPath holds the next hops of an ECMP route, Gw shall be nil if it is set
+	MultiPath []NextHop
+	// LinkIndex is the index of the output interface (optional). A unicast route with an interface and without
+	// gateway is an on-link device route.
+	LinkIndex int
+	// Src is the preferred source address of the packets sent to the destination (optional)
+	Src net.IP
+	// Scope is the kernel route scope (unix.RT_SCOPE_*). 0 is universe, except for device routes which are link
+	// scoped by default.
+	Scope int
+	// OnLink pretends that the gateway is directly attached to the link, LinkIndex must be set
+	OnLink bool
+	// MTU, AdvMSS, InitCwnd, InitRwnd and Hoplimit are the metrics (RTA_METRICS) of the route, 0 means not set
+	MTU      int
+	AdvMSS   int
+	InitCwnd int
+	InitRwnd int
+	Hoplimit int
+	// Type is the kernel route type (unix.RTN_*), 0 is handled as unicast. Only unicast routes have gateways.
+	Type int
 }
 
-// RouteWatcher is a user-implemented interface, where RouteManager will call back if a managed route is damaged
+// NextHop is one of the gateways of a multipath route
+type NextHop struct {
+	Gw net.IP
+	// Weight of the next hop, 0 is handled as 1
+	Weight int
+}
+
+// RouteWatcher is a user-implemented interface, where RouteManager will call back if a managed route is damaged.
+// The callbacks are executed in the event loop of the RouteManager, so they must not call the RouteManager synchronously.
 type RouteWatcher interface {
-	RouteDeleted(Route)
+	//RouteDeleted is called with the name and the content of the managed route, which was deleted by an external entity
+	RouteDeleted(string, Route)
+}
+
+// DriftType is the kind of difference between a managed route and the kernel routing table
+type DriftType string
+
+const (
+	// DriftMissing the managed route is not in the kernel
+	DriftMissing DriftType = "missing"
+	// DriftModified the kernel holds a different route (or a route of someone else) with the same destination,
+	// table and priority
+	DriftModified DriftType = "modified"
+	// DriftShadowed a route of someone else with the same destination and table, but a lower priority value
+	// takes precedence over the managed route
+	DriftShadowed DriftType = "shadowed"
+)
+
+// Drift is a difference between a managed route and the kernel routing table, found by the audit
+type Drift struct {
+	Type DriftType
+	// Route is the managed route
+	Route Route
+	// Kernel is the modified or the shadowing route found in the kernel, nil if the route is missing
+	Kernel *Route
+	// Repaired is true if the RouteManager restored the managed route according to the RepairPolicy
+	Repaired bool
+	// Err is the error of the repair, if it failed
+	Err error
+}
+
+// RepairPolicy tells the audit how to handle the drifts
+type RepairPolicy string
+
+const (
+	// RepairNone only reports the drifts
+	RepairNone RepairPolicy = "none"
+	// RepairRestore re-creates the missing routes and restores the modified ones, the shadowing routes are only reported
+	RepairRestore RepairPolicy = "restore"
+	// RepairEnforce restores the managed routes and removes the shadowing routes of others as well
+	RepairEnforce RepairPolicy = "enforce"
+)
+
+// AuditOptions configures the periodic comparison of the managed routes with the kernel routing tables
+type AuditOptions struct {
+	// Interval of the audit, 0 disables the periodic audit
+	Interval time.Duration
+	// Policy of repairing the drifts, RepairNone if empty
+	Policy RepairPolicy
+}
+
+// DriftWatcher can be implemented by a RouteWatcher to be notified about the drifts found by the audit.
+// The callbacks are executed in the event loop of the RouteManager, so they must not call the RouteManager synchronously.
+type DriftWatcher interface {
+	//RouteDrifted is called with the name of the managed route and the drift found by the audit
+	RouteDrifted(string, Drift)
 }
 
-// RouteManager is the main interface, which is implemented by the package
+// RouteManager is the main interface, which is implemented by the package.
+// Every method except Run is served by the event loop: it blocks until Run is started,
+// and it deadlocks if called from a RouteWatcher or DriftWatcher callback, which are executed by the loop itself.
 type RouteManager interface {
-	//IsRegistered returns true if a Route (by it's name) is already managed
+	//IsRegistered returns true if a Route (by it's name) is already managed. Blocks until Run is started.
 	IsRegistered(string) bool
+	//GetRoute returns a copy of the managed route with the name, or ErrNotFound. Blocks until Run is started.
+	GetRoute(string) (Route, error)
+	//ListRoutes returns a copy of the managed routes by their names. Blocks until Run is started.
+	ListRoutes() map[string]Route
 	//RegisterRoute creates and start watching the route. If the route is deleted after the registration, RouteWatchers will be notified.
 	RegisterRoute(string, Route) error
+	//UpdateRoute replaces a registered route with its new version, without a moment when neither of them is in the kernel.
+	UpdateRoute(string, Route) error
 	//DeRegisterRoute removed the route from the kernel and also stop watching it.
 	DeRegisterRoute(string) error
+	//CollectGarbage removes the routes from the kernel which carry the protocol ID of the RouteManager, but are not managed.
+	//The adopt callback can return a name to register such a route under, instead of removing it.
+	CollectGarbage(func(Route) (string, bool)) error
+	//Audit compares the managed routes with the kernel routing tables, repairs the drifts according to the RepairPolicy
+	//and returns them. The drifts are reported to the watchers implementing DriftWatcher as well.
+	Audit() ([]Drift, error)
 	//RegisterWatcher registers a new RouteWatcher, which will be notified if the managed routes are deleted.
 	RegisterWatcher(RouteWatcher)
 	//DeRegisterWatcher removes watchers
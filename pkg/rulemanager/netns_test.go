@@ -0,0 +1,97 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package rulemanager
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/IBM/staticroute-operator/pkg/routemanager"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+// newTestNamespace creates a throwaway network namespace. The test is skipped without the privileges to create it.
+func newTestNamespace(t *testing.T) (netns.NsHandle, *netlink.Handle) {
+	runtime.LockOSThread()
+	origin, err := netns.Get()
+	if err != nil {
+		runtime.UnlockOSThread()
+		t.Skipf("Network namespace of the test is not available: %v", err)
+	}
+	defer origin.Close()
+	// New switches the thread into the new namespace
+	namespace, err := netns.New()
+	if err != nil {
+		runtime.UnlockOSThread()
+		t.Skipf("Network namespace can not be created: %v", err)
+	}
+	if err := netns.Set(origin); err != nil {
+		// The thread stays locked, so it is terminated with the goroutine of the test
+		t.Fatalf("Network namespace of the test can not be restored: %v", err)
+	}
+	runtime.UnlockOSThread()
+	t.Cleanup(func() { namespace.Close() })
+
+	handle, err := netlink.NewHandleAt(namespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(handle.Close)
+	return namespace, handle
+}
+
+func TestRuleManagerInNamespace(t *testing.T) {
+	namespace, handle := newTestNamespace(t)
+	nl, err := routemanager.NewNetlinkAt(namespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testable := &testableRuleManager{rm: NewWithNetlink(nl, int(gTestProtocol)), stopChan: make(chan struct{})}
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Fatalf("RegisterRule shall pass here: %v", err)
+	}
+	if rules, err := handle.RuleList(netlink.FAMILY_V4); err != nil || !containsRule(rules, gTestRule) {
+		t.Errorf("Rule must be installed in the namespace: %v %v", rules, err)
+	}
+	if rules, err := netlink.RuleList(netlink.FAMILY_V4); err != nil || containsRule(rules, gTestRule) {
+		t.Errorf("Rule must not be installed in the namespace of the process: %v %v", rules, err)
+	}
+
+	mockWatcher := MockRuleWatcher{ruleDeletedCalledWith: make(chan Rule, 1)}
+	testable.rm.RegisterWatcher(mockWatcher)
+	if err := handle.RuleDel(gTestRule.toNetLinkRule()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case deleted := <-mockWatcher.ruleDeletedCalledWith:
+		if !deleted.Equal(gTestRule) {
+			t.Errorf("Deleted rule must be reported: %v", deleted)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Deletion in the namespace must be reported to the watchers")
+	}
+	testable.rm.DeRegisterWatcher(mockWatcher)
+
+	if err := testable.rm.DeRegisterRule(gTestRuleName); err != nil {
+		t.Errorf("DeRegisterRule shall pass here: %v", err)
+	}
+}
@@ -0,0 +1,407 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package rulemanager
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"syscall"
+
+	"github.com/IBM/staticroute-operator/pkg/routemanager"
+	"github.com/vishvananda/netlink"
+)
+
+var (
+	//NotFoundError rule not found error
+	ErrNotFound = errors.New("Rule could not found")
+)
+
+type ruleManagerImpl struct {
+	managedRules map[string]Rule
+	// notifiedRules are the managed rules already reported missing to the watchers, they are not reported again
+	// until they are registered again or show up in the kernel
+	notifiedRules         map[string]bool
+	watchers              []RuleWatcher
+	protocol              uint8
+	nl                    routemanager.Netlink
+	registerRuleChan      chan ruleManagerImplRegisterRuleParams
+	deRegisterRuleChan    chan ruleManagerImplDeRegisterRuleParams
+	registerWatcherChan   chan RuleWatcher
+	deRegisterWatcherChan chan RuleWatcher
+	isRegisteredChan      chan ruleManagerImplIsRegisteredParams
+	collectGarbageChan    chan ruleManagerImplCollectGarbageParams
+}
+
+type ruleManagerImplRegisterRuleParams struct {
+	name string
+	rule Rule
+	err  chan<- error
+}
+
+type ruleManagerImplDeRegisterRuleParams struct {
+	name string
+	err  chan<- error
+}
+
+type ruleManagerImplIsRegisteredParams struct {
+	name   string
+	result chan<- bool
+}
+
+type ruleManagerImplCollectGarbageParams struct {
+	adopt func(Rule) (string, bool)
+	err   chan<- error
+}
+
+// New creates a RuleManager for production use, which manages the rules of the network namespace of the process.
+// The rules are installed with the given protocol ID (FRA_PROTOCOL), which identifies them as owned by the RuleManager.
+func New(protocol int) RuleManager {
+	return NewWithNetlink(routemanager.NewNetlink(), protocol)
+}
+
+// NewWithNetlink creates a RuleManager which manages the rules through the given Netlink, i.e. the one of another
+// network namespace or a fake kernel in tests
+func NewWithNetlink(nl routemanager.Netlink, protocol int) RuleManager {
+	return &ruleManagerImpl{
+		managedRules:          make(map[string]Rule),
+		notifiedRules:         make(map[string]bool),
+		protocol:              uint8(protocol),
+		nl:                    nl,
+		registerRuleChan:      make(chan ruleManagerImplRegisterRuleParams),
+		deRegisterRuleChan:    make(chan ruleManagerImplDeRegisterRuleParams),
+		registerWatcherChan:   make(chan RuleWatcher),
+		deRegisterWatcherChan: make(chan RuleWatcher),
+		isRegisteredChan:      make(chan ruleManagerImplIsRegisteredParams),
+		collectGarbageChan:    make(chan ruleManagerImplCollectGarbageParams),
+	}
+}
+
+func (r *ruleManagerImpl) RegisterRule(name string, rule Rule) error {
+	errChan := make(chan error)
+	r.registerRuleChan <- ruleManagerImplRegisterRuleParams{name, rule, errChan}
+	return <-errChan
+}
+
+func (r *ruleManagerImpl) IsRegistered(name string) bool {
+	resultChan := make(chan bool)
+	r.isRegisteredChan <- ruleManagerImplIsRegisteredParams{name, resultChan}
+	return <-resultChan
+}
+
+// isRegistered is the version of IsRegistered for the event loop, which owns the managed rules
+func (r *ruleManagerImpl) isRegistered(name string) bool {
+	_, exists := r.managedRules[name]
+	return exists
+}
+
+func (r *ruleManagerImpl) registerRule(params ruleManagerImplRegisterRuleParams) {
+	if r.isRegistered(params.name) {
+		params.err <- errors.New("Rule with the same Name already registered")
+		return
+	}
+	for name, rule := range r.managedRules {
+		if rule.Equal(params.rule) {
+			params.err <- fmt.Errorf("Rule with the same selectors, priority and table already registered by %s", name)
+			return
+		}
+	}
+	nlRule := r.toNetLinkRule(params.rule)
+	/* If syscall returns EEXIST (file exists), it means the rule already existing.
+	   If it carries our protocol ID, we created it before a crash and start managing it again,
+	   otherwise it belongs to someone else. */
+	if err := r.nl.RuleAdd(nlRule); err != nil && syscall.EEXIST.Error() != err.Error() {
+		params.err <- err
+		return
+	} else if err != nil {
+		if err = r.adoptExisting(params.rule); err != nil {
+			params.err <- err
+			return
+		}
+	}
+	r.managedRules[params.name] = params.rule
+	delete(r.notifiedRules, params.name)
+	params.err <- nil
+}
+
+// adoptExisting checks whether the rule already in the kernel was installed by us
+func (r *ruleManagerImpl) adoptExisting(rule Rule) error {
+	existing, err := r.listOwnRules()
+	if err != nil {
+		return err
+	}
+	for _, nlRule := range existing {
+		if fromNetLinkRule(nlRule).Equal(rule) {
+			return nil
+		}
+	}
+	return fmt.Errorf("Rule with priority %d to table %d already exists, but it was not installed by the operator", rule.Priority, rule.Table)
+}
+
+// listOwnRules returns the rules of both families which carry our protocol ID
+func (r *ruleManagerImpl) listOwnRules() ([]netlink.Rule, error) {
+	rules, err := r.nl.RuleList(netlink.FAMILY_ALL)
+	if err != nil {
+		return nil, err
+	}
+	own := []netlink.Rule{}
+	for _, rule := range rules {
+		if rule.Protocol == r.protocol {
+			own = append(own, rule)
+		}
+	}
+	return own, nil
+}
+
+func (r *ruleManagerImpl) DeRegisterRule(name string) error {
+	errChan := make(chan error)
+	r.deRegisterRuleChan <- ruleManagerImplDeRegisterRuleParams{name, errChan}
+	return <-errChan
+}
+
+func (r *ruleManagerImpl) deRegisterRule(params ruleManagerImplDeRegisterRuleParams) {
+	item, found := r.managedRules[params.name]
+	if !found {
+		params.err <- ErrNotFound
+		return
+	}
+	/* We remove the rule from the managed ones, regardless of the ENOENT (no such file or directory) error from the lower layer.
+	   Error supposed to happen only when the rule is already missing, which was reported to the watchers, so they know. */
+	if err := r.nl.RuleDel(r.toNetLinkRule(item)); err != nil && syscall.ENOENT.Error() != err.Error() {
+		params.err <- err
+		return
+	}
+	delete(r.managedRules, params.name)
+	delete(r.notifiedRules, params.name)
+	params.err <- nil
+}
+
+func (r *ruleManagerImpl) CollectGarbage(adopt func(Rule) (string, bool)) error {
+	errChan := make(chan error)
+	r.collectGarbageChan <- ruleManagerImplCollectGarbageParams{adopt, errChan}
+	return <-errChan
+}
+
+func (r *ruleManagerImpl) collectGarbage(params ruleManagerImplCollectGarbageParams) {
+	existing, err := r.listOwnRules()
+	if err != nil {
+		params.err <- err
+		return
+	}
+	var firstErr error
+	for i := range existing {
+		rule := fromNetLinkRule(existing[i])
+		if r.isManaged(rule) {
+			continue
+		}
+		if name, ok := params.adopt(rule); ok && !r.isRegistered(name) {
+			r.managedRules[name] = rule
+			continue
+		}
+		if err := r.nl.RuleDel(&existing[i]); err != nil && syscall.ENOENT.Error() != err.Error() && firstErr == nil {
+			firstErr = err
+		}
+	}
+	params.err <- firstErr
+}
+
+func (r *ruleManagerImpl) isManaged(rule Rule) bool {
+	for _, managed := range r.managedRules {
+		if managed.Equal(rule) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ruleManagerImpl) RegisterWatcher(w RuleWatcher) {
+	r.registerWatcherChan <- w
+}
+
+func (r *ruleManagerImpl) registerWatcher(w RuleWatcher) {
+	r.watchers = append(r.watchers, w)
+}
+
+func (r *ruleManagerImpl) DeRegisterWatcher(w RuleWatcher) {
+	r.deRegisterWatcherChan <- w
+}
+
+func (r *ruleManagerImpl) deRegisterWatcher(w RuleWatcher) {
+	for index, item := range r.watchers {
+		if reflect.DeepEqual(item, w) {
+			r.watchers = append(r.watchers[:index], r.watchers[index+1:]...)
+			break
+		}
+	}
+}
+
+func (r Rule) toNetLinkRule() *netlink.Rule {
+	nlRule := netlink.NewRule()
+	nlRule.Src = r.Src
+	nlRule.Dst = r.Dst
+	nlRule.Mark = r.Mark
+	nlRule.Mask = r.Mask
+	nlRule.IifName = r.IifName
+	nlRule.OifName = r.OifName
+	nlRule.Priority = r.Priority
+	nlRule.Table = r.Table
+	nlRule.Family = r.family()
+	return nlRule
+}
+
+// family returns the netlink address family of the rule, based on the selectors
+func (r Rule) family() int {
+	for _, subnet := range []*net.IPNet{r.Src, r.Dst} {
+		if subnet == nil {
+			continue
+		}
+		if subnet.IP.To4() == nil {
+			return netlink.FAMILY_V6
+		}
+		return netlink.FAMILY_V4
+	}
+	if r.Family == netlink.FAMILY_V6 {
+		return netlink.FAMILY_V6
+	}
+	return netlink.FAMILY_V4
+}
+
+// toNetLinkRule converts the rule and marks it with our protocol ID
+func (r *ruleManagerImpl) toNetLinkRule(rule Rule) *netlink.Rule {
+	nlRule := rule.toNetLinkRule()
+	nlRule.Protocol = r.protocol
+	return nlRule
+}
+
+// Equal returns true if the rules match the same packets with the same priority and send them to the same table
+func (r Rule) Equal(x Rule) bool {
+	return subnetEqual(r.Src, x.Src) && subnetEqual(r.Dst, x.Dst) && r.Mark == x.Mark && maskEqual(r.Mask, x.Mask) &&
+		r.IifName == x.IifName && r.OifName == x.OifName && r.Priority == x.Priority && r.Table == x.Table && r.family() == x.family()
+}
+
+func subnetEqual(a, b *net.IPNet) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.String() == b.String()
+}
+
+// maskEqual handles the missing mask as all bits, the kernel does the same if only the mark is set
+func maskEqual(a, b *uint32) bool {
+	allBits := ^uint32(0)
+	if a == nil {
+		a = &allBits
+	}
+	if b == nil {
+		b = &allBits
+	}
+	return *a == *b
+}
+
+func fromNetLinkRule(nlRule netlink.Rule) Rule {
+	return Rule{
+		Src:      nlRule.Src,
+		Dst:      nlRule.Dst,
+		Mark:     nlRule.Mark,
+		Mask:     nlRule.Mask,
+		IifName:  nlRule.IifName,
+		OifName:  nlRule.OifName,
+		Priority: nlRule.Priority,
+		Table:    nlRule.Table,
+		Family:   nlRule.Family,
+	}
+}
+
+// notifyWatchers looks up the managed rules which are missing from the kernel after a rule got deleted. A missing rule
+// is reported only once, the watchers re-create it by registering it again.
+func (r *ruleManagerImpl) notifyWatchers() {
+	if len(r.managedRules) == 0 {
+		return
+	}
+	existing, err := r.listOwnRules()
+	if err != nil {
+		return
+	}
+	for name, rule := range r.managedRules {
+		if containsRule(existing, rule) {
+			delete(r.notifiedRules, name)
+			continue
+		}
+		if r.notifiedRules[name] {
+			continue
+		}
+		r.notifiedRules[name] = true
+		for _, watcher := range r.watchers {
+			watcher.RuleDeleted(name, rule)
+		}
+	}
+}
+
+// drainSignals consumes the pending signals without blocking, so one listing of the rules serves all of them.
+// Returns false if the channel got closed.
+func drainSignals(updateChan <-chan struct{}) bool {
+	for {
+		select {
+		case _, ok := <-updateChan:
+			if !ok {
+				return false
+			}
+		default:
+			return true
+		}
+	}
+}
+
+func containsRule(nlRules []netlink.Rule, rule Rule) bool {
+	for _, nlRule := range nlRules {
+		if fromNetLinkRule(nlRule).Equal(rule) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ruleManagerImpl) Run(stopChan chan struct{}) error {
+	updateChan := make(chan struct{})
+	if err := r.nl.RuleSubscribe(updateChan, stopChan); err != nil {
+		return err
+	}
+	for {
+		select {
+		case _, ok := <-updateChan:
+			if !ok || !drainSignals(updateChan) {
+				return nil
+			}
+			r.notifyWatchers()
+		case <-stopChan:
+			return nil
+		case watcher := <-r.registerWatcherChan:
+			r.registerWatcher(watcher)
+		case watcher := <-r.deRegisterWatcherChan:
+			r.deRegisterWatcher(watcher)
+		case params := <-r.registerRuleChan:
+			r.registerRule(params)
+		case params := <-r.deRegisterRuleChan:
+			r.deRegisterRule(params)
+		case params := <-r.isRegisteredChan:
+			params.result <- r.isRegistered(params.name)
+		case params := <-r.collectGarbageChan:
+			r.collectGarbage(params)
+		}
+	}
+}
@@ -0,0 +1,571 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package rulemanager
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/staticroute-operator/pkg/routemanager"
+	"github.com/IBM/staticroute-operator/pkg/routemanager/fake"
+	"github.com/vishvananda/netlink"
+)
+
+type MockRuleWatcher struct {
+	ruleDeletedCalledWith chan Rule
+	ruleDeletedNames      chan string
+}
+
+func (m MockRuleWatcher) RuleDeleted(n string, r Rule) {
+	if m.ruleDeletedNames != nil {
+		m.ruleDeletedNames <- n
+	}
+	m.ruleDeletedCalledWith <- r
+}
+
+var gTestRule = Rule{Src: &net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)}, Mark: 100, Priority: 1000, Table: 100}
+var gTestRuleName = "name"
+var gTestProtocol = uint8(196)
+
+// ownNetLinkRule returns the rule as the kernel reports it after the rule manager installed it
+func ownNetLinkRule(rule Rule) netlink.Rule {
+	nlRule := *rule.toNetLinkRule()
+	nlRule.Protocol = gTestProtocol
+	if nlRule.Mark != 0 && nlRule.Mask == nil {
+		allBits := ^uint32(0)
+		nlRule.Mask = &allBits
+	}
+	return nlRule
+}
+
+type testableRuleManager struct {
+	rm       RuleManager
+	kernel   *fake.Netlink
+	runError error
+	wg       sync.WaitGroup
+	stopChan chan struct{}
+}
+
+func (m *testableRuleManager) start() {
+	m.wg.Add(1)
+	go func() {
+		m.runError = m.rm.Run(m.stopChan)
+		m.wg.Done()
+	}()
+}
+
+func (m *testableRuleManager) stop() {
+	close(m.stopChan)
+	m.wg.Wait()
+}
+
+func newTestableRuleManager() *testableRuleManager {
+	kernel := fake.NewNetlink()
+	return &testableRuleManager{
+		rm:       NewWithNetlink(kernel, int(gTestProtocol)),
+		kernel:   kernel,
+		wg:       sync.WaitGroup{},
+		stopChan: make(chan struct{}),
+	}
+}
+
+// addKernelRule installs the rule into the fake kernel behind the back of the rule manager
+func (m *testableRuleManager) addKernelRule(t *testing.T, rule netlink.Rule) {
+	if err := m.kernel.RuleAdd(&rule); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// deleteKernelRule deletes the rule from the fake kernel behind the back of the rule manager
+func (m *testableRuleManager) deleteKernelRule(t *testing.T, rule netlink.Rule) {
+	if err := m.kernel.RuleDel(&rule); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ownKernelRules returns the rules in the fake kernel which carry the protocol ID of the tests
+func (m *testableRuleManager) ownKernelRules(t *testing.T) []netlink.Rule {
+	rules, err := m.kernel.RuleList(netlink.FAMILY_ALL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	own := []netlink.Rule{}
+	for _, rule := range rules {
+		if rule.Protocol == gTestProtocol {
+			own = append(own, rule)
+		}
+	}
+	return own
+}
+
+func TestNewDoesReturnValidManager(t *testing.T) {
+	rm := New(int(gTestProtocol)).(*ruleManagerImpl)
+	if reflect.TypeOf(rm.nl) != reflect.TypeOf(routemanager.NewNetlink()) {
+		t.Errorf("Netlink of the process namespace is expected, got %T", rm.nl)
+	}
+	if rm.protocol != gTestProtocol {
+		t.Error("protocol is not initialized")
+	}
+	if rm.registerRuleChan == nil || rm.deRegisterRuleChan == nil || rm.registerWatcherChan == nil || rm.deRegisterWatcherChan == nil {
+		t.Error("channels are not initialized")
+	}
+}
+
+func TestNewWithNetlinkUsesTheGivenNetlink(t *testing.T) {
+	kernel := fake.NewNetlink()
+	rm := NewWithNetlink(kernel, int(gTestProtocol)).(*ruleManagerImpl)
+	if rm.nl != kernel {
+		t.Error("The given Netlink must be used")
+	}
+}
+
+func TestNothingBlocksInRun(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.start()
+	defer testable.stop()
+
+	mockWatcher := MockRuleWatcher{}
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Error("RegisterRule shall pass here")
+	}
+	testable.rm.RegisterWatcher(mockWatcher)
+
+	if err := testable.rm.DeRegisterRule(gTestRuleName); err != nil {
+		t.Error("DeRegisterRule shall pass here")
+	}
+	testable.rm.DeRegisterWatcher(mockWatcher)
+}
+
+func TestRunReturnsSubscribeError(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.kernel.Fail("RuleSubscribe", errors.New("bla"))
+	testable.start()
+	testable.stop()
+	if testable.runError == nil {
+		t.Error("Run supposed to early exit with an error due to rule subscription failure")
+	}
+}
+
+func TestRunReturnsIfUpdateChanClosed(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.start()
+
+	// The watcher is registered by the event loop, so the subscription is already in place
+	testable.rm.RegisterWatcher(MockRuleWatcher{})
+	testable.kernel.CloseSubscriptions()
+
+	testable.wg.Wait()
+}
+
+func TestRegisterRuleSuccess(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Errorf("RegisterRule shall pass here: %s", err.Error())
+	}
+	if !testable.rm.IsRegistered(gTestRuleName) {
+		t.Error("Rule must be registered")
+	}
+	added := testable.ownKernelRules(t)
+	if len(added) != 1 {
+		t.Fatalf("Rule must be added once with the protocol: %v", added)
+	}
+	if added[0].Priority != 1000 || added[0].Table != 100 || added[0].Mark != 100 || added[0].Src.String() != "10.0.0.0/8" || added[0].Family != netlink.FAMILY_V4 {
+		t.Errorf("Rule must be converted: %v", added[0])
+	}
+	if added[0].Goto != -1 || added[0].Flow != -1 || added[0].SuppressIfgroup != -1 || added[0].SuppressPrefixlen != -1 {
+		t.Errorf("Unused attributes must be left out: %v", added[0])
+	}
+}
+
+func TestRegisterRuleFail(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.kernel.Fail("RuleAdd", errors.New("bla"))
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err == nil {
+		t.Error("RegisterRule must fail")
+	}
+	if testable.rm.IsRegistered(gTestRuleName) {
+		t.Error("Rule must not be registered")
+	}
+}
+
+func TestSameRegisterRuleTwiceFail(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Error("RegisterRule shall pass here")
+	}
+	other := gTestRule
+	other.Priority = 1001
+	if err := testable.rm.RegisterRule(gTestRuleName, other); err == nil {
+		t.Error("RegisterRule must fail with the same name")
+	}
+	if rules := testable.ownKernelRules(t); len(rules) != 1 {
+		t.Errorf("Only the first rule must be added: %v", rules)
+	}
+}
+
+func TestRegisterEqualRuleFail(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Error("RegisterRule shall pass here")
+	}
+	if err := testable.rm.RegisterRule("other", gTestRule); err == nil {
+		t.Error("RegisterRule must fail with the same rule")
+	}
+}
+
+func TestRegisterRuleAdoptsOwnExisting(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.addKernelRule(t, ownNetLinkRule(gTestRule))
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Errorf("Own existing rule must be adopted: %s", err.Error())
+	}
+	if !testable.rm.IsRegistered(gTestRuleName) {
+		t.Error("Adopted rule must be registered")
+	}
+	if rules := testable.ownKernelRules(t); len(rules) != 1 {
+		t.Errorf("Adopted rule must not be duplicated: %v", rules)
+	}
+}
+
+func TestRegisterRuleForeignExistingFail(t *testing.T) {
+	testable := newTestableRuleManager()
+	foreign := ownNetLinkRule(gTestRule)
+	foreign.Protocol = 0
+	testable.addKernelRule(t, foreign)
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err == nil {
+		t.Error("Rule installed by someone else must not be adopted")
+	}
+	if testable.rm.IsRegistered(gTestRuleName) {
+		t.Error("Rule must not be registered")
+	}
+}
+
+func TestDeRegisterRule(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Error("RegisterRule shall pass here")
+	}
+	if err := testable.rm.DeRegisterRule(gTestRuleName); err != nil {
+		t.Errorf("DeRegisterRule shall pass here: %s", err.Error())
+	}
+	if rules := testable.ownKernelRules(t); len(rules) != 0 {
+		t.Errorf("Rule must be deleted from the kernel: %v", rules)
+	}
+}
+
+func TestDeRegisterRuleAlreadyDeleted(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Error("RegisterRule shall pass here")
+	}
+	testable.deleteKernelRule(t, ownNetLinkRule(gTestRule))
+	if err := testable.rm.DeRegisterRule(gTestRuleName); err != nil {
+		t.Errorf("Missing rule must be deregistered: %s", err.Error())
+	}
+	if testable.rm.IsRegistered(gTestRuleName) {
+		t.Error("Rule must not be registered")
+	}
+}
+
+func TestDeRegisterRuleUnknownError(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Error("RegisterRule shall pass here")
+	}
+	testable.kernel.Fail("RuleDel", errors.New("bla"))
+	if err := testable.rm.DeRegisterRule(gTestRuleName); err == nil {
+		t.Error("DeRegisterRule must fail")
+	}
+	if !testable.rm.IsRegistered(gTestRuleName) {
+		t.Error("Rule must stay registered")
+	}
+}
+
+func TestDeRegisterRuleWhichIsNotRegistered(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.DeRegisterRule(gTestRuleName); err != ErrNotFound {
+		t.Error("DeRegisterRule must return ErrNotFound")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	testable := newTestableRuleManager()
+	other := Rule{Family: netlink.FAMILY_V6, Priority: 1001, Table: 101}
+	testable.start()
+	defer testable.stop()
+
+	mockWatcher := MockRuleWatcher{ruleDeletedCalledWith: make(chan Rule), ruleDeletedNames: make(chan string, 1)}
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Error("RegisterRule shall pass here")
+	}
+	if err := testable.rm.RegisterRule("other", other); err != nil {
+		t.Error("RegisterRule shall pass here")
+	}
+	testable.rm.RegisterWatcher(mockWatcher)
+
+	// Someone deletes the rule behind our back
+	testable.deleteKernelRule(t, ownNetLinkRule(gTestRule))
+
+	deleted := <-mockWatcher.ruleDeletedCalledWith
+	if name := <-mockWatcher.ruleDeletedNames; name != gTestRuleName {
+		t.Errorf("Name of the deleted rule must be %s, it is %s", gTestRuleName, name)
+	}
+	if !deleted.Equal(gTestRule) {
+		t.Error("Rule in the notification must be the deleted one")
+	}
+	testable.rm.DeRegisterWatcher(mockWatcher)
+}
+
+func TestWatchDoesNotTriggerIfRulesExist(t *testing.T) {
+	testable := newTestableRuleManager()
+	foreign := netlink.NewRule()
+	foreign.Priority = 2000
+	foreign.Table = 200
+	testable.addKernelRule(t, *foreign)
+	testable.start()
+	mockWatcher := MockRuleWatcher{ruleDeletedCalledWith: make(chan Rule)}
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Error("RegisterRule shall pass here")
+	}
+	testable.rm.RegisterWatcher(mockWatcher)
+
+	testable.deleteKernelRule(t, *foreign)
+
+	testable.stop()
+	select {
+	case <-mockWatcher.ruleDeletedCalledWith:
+		t.Error("Mock must not be triggered while the rule exists")
+	default:
+	}
+}
+
+// nextDeletedName waits for the next notification of the watcher
+func nextDeletedName(t *testing.T, watcher MockRuleWatcher) string {
+	select {
+	case <-watcher.ruleDeletedCalledWith:
+		return <-watcher.ruleDeletedNames
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watcher must be notified")
+		return ""
+	}
+}
+
+func TestWatchNotifiesMissingRuleOnce(t *testing.T) {
+	testable := newTestableRuleManager()
+	other := Rule{Family: netlink.FAMILY_V6, Priority: 1001, Table: 101}
+	foreign := netlink.NewRule()
+	foreign.Priority = 2000
+	foreign.Table = 200
+	testable.addKernelRule(t, *foreign)
+	testable.start()
+	defer testable.stop()
+	mockWatcher := MockRuleWatcher{ruleDeletedCalledWith: make(chan Rule, 10), ruleDeletedNames: make(chan string, 10)}
+	for name, rule := range map[string]Rule{gTestRuleName: gTestRule, "other": other} {
+		if err := testable.rm.RegisterRule(name, rule); err != nil {
+			t.Fatalf("RegisterRule shall pass here: %s", err.Error())
+		}
+	}
+	testable.rm.RegisterWatcher(mockWatcher)
+
+	testable.deleteKernelRule(t, ownNetLinkRule(gTestRule))
+	if name := nextDeletedName(t, mockWatcher); name != gTestRuleName {
+		t.Fatalf("Name of the deleted rule must be %s, it is %s", gTestRuleName, name)
+	}
+
+	// The further deletions must not report the rule again
+	testable.deleteKernelRule(t, *foreign)
+	testable.deleteKernelRule(t, ownNetLinkRule(other))
+	if name := nextDeletedName(t, mockWatcher); name != "other" {
+		t.Errorf("Missing rule must be reported once, %s is reported again", name)
+	}
+
+	// Once re-created, the rule is reported again
+	if err := testable.rm.DeRegisterRule(gTestRuleName); err != nil {
+		t.Fatal(err)
+	}
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Fatal(err)
+	}
+	testable.deleteKernelRule(t, ownNetLinkRule(gTestRule))
+	if name := nextDeletedName(t, mockWatcher); name != gTestRuleName {
+		t.Errorf("Re-created rule must be reported, %s is reported", name)
+	}
+	testable.rm.DeRegisterWatcher(mockWatcher)
+	if len(mockWatcher.ruleDeletedNames) != 0 {
+		t.Errorf("Missing rules must be reported once: %d more notifications", len(mockWatcher.ruleDeletedNames))
+	}
+}
+
+func TestDrainSignals(t *testing.T) {
+	updateChan := make(chan struct{}, 3)
+	for i := 0; i < 3; i++ {
+		updateChan <- struct{}{}
+	}
+
+	if !drainSignals(updateChan) || len(updateChan) != 0 {
+		t.Errorf("Pending signals must be consumed: %d", len(updateChan))
+	}
+	close(updateChan)
+	if drainSignals(updateChan) {
+		t.Error("Closed channel must be reported")
+	}
+}
+
+func TestIsRegisteredConcurrently(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.start()
+	defer testable.stop()
+
+	// The managed rules are owned by the event loop, so this passes the race detector
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			testable.rm.IsRegistered(gTestRuleName)
+		}
+	}()
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Error("RegisterRule shall pass here")
+	}
+	<-done
+	if !testable.rm.IsRegistered(gTestRuleName) {
+		t.Error("Rule must be registered")
+	}
+}
+
+func TestCollectGarbage(t *testing.T) {
+	testable := newTestableRuleManager()
+	orphan := Rule{Priority: 1001, Table: 101}
+	adoptable := Rule{Priority: 1002, Table: 102}
+	foreign := ownNetLinkRule(Rule{Priority: 1003, Table: 103})
+	foreign.Protocol = 0
+	testable.addKernelRule(t, ownNetLinkRule(orphan))
+	testable.addKernelRule(t, ownNetLinkRule(adoptable))
+	testable.addKernelRule(t, foreign)
+	testable.start()
+	defer testable.stop()
+	if err := testable.rm.RegisterRule(gTestRuleName, gTestRule); err != nil {
+		t.Error("RegisterRule shall pass here")
+	}
+
+	err := testable.rm.CollectGarbage(func(r Rule) (string, bool) {
+		if r.Equal(adoptable) {
+			return "adopted", true
+		}
+		return "", false
+	})
+
+	if err != nil {
+		t.Errorf("CollectGarbage shall pass here: %s", err.Error())
+	}
+	own := testable.ownKernelRules(t)
+	if len(own) != 2 || !containsRule(own, adoptable) || !containsRule(own, gTestRule) {
+		t.Errorf("Only the orphaned rule must be removed: %v", own)
+	}
+	if !testable.rm.IsRegistered("adopted") || !testable.rm.IsRegistered(gTestRuleName) {
+		t.Error("Managed and adopted rules must be registered")
+	}
+	if all, _ := testable.kernel.RuleList(netlink.FAMILY_ALL); !containsRule(all, fromNetLinkRule(foreign)) {
+		t.Error("Foreign rule must be kept")
+	}
+}
+
+func TestCollectGarbageListFails(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.kernel.Fail("RuleList", errors.New("bla"))
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.CollectGarbage(func(Rule) (string, bool) { return "", false }); err == nil {
+		t.Error("CollectGarbage shall fail here")
+	}
+}
+
+func TestCollectGarbageDeleteFails(t *testing.T) {
+	testable := newTestableRuleManager()
+	testable.addKernelRule(t, ownNetLinkRule(gTestRule))
+	testable.kernel.Fail("RuleDel", errors.New("bla"))
+	testable.start()
+	defer testable.stop()
+
+	if err := testable.rm.CollectGarbage(func(Rule) (string, bool) { return "", false }); err == nil {
+		t.Error("CollectGarbage shall fail here")
+	}
+}
+
+func TestRuleFamily(t *testing.T) {
+	var testData = []struct {
+		rule   Rule
+		family int
+	}{
+		{Rule{}, netlink.FAMILY_V4},
+		{Rule{Family: netlink.FAMILY_V6}, netlink.FAMILY_V6},
+		{Rule{Dst: &net.IPNet{IP: net.ParseIP("fd00::"), Mask: net.CIDRMask(8, 128)}}, netlink.FAMILY_V6},
+		{Rule{Src: &net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)}, Family: netlink.FAMILY_V6}, netlink.FAMILY_V4},
+	}
+	for _, td := range testData {
+		if family := td.rule.toNetLinkRule().Family; family != td.family {
+			t.Errorf("Family of %v must be %d, it is %d", td.rule, td.family, family)
+		}
+	}
+}
+
+func TestRuleEqualKernelRule(t *testing.T) {
+	if !fromNetLinkRule(ownNetLinkRule(gTestRule)).Equal(gTestRule) {
+		t.Error("Rule reported by the kernel must be equal")
+	}
+	mask := uint32(0xff)
+	masked := gTestRule
+	masked.Mask = &mask
+	if fromNetLinkRule(ownNetLinkRule(gTestRule)).Equal(masked) {
+		t.Error("Rules with different masks must not be equal")
+	}
+}
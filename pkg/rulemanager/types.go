@@ -0,0 +1,66 @@
+//
+// Copyright 2026 IBM Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+
+package rulemanager
+
+import (
+	"net"
+)
+
+// Rule structure represents just-enough data to manage IP policy routing rules from user code
+type Rule struct {
+	// Src and Dst match the addresses of the packets, nil matches all
+	Src *net.IPNet
+	Dst *net.IPNet
+	// Mark matches the firewall mark of the packets, Mask is applied on the mark before the comparison
+	Mark    uint32
+	Mask    *uint32
+	IifName string
+	OifName string
+	// Priority of the rule, rules with lower priority are evaluated first
+	Priority int
+	Table    int
+	// Family is used only if neither Src nor Dst is set, 0 means IPv4
+	Family int
+}
+
+// RuleWatcher is a user-implemented interface, where RuleManager will call back if a managed rule is damaged.
+// The callbacks are executed in the event loop of the RuleManager, so they must not call the RuleManager synchronously.
+type RuleWatcher interface {
+	//RuleDeleted is called with the name and the content of the managed rule, which was deleted by an external entity
+	RuleDeleted(string, Rule)
+}
+
+// RuleManager is the main interface, which is implemented by the package.
+// Every method except Run is served by the event loop: it blocks until Run is started,
+// and it deadlocks if called from a RuleWatcher callback, which is executed by the loop itself.
+type RuleManager interface {
+	//IsRegistered returns true if a Rule (by it's name) is already managed. Blocks until Run is started.
+	IsRegistered(string) bool
+	//RegisterRule creates and start watching the rule. If the rule is deleted after the registration, RuleWatchers will be notified.
+	RegisterRule(string, Rule) error
+	//DeRegisterRule removes the rule from the kernel and also stop watching it.
+	DeRegisterRule(string) error
+	//CollectGarbage removes the rules from the kernel which carry the protocol ID of the RuleManager, but are not managed.
+	//The adopt callback can return a name to register such a rule under, instead of removing it.
+	CollectGarbage(func(Rule) (string, bool)) error
+	//RegisterWatcher registers a new RuleWatcher, which will be notified if the managed rules are deleted.
+	RegisterWatcher(RuleWatcher)
+	//DeRegisterWatcher removes watchers
+	DeRegisterWatcher(RuleWatcher)
+	//Run is the main event loop, shall run in it's own go-routine. Returns when the channel sent in got closed.
+	Run(chan struct{}) error
+}